@@ -0,0 +1,209 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/kitproj/splunk-cli/internal/output"
+	"github.com/kitproj/splunk-cli/internal/ticket"
+	"github.com/kitproj/splunk-cli/pkg/splunk"
+)
+
+func alertsCommand() *command {
+	var count, maxResults *int
+	var format, to, project *string
+	return &command{
+		name:    "alerts",
+		aliases: []string{"alert"},
+		short:   "Show triggered alerts",
+		subcommands: []*command{
+			{
+				name:    "fired",
+				args:    "[name]",
+				short:   "List recently triggered alerts, optionally only those of one alert",
+				maxArgs: 1,
+				flags: func(flags *flag.FlagSet) {
+					count = flags.Int("count", 50, "maximum number of triggers to list (0 for all)")
+					format = outputFlag(flags)
+				},
+				run: func(ctx context.Context, args []string) error {
+					name := ""
+					if len(args) == 1 {
+						name = args[0]
+					}
+					return executeCommand(ctx, func(ctx context.Context) error {
+						return runAlertsFired(ctx, name, *count, *format)
+					})
+				},
+			},
+			{
+				name:    "export-ticket",
+				args:    "<sid>",
+				short:   "File a ticket for a triggered alert, with its results",
+				long:    "File a ticket in Jira or ServiceNow for a triggered alert, identified by the sid 'splunk alerts fired' lists.\nThe ticket describes the alert and includes a table of the results that triggered it.\nThe tracker is configured in the profile's \"jira\" or \"servicenow\" settings, with the secret in JIRA_API_TOKEN or SERVICENOW_PASSWORD.",
+				minArgs: 1,
+				maxArgs: 1,
+				flags: func(flags *flag.FlagSet) {
+					to = flags.String("to", "jira", "where to file the ticket: jira or servicenow")
+					project = flags.String("project", "", "Jira project key, e.g. OPS (default: the profile's)")
+					maxResults = flags.Int("max-results", 20, "maximum number of results to include in the ticket")
+				},
+				flagValues: map[string]func() []string{"to": staticValues("jira", "servicenow")},
+				run: func(ctx context.Context, args []string) error {
+					return executeCommand(ctx, func(ctx context.Context) error {
+						return runAlertsExportTicket(ctx, args[0], *to, *project, *maxResults)
+					})
+				},
+			},
+		},
+	}
+}
+
+// runAlertsFired prints each trigger of the alerts
+func runAlertsFired(ctx context.Context, name string, count int, format string) error {
+	writer, err := newWriter(os.Stdout, format)
+	if err != nil {
+		return err
+	}
+
+	alerts, err := client.ListFiredAlerts(ctx, splunk.FiredAlertsOptions{Alert: name, Count: count})
+	if err != nil {
+		return fmt.Errorf("failed to list fired alerts: %w", err)
+	}
+
+	for _, alert := range alerts {
+		err := writer.Write(map[string]interface{}{
+			"_time":    time.Unix(int64(alert.TriggerTime), 0).Format(time.RFC3339),
+			"name":     alert.Name,
+			"severity": alert.SeverityName(),
+			"type":     alert.AlertType,
+			"actions":  alert.Actions,
+			"sid":      alert.SID,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return writer.Close()
+}
+
+// runAlertsExportTicket files a ticket for the trigger of an alert with the sid
+func runAlertsExportTicket(ctx context.Context, sid, to, project string, maxResults int) error {
+	tracker, err := newTracker(to, project)
+	if err != nil {
+		return err
+	}
+
+	alerts, err := client.ListFiredAlerts(ctx, splunk.FiredAlertsOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to list fired alerts: %w", err)
+	}
+	var alert *splunk.FiredAlert
+	for i := range alerts {
+		if alerts[i].SID == sid {
+			alert = &alerts[i]
+			break
+		}
+	}
+	if alert == nil {
+		return fmt.Errorf("no triggered alert has sid %q (see 'splunk alerts fired')", sid)
+	}
+
+	status, err := client.GetSearchStatus(ctx, sid)
+	if err != nil {
+		return fmt.Errorf("failed to get the alert's search job (it may have expired): %w", err)
+	}
+	results, err := client.GetResults(ctx, sid, splunk.ResultsOptions{Count: maxResults})
+	if err != nil {
+		return fmt.Errorf("failed to get the alert's results: %w", err)
+	}
+
+	t, err := alertTicket(*alert, int(status.Content.ResultCount), results.Results)
+	if err != nil {
+		return err
+	}
+	created, err := tracker.Create(ctx, t)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Created %s: %s\n", created.Key, created.URL)
+	return nil
+}
+
+// newTracker returns the tracker configured in the profile for to
+func newTracker(to, project string) (ticket.Tracker, error) {
+	switch to {
+	case "jira":
+		jira := settings.Jira
+		if jira == nil || jira.URL == "" {
+			return nil, fmt.Errorf("Jira is not configured (add \"jira\": {\"url\": ..., \"username\": ...} to the profile)")
+		}
+		token := os.Getenv("JIRA_API_TOKEN")
+		if token == "" {
+			return nil, fmt.Errorf("JIRA_API_TOKEN is required")
+		}
+		project = defaultString(project, jira.Project)
+		if project == "" {
+			return nil, fmt.Errorf("--project is required")
+		}
+		return &ticket.Jira{URL: jira.URL, Username: jira.Username, Token: token, Project: project, IssueType: jira.IssueType}, nil
+	case "servicenow":
+		sn := settings.ServiceNow
+		if sn == nil || sn.URL == "" {
+			return nil, fmt.Errorf("ServiceNow is not configured (add \"servicenow\": {\"url\": ..., \"username\": ...} to the profile)")
+		}
+		password := os.Getenv("SERVICENOW_PASSWORD")
+		if password == "" {
+			return nil, fmt.Errorf("SERVICENOW_PASSWORD is required")
+		}
+		return &ticket.ServiceNow{URL: sn.URL, Username: sn.Username, Password: password, AssignmentGroup: sn.AssignmentGroup}, nil
+	default:
+		return nil, fmt.Errorf("unknown ticket system %q (must be jira or servicenow)", to)
+	}
+}
+
+// alertTicket describes the trigger of an alert and tabulates its results
+func alertTicket(alert splunk.FiredAlert, resultCount int, results []map[string]interface{}) (ticket.Ticket, error) {
+	var description strings.Builder
+	fmt.Fprintf(&description, "Splunk alert %q triggered at %s.\n\n", alert.Name, time.Unix(int64(alert.TriggerTime), 0).Format(time.RFC3339))
+	fmt.Fprintf(&description, "Severity: %s\n", alert.SeverityName())
+	if alert.AlertType != "" {
+		fmt.Fprintf(&description, "Type: %s\n", alert.AlertType)
+	}
+	if alert.Actions != "" {
+		fmt.Fprintf(&description, "Actions: %s\n", alert.Actions)
+	}
+	fmt.Fprintf(&description, "Search job: %s\n", alert.SID)
+	fmt.Fprintf(&description, "Results: %d", resultCount)
+	if len(results) < resultCount {
+		fmt.Fprintf(&description, " (the first %d are shown)", len(results))
+	}
+
+	var details bytes.Buffer
+	if len(results) > 0 {
+		writer, err := output.NewWriter(&details, "table")
+		if err != nil {
+			return ticket.Ticket{}, err
+		}
+		for _, result := range results {
+			if err := writer.Write(result); err != nil {
+				return ticket.Ticket{}, err
+			}
+		}
+		if err := writer.Close(); err != nil {
+			return ticket.Ticket{}, err
+		}
+	}
+
+	return ticket.Ticket{
+		Summary:     fmt.Sprintf("Splunk alert: %s (%s)", alert.Name, alert.SeverityName()),
+		Description: description.String(),
+		Details:     strings.TrimRight(details.String(), "\n"),
+		Labels:      []string{"splunk"},
+	}, nil
+}
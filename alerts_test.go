@@ -0,0 +1,29 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/kitproj/splunk-cli/pkg/splunk"
+)
+
+func TestAlertTicket(t *testing.T) {
+	alert := splunk.FiredAlert{Name: "5xx errors", SID: "scheduler__admin__search__RMD5", TriggerTime: 1700000000, Severity: 4, AlertType: "number of events"}
+	results := []map[string]interface{}{{"status": "500", "count": "12"}}
+
+	got, err := alertTicket(alert, 3, results)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if got.Summary != "Splunk alert: 5xx errors (error)" {
+		t.Errorf("Unexpected summary: %s", got.Summary)
+	}
+	for _, want := range []string{"Search job: scheduler__admin__search__RMD5", "Results: 3 (the first 1 are shown)", "Type: number of events"} {
+		if !strings.Contains(got.Description, want) {
+			t.Errorf("Expected the description to contain %q, got: %s", want, got.Description)
+		}
+	}
+	if !strings.Contains(got.Details, "500") || !strings.Contains(got.Details, "STATUS") {
+		t.Errorf("Expected a table of the results, got: %s", got.Details)
+	}
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+)
+
+// paramsFlag collects repeated key=value flags
+type paramsFlag struct {
+	values url.Values
+}
+
+func (p *paramsFlag) String() string {
+	if p == nil || p.values == nil {
+		return ""
+	}
+	return p.values.Encode()
+}
+
+func (p *paramsFlag) Set(s string) error {
+	key, value, ok := strings.Cut(s, "=")
+	if !ok || key == "" {
+		return fmt.Errorf("expected key=value, got %q", s)
+	}
+	if p.values == nil {
+		p.values = url.Values{}
+	}
+	p.values.Add(key, value)
+	return nil
+}
+
+func apiCommand() *command {
+	var params paramsFlag
+	var raw *bool
+	return &command{
+		name:  "api",
+		args:  "<method> <path>",
+		short: "Make an authenticated call to any REST endpoint",
+		long: "Call a Splunk REST endpoint with the configured credentials, e.g. 'splunk api GET /services/server/info',\n" +
+			"for endpoints the CLI doesn't have a command for. Paths without a leading / are under /services/.\n" +
+			"Parameters go in the query string of GET and DELETE requests, and the form body of others.\n" +
+			"output_mode=json is added unless -d sets an output mode, and JSON responses are pretty-printed.",
+		minArgs: 2,
+		maxArgs: 2,
+		flags: func(flags *flag.FlagSet) {
+			flags.Var(&params, "d", "parameter to send, as key=value (repeatable)")
+			raw = flags.Bool("raw", false, "print the response as it is, without pretty-printing JSON")
+		},
+		run: func(ctx context.Context, args []string) error {
+			method, path := strings.ToUpper(args[0]), args[1]
+			if !strings.HasPrefix(path, "/") {
+				path = "/services/" + path
+			}
+			return executeCommand(ctx, func(ctx context.Context) error {
+				data, err := client.Request(ctx, method, path, params.values)
+				if err != nil {
+					return err
+				}
+				return writeAPIResponse(data, *raw)
+			})
+		},
+	}
+}
+
+// writeAPIResponse prints a response body, indenting it if it's JSON
+func writeAPIResponse(data []byte, raw bool) error {
+	var out bytes.Buffer
+	if raw || json.Indent(&out, data, "", "  ") != nil {
+		out.Reset()
+		out.Write(data)
+	}
+	if out.Len() > 0 && out.Bytes()[out.Len()-1] != '\n' {
+		out.WriteByte('\n')
+	}
+	_, err := os.Stdout.Write(out.Bytes())
+	return err
+}
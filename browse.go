@@ -0,0 +1,485 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+
+	"github.com/kitproj/splunk-cli/internal/output"
+	"github.com/kitproj/splunk-cli/pkg/splunk"
+	"golang.org/x/term"
+)
+
+func browseCommand() *command {
+	var count *int
+	return &command{
+		name:  "browse",
+		args:  "<query> [earliest-time] [latest-time]",
+		short: "Browse a search's results in a scrollable, filterable table",
+		long: "Run a search and browse its results in the terminal: scroll the table, filter it with /,\n" +
+			"open an event's fields with Enter and choose the table's columns with f.",
+		minArgs: 1,
+		maxArgs: 3,
+		flags: func(flags *flag.FlagSet) {
+			count = flags.Int("count", 1000, "maximum number of results to browse")
+		},
+		run: func(ctx context.Context, args []string) error {
+			search := searchArgs{query: args[0]}
+			if len(args) >= 2 {
+				search.earliestTime = args[1]
+			}
+			if len(args) >= 3 {
+				search.latestTime = args[2]
+			}
+			if !term.IsTerminal(int(os.Stdin.Fd())) || !term.IsTerminal(int(os.Stdout.Fd())) {
+				return fmt.Errorf("browse needs a terminal (use 'splunk search -o table' to print results instead)")
+			}
+			return executeCommand(ctx, func(ctx context.Context) error {
+				return runBrowse(ctx, search, *count)
+			})
+		},
+	}
+}
+
+// runBrowse runs a search, then browses its results until the user quits
+func runBrowse(ctx context.Context, args searchArgs, count int) error {
+	query := ensureSearchCommand(args.query)
+	earliestTime := defaultString(args.earliestTime, settings.Earliest)
+	latestTime := defaultString(args.latestTime, settings.Latest)
+
+	slog.Info("Running search", "query", query)
+	job := searchJob{
+		client:  client,
+		query:   query,
+		opts:    splunk.SearchOptions{EarliestTime: earliestTime, LatestTime: latestTime},
+		results: splunk.ResultsOptions{Count: count},
+	}
+	finished, err := job.run(ctx)
+	if err != nil {
+		return err
+	}
+	results := finished.results
+	if len(results.Results) == 0 {
+		slog.Info("The search found no results")
+		return nil
+	}
+
+	fd := int(os.Stdin.Fd())
+	state, err := term.MakeRaw(fd)
+	if err != nil {
+		return fmt.Errorf("failed to set terminal to raw mode: %w", err)
+	}
+	defer term.Restore(fd, state)
+	// Use the alternate screen, so the shell's scrollback is as it was on exit
+	fmt.Fprint(os.Stdout, "\x1b[?1049h\x1b[?25l")
+	defer fmt.Fprint(os.Stdout, "\x1b[?25h\x1b[?1049l")
+
+	b := newBrowser(results.Results)
+	in := bufio.NewReader(os.Stdin)
+	for {
+		// The size is read before every draw, so the browser follows the window as it's resized
+		if width, height, err := term.GetSize(int(os.Stdout.Fd())); err == nil && width > 0 && height > 0 {
+			b.width, b.height = width, height
+		}
+		fmt.Fprint(os.Stdout, "\x1b[H"+strings.Join(b.render(), "\x1b[K\r\n")+"\x1b[K\x1b[J")
+		key, err := readKey(in)
+		if err != nil {
+			return err
+		}
+		if b.key(key) {
+			return nil
+		}
+	}
+}
+
+// browserView is what the browser shows
+type browserView int
+
+const (
+	tableView browserView = iota
+	// detailView shows every field of the selected result
+	detailView
+	// fieldsView lists the fields, to choose the table's columns
+	fieldsView
+	// filterView is the table while a filter is typed
+	filterView
+)
+
+// browser is the state of the results browser. It's drawn by render and driven by key, so it
+// doesn't depend on the terminal.
+type browser struct {
+	results []map[string]interface{}
+	// fields are every field of the results, in display order
+	fields []string
+	hidden map[string]bool
+	// filter is a case-insensitive substring that results must contain, and rows are the indexes
+	// of the results that do
+	filter string
+	rows   []int
+
+	view browserView
+	// cursor and offset are the selected and first shown rows of the table
+	cursor, offset int
+	// scroll is the first shown line of the detail view
+	scroll int
+	// fieldCursor is the selected field of the fields view
+	fieldCursor int
+
+	width, height int
+}
+
+func newBrowser(results []map[string]interface{}) *browser {
+	seen := map[string]bool{}
+	var fields []string
+	for _, result := range results {
+		for field := range result {
+			if !seen[field] {
+				seen[field] = true
+				fields = append(fields, field)
+			}
+		}
+	}
+	b := &browser{results: results, fields: output.SortFields(fields), hidden: map[string]bool{}, width: 80, height: 24}
+	// Internal fields such as _cd and _bkt are hidden until chosen, except the time and raw event
+	for _, field := range b.fields {
+		if strings.HasPrefix(field, "_") && field != "_time" && field != "_raw" {
+			b.hidden[field] = true
+		}
+	}
+	b.applyFilter()
+	return b
+}
+
+// key handles a key press, returning whether to quit
+func (b *browser) key(key string) bool {
+	if key == "ctrl-c" {
+		return true
+	}
+	switch b.view {
+	case filterView:
+		switch key {
+		case "enter":
+			b.view = tableView
+		case "esc":
+			b.filter = ""
+			b.view = tableView
+		case "backspace":
+			if runes := []rune(b.filter); len(runes) > 0 {
+				b.filter = string(runes[:len(runes)-1])
+			}
+		default:
+			if len([]rune(key)) == 1 {
+				b.filter += key
+			}
+		}
+		b.applyFilter()
+	case detailView:
+		switch key {
+		case "q":
+			return true
+		case "esc", "enter", "backspace":
+			b.view = tableView
+		case "up", "k":
+			b.scroll = max(b.scroll-1, 0)
+		case "down", "j":
+			b.scroll++
+		case "pgup":
+			b.scroll = max(b.scroll-b.pageSize(), 0)
+		case "pgdown", " ":
+			b.scroll += b.pageSize()
+		case "home", "g":
+			b.scroll = 0
+		}
+	case fieldsView:
+		switch key {
+		case "q":
+			return true
+		case "esc", "f":
+			b.view = tableView
+		case "up", "k":
+			b.fieldCursor = max(b.fieldCursor-1, 0)
+		case "down", "j":
+			b.fieldCursor = min(b.fieldCursor+1, len(b.fields)-1)
+		case " ", "enter":
+			field := b.fields[b.fieldCursor]
+			b.hidden[field] = !b.hidden[field]
+		}
+	default:
+		switch key {
+		case "q":
+			return true
+		case "esc":
+			// Esc clears the filter before it quits
+			if b.filter == "" {
+				return true
+			}
+			b.filter = ""
+			b.applyFilter()
+		case "up", "k":
+			b.move(-1)
+		case "down", "j":
+			b.move(1)
+		case "pgup":
+			b.move(-b.tableRows())
+		case "pgdown", " ":
+			b.move(b.tableRows())
+		case "home", "g":
+			b.move(-len(b.rows))
+		case "end", "G":
+			b.move(len(b.rows))
+		case "enter":
+			if len(b.rows) > 0 {
+				b.view, b.scroll = detailView, 0
+			}
+		case "/":
+			b.view = filterView
+		case "f":
+			b.view = fieldsView
+		}
+	}
+	return false
+}
+
+// move moves the table's cursor by delta rows, scrolling to keep it shown
+func (b *browser) move(delta int) {
+	b.cursor = min(max(b.cursor+delta, 0), max(len(b.rows)-1, 0))
+	if b.cursor < b.offset {
+		b.offset = b.cursor
+	}
+	if page := b.tableRows(); b.cursor >= b.offset+page {
+		b.offset = b.cursor - page + 1
+	}
+}
+
+// pageSize is the number of lines that fit between the title and footer
+func (b *browser) pageSize() int {
+	return max(b.height-2, 1)
+}
+
+// tableRows is the number of rows of the table that fit under its column headers
+func (b *browser) tableRows() int {
+	return max(b.pageSize()-1, 1)
+}
+
+// applyFilter finds the results that contain the filter in any field
+func (b *browser) applyFilter() {
+	filter := strings.ToLower(b.filter)
+	b.rows = b.rows[:0]
+	for i, result := range b.results {
+		if filter == "" || resultContains(result, filter) {
+			b.rows = append(b.rows, i)
+		}
+	}
+	b.cursor, b.offset = 0, 0
+}
+
+// resultContains reports whether any field of a result contains the lowercase text
+func resultContains(result map[string]interface{}, text string) bool {
+	for _, value := range result {
+		if strings.Contains(strings.ToLower(output.FormatValue(value, "\n")), text) {
+			return true
+		}
+	}
+	return false
+}
+
+// render returns the lines of the screen
+func (b *browser) render() []string {
+	var title, footer string
+	var body []string
+	switch b.view {
+	case detailView:
+		index := b.rows[b.cursor]
+		title = fmt.Sprintf("Result %d of %d", index+1, len(b.results))
+		body = b.detailLines(b.results[index])
+		b.scroll = min(b.scroll, max(len(body)-b.pageSize(), 0))
+		body = body[b.scroll:]
+		footer = "↑/↓ scroll  Esc back  q quit"
+	case fieldsView:
+		title = "Fields"
+		for i, field := range b.fields {
+			check := "[x]"
+			if b.hidden[field] {
+				check = "[ ]"
+			}
+			line := fmt.Sprintf("  %s %s", check, field)
+			if i == b.fieldCursor {
+				line = reverse(pad(line, b.width))
+			}
+			body = append(body, line)
+		}
+		if page := b.pageSize(); b.fieldCursor >= page {
+			body = body[b.fieldCursor-page+1:]
+		}
+		footer = "↑/↓ move  Space show/hide  Esc back"
+	default:
+		title = fmt.Sprintf("%d results", len(b.results))
+		if b.filter != "" || b.view == filterView {
+			title = fmt.Sprintf("%d of %d results matching %q", len(b.rows), len(b.results), b.filter)
+		}
+		body = b.tableLines()
+		footer = "↑/↓ move  Enter details  / filter  f fields  q quit"
+		if b.view == filterView {
+			footer = "/" + b.filter + "█  (Enter apply, Esc clear)"
+		}
+	}
+
+	lines := []string{reverse(pad(" "+title, b.width))}
+	for i := 0; i < b.pageSize(); i++ {
+		line := ""
+		if i < len(body) {
+			line = body[i]
+		}
+		lines = append(lines, line)
+	}
+	return append(lines, truncate(footer, b.width))
+}
+
+// tableLines returns the column headers and the shown rows of the table
+func (b *browser) tableLines() []string {
+	var columns []string
+	for _, field := range b.fields {
+		if !b.hidden[field] {
+			columns = append(columns, field)
+		}
+	}
+
+	// Columns are as wide as their widest value on the page, up to a limit
+	page := b.rows[b.offset:min(b.offset+b.tableRows(), len(b.rows))]
+	widths := make([]int, len(columns))
+	for i, column := range columns {
+		widths[i] = len([]rune(column))
+		for _, index := range page {
+			widths[i] = max(widths[i], len([]rune(cellValue(b.results[index], column))))
+		}
+		widths[i] = min(widths[i], 50)
+	}
+	line := func(values []string) string {
+		cells := make([]string, len(values))
+		for i, value := range values {
+			cells[i] = pad(truncate(value, widths[i]), widths[i])
+		}
+		return truncate(strings.Join(cells, "  "), b.width)
+	}
+
+	headers := make([]string, len(columns))
+	for i, column := range columns {
+		headers[i] = strings.ToUpper(column)
+	}
+	lines := []string{line(headers)}
+	for i, index := range page {
+		values := make([]string, len(columns))
+		for j, column := range columns {
+			values[j] = cellValue(b.results[index], column)
+		}
+		row := line(values)
+		if b.offset+i == b.cursor {
+			row = reverse(pad(row, b.width))
+		}
+		lines = append(lines, row)
+	}
+	return lines
+}
+
+// detailLines returns every field of a result, one per line, with multi-line values indented
+func (b *browser) detailLines(result map[string]interface{}) []string {
+	var fields []string
+	for field := range result {
+		fields = append(fields, field)
+	}
+	var lines []string
+	for _, field := range output.SortFields(fields) {
+		for i, value := range strings.Split(output.FormatValue(result[field], "\n"), "\n") {
+			if i == 0 {
+				lines = append(lines, truncate(field+": "+value, b.width))
+			} else {
+				lines = append(lines, truncate("    "+value, b.width))
+			}
+		}
+	}
+	return lines
+}
+
+// cellValue returns a field of a result on one line
+func cellValue(result map[string]interface{}, field string) string {
+	return strings.ReplaceAll(output.FormatValue(result[field], ", "), "\n", " ")
+}
+
+// truncate shortens s to width characters, marking that it was shortened
+func truncate(s string, width int) string {
+	runes := []rune(s)
+	if len(runes) <= width {
+		return s
+	}
+	if width <= 1 {
+		return string(runes[:max(width, 0)])
+	}
+	return string(runes[:width-1]) + "…"
+}
+
+// pad pads s with spaces to width characters
+func pad(s string, width int) string {
+	return s + strings.Repeat(" ", max(width-len([]rune(s)), 0))
+}
+
+// reverse shows s in reverse video, to highlight it
+func reverse(s string) string {
+	return "\x1b[7m" + s + "\x1b[0m"
+}
+
+// readKey reads a key press from a terminal in raw mode, naming special keys such as "up"
+func readKey(in *bufio.Reader) (string, error) {
+	r, _, err := in.ReadRune()
+	if err != nil {
+		return "", err
+	}
+	switch r {
+	case 3:
+		return "ctrl-c", nil
+	case '\r', '\n':
+		return "enter", nil
+	case 127, 8:
+		return "backspace", nil
+	case 0x1b:
+	default:
+		return string(r), nil
+	}
+
+	// A lone escape is the Esc key; otherwise it starts a sequence such as ESC [ A
+	if in.Buffered() == 0 {
+		return "esc", nil
+	}
+	if next, _ := in.ReadByte(); next != '[' && next != 'O' {
+		return "esc", nil
+	}
+	var seq []byte
+	for {
+		c, err := in.ReadByte()
+		if err != nil {
+			return "", err
+		}
+		seq = append(seq, c)
+		if c >= 0x40 && c <= 0x7e {
+			break
+		}
+	}
+	switch string(seq) {
+	case "A":
+		return "up", nil
+	case "B":
+		return "down", nil
+	case "H", "1~":
+		return "home", nil
+	case "F", "4~":
+		return "end", nil
+	case "5~":
+		return "pgup", nil
+	case "6~":
+		return "pgdown", nil
+	}
+	return "", nil
+}
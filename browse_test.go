@@ -0,0 +1,64 @@
+package main
+
+import (
+	"bufio"
+	"strings"
+	"testing"
+)
+
+func TestBrowser(t *testing.T) {
+	b := newBrowser([]map[string]interface{}{
+		{"_time": "2024-01-01T00:00:00", "host": "web1", "_raw": "GET /", "_cd": "1:1"},
+		{"_time": "2024-01-01T00:00:01", "host": "web2", "_raw": "POST /login\nbody", "_cd": "1:2"},
+		{"_time": "2024-01-01T00:00:02", "host": "db1", "_raw": "SELECT 1", "_cd": "1:3"},
+	})
+	b.width, b.height = 60, 8
+
+	screen := strings.Join(b.render(), "\n")
+	if len(b.render()) != 8 || !strings.Contains(screen, "_TIME") || !strings.Contains(screen, "HOST") || strings.Contains(screen, "_CD") {
+		t.Errorf("Expected a table without internal fields, got:\n%s", screen)
+	}
+
+	// Filter to the web servers, then open the second one's details
+	for _, key := range []string{"/", "w", "e", "b", "enter", "down", "enter"} {
+		b.key(key)
+	}
+	if len(b.rows) != 2 || b.view != detailView {
+		t.Fatalf("Expected 2 matching rows and the detail view, got %d rows and view %d", len(b.rows), b.view)
+	}
+	screen = strings.Join(b.render(), "\n")
+	if !strings.Contains(screen, "Result 2 of 3") || !strings.Contains(screen, "_raw: POST /login") || !strings.Contains(screen, "    body") {
+		t.Errorf("Expected the second result's fields, got:\n%s", screen)
+	}
+
+	// Show _cd in the table
+	b.key("esc")
+	b.key("f")
+	for b.fields[b.fieldCursor] != "_cd" {
+		b.key("down")
+	}
+	b.key(" ")
+	b.key("esc")
+	if screen = strings.Join(b.render(), "\n"); !strings.Contains(screen, "_CD") {
+		t.Errorf("Expected the _cd column to be shown, got:\n%s", screen)
+	}
+
+	if !b.key("q") {
+		t.Errorf("Expected q to quit")
+	}
+}
+
+func TestReadKey(t *testing.T) {
+	in := bufio.NewReader(strings.NewReader("a\x1b[A\x1b[6~\r\x7f"))
+	var got []string
+	for range 5 {
+		key, err := readKey(in)
+		if err != nil {
+			t.Fatalf("Expected no error, got: %v", err)
+		}
+		got = append(got, key)
+	}
+	if strings.Join(got, ",") != "a,up,pgdown,enter,backspace" {
+		t.Errorf("Unexpected keys: %v", got)
+	}
+}
@@ -0,0 +1,277 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// command is a node in the CLI's command tree. Its metadata drives argument
+// parsing, usage messages, the docs command and the generated man pages.
+type command struct {
+	name string
+	// aliases are alternative names for the command, e.g. "ls" for "list"
+	aliases []string
+	// args is the synopsis of the positional arguments, e.g. "<sid>"
+	args string
+	// short is a one-line description
+	short string
+	// long is an optional detailed description
+	long string
+	// minArgs and maxArgs bound the number of positional arguments; maxArgs < 0 means unbounded
+	minArgs int
+	maxArgs int
+	// flags defines the command's flags
+	flags func(flags *flag.FlagSet)
+	// globalFlags defines flags accepted by the command and all of its subcommands
+	globalFlags func(flags *flag.FlagSet)
+	// rawArgs passes the arguments to run as they are, without parsing any flags
+	rawArgs bool
+	// flagValues returns the values a flag can take, by flag name, for shell completion. Those of
+	// ancestors apply too, so flags common to many commands, such as -o, are only described once.
+	flagValues map[string]func() []string
+	// hidden commands are left out of help, the reference and man pages, e.g. the completion helper
+	hidden bool
+	// run runs the command with its positional arguments
+	run         func(ctx context.Context, args []string) error
+	subcommands []*command
+
+	parent *command
+	// fs and globalFS are built once, so the values flags and globalFlags bind are never reset
+	fs       *flag.FlagSet
+	globalFS *flag.FlagSet
+}
+
+// link sets the parent of every command in the tree rooted at c, and returns c
+func (c *command) link() *command {
+	for _, sub := range c.subcommands {
+		sub.parent = c
+		sub.link()
+	}
+	return c
+}
+
+// path returns the full name of the command, e.g. "splunk mcp-server install"
+func (c *command) path() string {
+	if c.parent == nil {
+		return c.name
+	}
+	return c.parent.path() + " " + c.name
+}
+
+// usage returns the command's usage line
+func (c *command) usage() string {
+	parts := []string{c.path()}
+	if c.run == nil {
+		parts = append(parts, "<command>")
+	}
+	if hasFlags(c.ownFlags()) {
+		parts = append(parts, "[flags]")
+	}
+	if c.args != "" {
+		parts = append(parts, c.args)
+	}
+	return strings.Join(parts, " ")
+}
+
+// usageError returns an error describing the command's usage
+func (c *command) usageError() error {
+	return withExitCode(exitUsage, fmt.Errorf("usage: %s", c.usage()))
+}
+
+// flagSet returns the command's flag set: its own flags, plus the global flags of it and its ancestors
+func (c *command) flagSet() *flag.FlagSet {
+	if c.fs != nil {
+		return c.fs
+	}
+	c.fs = flag.NewFlagSet(c.path(), flag.ContinueOnError)
+	if c.flags != nil {
+		c.flags(c.fs)
+	}
+	for cmd := c; cmd != nil; cmd = cmd.parent {
+		// Share the flag's value, so it's set wherever on the command line it appears
+		copyFlags(c.fs, cmd.globalFlagSet(), nil)
+	}
+	c.fs.Usage = func() {
+		c.printHelp(c.fs.Output())
+	}
+	return c.fs
+}
+
+// globalFlagSet returns a flag set with only the global flags the command defines
+func (c *command) globalFlagSet() *flag.FlagSet {
+	if c.globalFS == nil {
+		c.globalFS = flag.NewFlagSet(c.path(), flag.ContinueOnError)
+		if c.globalFlags != nil {
+			c.globalFlags(c.globalFS)
+		}
+	}
+	return c.globalFS
+}
+
+// ownFlags returns the command's flags, excluding global flags inherited from its ancestors
+func (c *command) ownFlags() *flag.FlagSet {
+	own := flag.NewFlagSet(c.path(), flag.ContinueOnError)
+	copyFlags(own, c.flagSet(), func(f *flag.Flag) bool { return !c.isInherited(f.Name) })
+	return own
+}
+
+// inheritedFlags returns the global flags the command inherits from its ancestors
+func (c *command) inheritedFlags() *flag.FlagSet {
+	inherited := flag.NewFlagSet(c.path(), flag.ContinueOnError)
+	copyFlags(inherited, c.flagSet(), func(f *flag.Flag) bool { return c.isInherited(f.Name) })
+	return inherited
+}
+
+// isInherited reports whether a flag is a global flag of one of the command's ancestors
+func (c *command) isInherited(name string) bool {
+	for cmd := c.parent; cmd != nil; cmd = cmd.parent {
+		if cmd.globalFlagSet().Lookup(name) != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// find returns the subcommand with the given name or alias, or nil
+func (c *command) find(name string) *command {
+	for _, sub := range c.subcommands {
+		if sub.name == name {
+			return sub
+		}
+		for _, alias := range sub.aliases {
+			if alias == name {
+				return sub
+			}
+		}
+	}
+	return nil
+}
+
+// commands returns every runnable command in the tree rooted at c, depth first
+func (c *command) commands() []*command {
+	var all []*command
+	if c.run != nil {
+		all = append(all, c)
+	}
+	for _, sub := range c.subcommands {
+		if !sub.hidden {
+			all = append(all, sub.commands()...)
+		}
+	}
+	return all
+}
+
+// tree returns every command in the tree rooted at c, depth first
+func (c *command) tree() []*command {
+	all := []*command{c}
+	for _, sub := range c.subcommands {
+		if !sub.hidden {
+			all = append(all, sub.tree()...)
+		}
+	}
+	return all
+}
+
+// execute dispatches args to the matching subcommand, or parses them and runs c
+func (c *command) execute(ctx context.Context, args []string) error {
+	if len(c.subcommands) > 0 {
+		// Parse any flags before the subcommand's name, e.g. "splunk -no-cache search"
+		flags := c.flagSet()
+		if err := flags.Parse(args); err != nil {
+			return flagError(err)
+		}
+		args = flags.Args()
+		if len(args) > 0 {
+			if sub := c.find(args[0]); sub != nil {
+				return sub.execute(ctx, args[1:])
+			}
+		}
+	}
+
+	if c.run == nil {
+		if len(args) == 0 {
+			return c.usageError()
+		}
+		return withExitCode(exitUsage, fmt.Errorf("unknown sub-command: %s", args[0]))
+	}
+
+	if c.rawArgs {
+		logCommand(c, c.flagSet())
+		return c.run(ctx, args)
+	}
+
+	positional, err := parseFlags(c.flagSet(), args)
+	if err != nil {
+		return flagError(err)
+	}
+	if len(positional) < c.minArgs || (c.maxArgs >= 0 && len(positional) > c.maxArgs) {
+		return c.usageError()
+	}
+	logCommand(c, c.flagSet())
+	return c.run(ctx, positional)
+}
+
+// flagError marks an error parsing flags as a usage error, except for -help
+func flagError(err error) error {
+	if errors.Is(err, flag.ErrHelp) {
+		return err
+	}
+	return withExitCode(exitUsage, err)
+}
+
+// printHelp prints the command's usage, description, subcommands and flags
+func (c *command) printHelp(w io.Writer) {
+	fmt.Fprintf(w, "Usage: %s\n\n", c.usage())
+	fmt.Fprintln(w, c.description())
+	if len(c.aliases) > 0 {
+		fmt.Fprintf(w, "\nAliases: %s\n", strings.Join(c.aliases, ", "))
+	}
+	if len(c.subcommands) > 0 {
+		fmt.Fprintln(w, "\nCommands:")
+		for _, sub := range c.commands() {
+			if sub != c {
+				fmt.Fprintf(w, "  %s - %s\n", sub.usage(), sub.short)
+			}
+		}
+	}
+	if own := c.ownFlags(); hasFlags(own) {
+		fmt.Fprintln(w, "\nFlags:")
+		own.SetOutput(w)
+		own.PrintDefaults()
+	}
+	if inherited := c.inheritedFlags(); hasFlags(inherited) {
+		fmt.Fprintln(w, "\nGlobal flags:")
+		inherited.SetOutput(w)
+		inherited.PrintDefaults()
+	}
+}
+
+// description returns the long description, falling back to the short one
+func (c *command) description() string {
+	if c.long != "" {
+		return c.long
+	}
+	return c.short
+}
+
+// copyFlags defines the flags of src that match keep (or all of them, if keep is nil) in dst, sharing their values
+func copyFlags(dst, src *flag.FlagSet, keep func(*flag.Flag) bool) {
+	src.VisitAll(func(f *flag.Flag) {
+		if keep != nil && !keep(f) {
+			return
+		}
+		dst.Var(f.Value, f.Name, f.Usage)
+		dst.Lookup(f.Name).DefValue = f.DefValue
+	})
+}
+
+// hasFlags reports whether a flag set defines any flags
+func hasFlags(flags *flag.FlagSet) bool {
+	has := false
+	flags.VisitAll(func(*flag.Flag) { has = true })
+	return has
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"strings"
+	"testing"
+)
+
+func TestCommandExecute(t *testing.T) {
+	var got []string
+	var post *string
+	root := (&command{
+		name: "splunk",
+		subcommands: []*command{{
+			name:    "results",
+			args:    "<sid>",
+			minArgs: 1,
+			maxArgs: 1,
+			flags: func(flags *flag.FlagSet) {
+				post = flags.String("post", "", "")
+			},
+			run: func(ctx context.Context, args []string) error {
+				got = append(args, *post)
+				return nil
+			},
+		}},
+	}).link()
+
+	if err := root.execute(context.Background(), []string{"results", "123", "--post", "| stats count"}); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if strings.Join(got, ",") != "123,| stats count" {
+		t.Errorf("Expected args and flag to be parsed, got: %v", got)
+	}
+
+	err := root.execute(context.Background(), []string{"results"})
+	if err == nil || err.Error() != "usage: splunk results [flags] <sid>" {
+		t.Errorf("Expected usage error, got: %v", err)
+	}
+
+	err = root.execute(context.Background(), []string{"bogus"})
+	if err == nil || err.Error() != "unknown sub-command: bogus" {
+		t.Errorf("Expected unknown sub-command error, got: %v", err)
+	}
+}
+
+func TestCommandGlobalFlagsAndAliases(t *testing.T) {
+	var verbose bool
+	var got []string
+	root := (&command{
+		name: "splunk",
+		globalFlags: func(flags *flag.FlagSet) {
+			flags.BoolVar(&verbose, "verbose", false, "")
+		},
+		subcommands: []*command{{
+			name: "jobs",
+			subcommands: []*command{{
+				name:    "list",
+				aliases: []string{"ls"},
+				run: func(ctx context.Context, args []string) error {
+					got = append(got, "list")
+					return nil
+				},
+			}},
+		}},
+	}).link()
+
+	for _, args := range [][]string{{"-verbose", "jobs", "ls"}, {"jobs", "ls", "-verbose"}} {
+		verbose = false
+		if err := root.execute(context.Background(), args); err != nil {
+			t.Fatalf("%v: expected no error, got: %v", args, err)
+		}
+		if !verbose {
+			t.Errorf("%v: expected the global flag to be set", args)
+		}
+	}
+	if len(got) != 2 {
+		t.Errorf("Expected the alias to run the command twice, got: %v", got)
+	}
+}
+
+func TestRoff(t *testing.T) {
+	if got := roff(`.-1h \d`); got != `\&.\-1h \ed` {
+		t.Errorf("Expected escaped roff, got: %q", got)
+	}
+}
@@ -0,0 +1,203 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/kitproj/splunk-cli/internal/config"
+)
+
+func completionCommand(root *command) *command {
+	return &command{
+		name:  "completion",
+		args:  "<bash|zsh|fish>",
+		short: "Print a shell completion script",
+		long: "Print a script that completes commands, flags, profile names and flag values in the shell.\n" +
+			"For bash, add 'source <(splunk completion bash)' to ~/.bashrc; for zsh, add 'source <(splunk completion zsh)'\n" +
+			"to ~/.zshrc; for fish, run 'splunk completion fish > ~/.config/fish/completions/splunk.fish'.",
+		minArgs: 1,
+		maxArgs: 1,
+		run: func(ctx context.Context, args []string) error {
+			script, err := completionScript(args[0], root.name)
+			if err != nil {
+				return err
+			}
+			_, err = fmt.Fprint(os.Stdout, script)
+			return err
+		},
+	}
+}
+
+// completeCommand is the hidden command the completion scripts call with the words of the
+// command line, the last being the one to complete. It prints a candidate per line.
+func completeCommand(root *command) *command {
+	return &command{
+		name:    "__complete",
+		hidden:  true,
+		rawArgs: true,
+		run: func(ctx context.Context, args []string) error {
+			for _, candidate := range completions(root, args) {
+				fmt.Println(candidate)
+			}
+			return nil
+		},
+	}
+}
+
+// completionScript returns the completion script of a shell for the named binary
+func completionScript(shell, name string) (string, error) {
+	var script string
+	switch shell {
+	case "bash":
+		script = bashCompletion
+	case "zsh":
+		script = zshCompletion
+	case "fish":
+		script = fishCompletion
+	default:
+		return "", fmt.Errorf("unknown shell %q (must be bash, zsh or fish)", shell)
+	}
+	return strings.ReplaceAll(script, "{{name}}", name), nil
+}
+
+// Each script falls back to completing file names when there are no candidates, e.g. for
+// -client-cert or positional arguments
+
+const bashCompletion = `# bash completion for {{name}}
+_{{name}}() {
+    local IFS=$'\n'
+    COMPREPLY=($({{name}} __complete "${COMP_WORDS[@]:1:COMP_CWORD}" 2>/dev/null))
+}
+complete -o default -F _{{name}} {{name}}
+`
+
+const zshCompletion = `#compdef {{name}}
+# zsh completion for {{name}}
+_{{name}}() {
+    local -a candidates
+    candidates=("${(@f)$({{name}} __complete "${(@)words[2,CURRENT]}" 2>/dev/null)}")
+    candidates=(${candidates:#})
+    if (( ${#candidates} )); then
+        compadd -Q -- "${candidates[@]}"
+    else
+        _files
+    fi
+}
+if [ "$funcstack[1]" = "_{{name}}" ]; then
+    _{{name}} "$@"
+else
+    compdef _{{name}} {{name}}
+fi
+`
+
+const fishCompletion = `# fish completion for {{name}}
+function __{{name}}_complete
+    set -l tokens (commandline -opc) (commandline -ct)
+    set -e tokens[1]
+    set -l candidates ({{name}} __complete $tokens 2>/dev/null)
+    if test (count $candidates) -eq 0
+        __fish_complete_path (commandline -ct)
+    else
+        printf '%s\n' $candidates
+    end
+end
+complete -c {{name}} -f -a '(__{{name}}_complete)'
+`
+
+// completions returns the candidates for the last of args, after resolving the words before it
+// to a command. Plugins complete their own arguments, so nothing is offered after one.
+func completions(root *command, args []string) []string {
+	if len(args) == 0 {
+		args = []string{""}
+	}
+	words, cur := args[:len(args)-1], args[len(args)-1]
+
+	cmd, positional := root, false
+	for i := 0; i < len(words); i++ {
+		word := words[i]
+		switch {
+		case cmd.rawArgs:
+			return nil
+		case word == "--":
+			positional = true
+		case !positional && len(word) > 1 && word[0] == '-':
+			if takesValue(cmd.flagSet(), word) {
+				i++
+			}
+		case !positional:
+			if sub := cmd.find(word); sub != nil {
+				cmd = sub
+			}
+		}
+	}
+	// Nothing after "--" is a flag or a command
+	if cmd.rawArgs || positional {
+		return nil
+	}
+
+	var candidates []string
+	switch {
+	case len(words) > 0 && takesValue(cmd.flagSet(), words[len(words)-1]):
+		candidates = flagValues(cmd, strings.TrimLeft(words[len(words)-1], "-"))
+	case strings.HasPrefix(cur, "-") && strings.Contains(cur, "="):
+		name, _, _ := strings.Cut(cur, "=")
+		for _, value := range flagValues(cmd, strings.TrimLeft(name, "-")) {
+			candidates = append(candidates, name+"="+value)
+		}
+	case strings.HasPrefix(cur, "-"):
+		prefix := "-"
+		if strings.HasPrefix(cur, "--") {
+			prefix = "--"
+		}
+		cmd.flagSet().VisitAll(func(f *flag.Flag) {
+			candidates = append(candidates, prefix+f.Name)
+		})
+	default:
+		for _, sub := range cmd.subcommands {
+			if !sub.hidden {
+				candidates = append(candidates, sub.name)
+			}
+		}
+	}
+
+	var matches []string
+	for _, candidate := range candidates {
+		if strings.HasPrefix(candidate, cur) {
+			matches = append(matches, candidate)
+		}
+	}
+	return matches
+}
+
+// flagValues returns the values of a command's flag, from its own or its ancestors' flagValues
+func flagValues(cmd *command, name string) []string {
+	for c := cmd; c != nil; c = c.parent {
+		if values, ok := c.flagValues[name]; ok {
+			return values()
+		}
+	}
+	return nil
+}
+
+// staticValues returns a flagValues function for a fixed list of values
+func staticValues(values ...string) func() []string {
+	return func() []string { return values }
+}
+
+// profileNames returns the names of the config file's profiles
+func profileNames() []string {
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		return nil
+	}
+	names := make([]string, 0, len(cfg.Profiles))
+	for name := range cfg.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"strings"
+	"testing"
+)
+
+func TestCompletions(t *testing.T) {
+	noop := func(ctx context.Context, args []string) error { return nil }
+	root := (&command{
+		name: "splunk",
+		globalFlags: func(flags *flag.FlagSet) {
+			flags.String("profile", "", "")
+			flags.Bool("no-cache", false, "")
+		},
+		flagValues: map[string]func() []string{
+			"profile": staticValues("dev", "prod"),
+			"o":       staticValues("json", "table"),
+		},
+		subcommands: []*command{
+			{name: "search", run: noop, flags: func(flags *flag.FlagSet) { flags.String("o", "", "") }},
+			{name: "send", run: noop},
+			{name: "__complete", hidden: true, run: noop},
+			{name: "plugin", rawArgs: true, run: noop},
+		},
+	}).link()
+
+	for _, tc := range []struct {
+		args []string
+		want string
+	}{
+		{[]string{""}, "search,send,plugin"},
+		{[]string{"se"}, "search,send"},
+		{[]string{"-profile", "prod", "se"}, "search,send"},
+		{[]string{"-profile", "p"}, "prod"},
+		{[]string{"--no-cache", "search", "-o", ""}, "json,table"},
+		{[]string{"search", "-o=t"}, "-o=table"},
+		{[]string{"search", "--n"}, "--no-cache"},
+		{[]string{"search", "--", "-"}, ""},
+		{[]string{"plugin", ""}, ""},
+	} {
+		if got := strings.Join(completions(root, tc.args), ","); got != tc.want {
+			t.Errorf("%q: expected %s, got: %s", tc.args, tc.want, got)
+		}
+	}
+}
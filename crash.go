@@ -0,0 +1,296 @@
+package main
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"runtime"
+	"runtime/debug"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/kitproj/splunk-cli/internal/config"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+const (
+	// maxCommandLog is the number of commands kept in the command log
+	maxCommandLog = 20
+	// maxBugReportCrashes is the number of the latest crash reports a bug report includes
+	maxBugReportCrashes = 5
+)
+
+// publicSettings are the settings shown as they are in crash reports and bug reports. The values
+// of all others, such as hosts, usernames and hooks, are redacted.
+var publicSettings = map[string]bool{
+	"scheme": true, "auth": true, "earliest": true, "latest": true, "backoff": true, "max_backoff": true,
+}
+
+// logCommands is whether commands are logged, which main turns on, so tests running commands don't
+var logCommands bool
+
+// logCommand appends a command to the command log, so crash reports show what ran before. Only the
+// names of the command and the flags it was given are logged, never arguments or flag values.
+func logCommand(c *command, flags *flag.FlagSet) {
+	if !logCommands || c.hidden {
+		return
+	}
+	path, err := commandLogPath()
+	if err != nil {
+		return
+	}
+	line := c.path()
+	flags.Visit(func(f *flag.Flag) {
+		line += " -" + f.Name
+	})
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return
+	}
+	// A line appended in a single write isn't interleaved with those of commands run at the same time
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0600)
+	if err != nil {
+		return
+	}
+	_, err = f.WriteString(time.Now().UTC().Format(time.RFC3339) + " " + line + "\n")
+	if closeErr := f.Close(); err != nil || closeErr != nil {
+		return
+	}
+
+	// The log is trimmed once it's twice as long as needed, replacing the file so that it's never
+	// seen part written. A command logged while it's trimmed may be lost, but never corrupts it.
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) <= 2*maxCommandLog {
+		return
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".commands-*")
+	if err != nil {
+		return
+	}
+	defer os.Remove(tmp.Name())
+	_, err = tmp.WriteString(strings.Join(lines[len(lines)-maxCommandLog:], "\n") + "\n")
+	if closeErr := tmp.Close(); err != nil || closeErr != nil {
+		return
+	}
+	_ = os.Rename(tmp.Name(), path)
+}
+
+// readCommandLog returns the last commands in the command log
+func readCommandLog(path string) []string {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	return lines[max(len(lines)-maxCommandLog, 0):]
+}
+
+// recoverCrash, deferred in main and first in the goroutines commands start, saves a crash report
+// if the CLI panics and exits. A panic isn't recovered by the goroutines that started its own.
+func recoverCrash() {
+	r := recover()
+	if r == nil {
+		return
+	}
+	fmt.Fprintf(os.Stderr, "Error: splunk crashed: %s\n", redact(fmt.Sprint(r)))
+	if path, err := writeCrashReport(r, debug.Stack()); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to save a crash report: %v\n", err)
+	} else {
+		fmt.Fprintf(os.Stderr, "A crash report was saved to %s\nRun 'splunk bugreport' to bundle it for an issue at https://github.com/kitproj/splunk-cli/issues\n", path)
+	}
+	os.Exit(exitError)
+}
+
+// recoverToolCrash saves a crash report if an MCP tool panics, and fails the call rather than the server
+func recoverToolCrash(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (result *mcp.CallToolResult, err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				result, err = nil, toolCrashed(request.Params.Name, r)
+			}
+		}()
+		return next(ctx, request)
+	}
+}
+
+// toolCrashed saves a crash report for a panic in the MCP tool, called while it's recovered, and
+// returns the error to fail the call with
+func toolCrashed(tool string, r interface{}) error {
+	path, err := writeCrashReport(r, debug.Stack())
+	if err != nil {
+		path = "nowhere: " + err.Error()
+	}
+	fmt.Fprintf(os.Stderr, "Tool %s crashed: %s (crash report saved to %s)\n", tool, redact(fmt.Sprint(r)), path)
+	return fmt.Errorf("tool %s crashed: %s", tool, redact(fmt.Sprint(r)))
+}
+
+// writeCrashReport writes the panic, its stack, the redacted settings and the last commands to the
+// crashes directory of the cache, and returns its path
+func writeCrashReport(r interface{}, stack []byte) (string, error) {
+	dir, err := config.CacheDir()
+	if err != nil {
+		return "", err
+	}
+	dir = filepath.Join(dir, "crashes")
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return "", fmt.Errorf("failed to create crash directory: %w", err)
+	}
+
+	var report strings.Builder
+	fmt.Fprintf(&report, "splunk-cli crash report\n\n")
+	writeEnvironment(&report)
+	fmt.Fprintf(&report, "\nPanic: %s\n\n%s\n", redact(fmt.Sprint(r)), stack)
+	if log, err := commandLogPath(); err == nil {
+		fmt.Fprintf(&report, "\nLast commands:\n%s\n", strings.Join(readCommandLog(log), "\n"))
+	}
+
+	path := filepath.Join(dir, "crash-"+time.Now().UTC().Format("20060102T150405.000000000")+".txt")
+	if err := os.WriteFile(path, []byte(report.String()), 0600); err != nil {
+		return "", fmt.Errorf("failed to write crash report: %w", err)
+	}
+	return path, nil
+}
+
+func commandLogPath() (string, error) {
+	dir, err := config.CacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "commands.log"), nil
+}
+
+// writeEnvironment writes the version, platform and redacted settings of the CLI
+func writeEnvironment(w io.Writer) {
+	fmt.Fprintf(w, "Time: %s\nVersion: %s\nGo: %s %s/%s\n", time.Now().UTC().Format(time.RFC3339), version, runtime.Version(), runtime.GOOS, runtime.GOARCH)
+	fmt.Fprintf(w, "Profile: %s\n", defaultString(profileName(), "(default)"))
+	var env []string
+	for _, kv := range os.Environ() {
+		if name, _, _ := strings.Cut(kv, "="); strings.HasPrefix(name, "SPLUNK_") {
+			env = append(env, name)
+		}
+	}
+	sort.Strings(env)
+	fmt.Fprintf(w, "Environment variables set: %s\n", defaultString(strings.Join(env, ", "), "(none)"))
+	fmt.Fprintf(w, "Settings: %s\n", redactedSettings())
+}
+
+// redactedSettings returns the selected profile's settings as JSON, with the values of all but
+// publicSettings redacted
+func redactedSettings() string {
+	data, err := json.Marshal(settings)
+	if err != nil {
+		return err.Error()
+	}
+	var v interface{}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err.Error()
+	}
+	data, _ = json.MarshalIndent(redactSettings("", v), "", "  ")
+	return string(data)
+}
+
+func redactSettings(key string, v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for k, value := range v {
+			v[k] = redactSettings(k, value)
+		}
+		return v
+	case string:
+		if v == "" || publicSettings[key] {
+			return v
+		}
+		return "REDACTED"
+	}
+	return v
+}
+
+func bugReportCommand() *command {
+	return &command{
+		name:  "bugreport",
+		args:  "[file]",
+		short: "Bundle crash reports and recent commands for an issue",
+		long: "Write a zip file to attach to an issue, with the CLI's version and platform, the selected profile's settings with\n" +
+			"hosts, usernames and hooks redacted, the names (but not arguments or flag values) of the last commands run, and\n" +
+			"the latest crash reports. Tokens, passwords and queries are never included. The default file is\n" +
+			"splunk-bugreport-<time>.zip in the current directory.",
+		maxArgs: 1,
+		run: func(ctx context.Context, args []string) error {
+			// The settings are only reported, so a broken config file is worth reporting too
+			if err := loadSettings(); err != nil {
+				slog.Warn(err.Error())
+			}
+			path := "splunk-bugreport-" + time.Now().UTC().Format("20060102T150405") + ".zip"
+			if len(args) > 0 {
+				path = args[0]
+			}
+			if err := writeBugReport(path); err != nil {
+				return err
+			}
+			slog.Info("Saved bug report", "path", path)
+			return nil
+		},
+	}
+}
+
+// writeBugReport writes the environment, command log and latest crash reports to a zip file
+func writeBugReport(path string) error {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to create bug report: %w", err)
+	}
+	defer f.Close()
+	z := zip.NewWriter(f)
+
+	var info strings.Builder
+	writeEnvironment(&info)
+	names := []string{"environment.txt"}
+	files := map[string][]byte{"environment.txt": []byte(info.String())}
+	add := func(name string, data []byte) {
+		names = append(names, name)
+		files[name] = data
+	}
+	if log, err := commandLogPath(); err == nil {
+		if lines := readCommandLog(log); len(lines) > 0 {
+			add("commands.log", []byte(strings.Join(lines, "\n")+"\n"))
+		}
+	}
+	if dir, err := config.CacheDir(); err == nil {
+		crashes, _ := filepath.Glob(filepath.Join(dir, "crashes", "crash-*.txt"))
+		// The names sort by time
+		sort.Strings(crashes)
+		if len(crashes) > maxBugReportCrashes {
+			crashes = crashes[len(crashes)-maxBugReportCrashes:]
+		}
+		for _, crash := range crashes {
+			if data, err := os.ReadFile(crash); err == nil {
+				add("crashes/"+filepath.Base(crash), data)
+			}
+		}
+	}
+
+	for _, name := range names {
+		w, err := z.Create(name)
+		if err != nil {
+			return fmt.Errorf("failed to write bug report: %w", err)
+		}
+		if _, err := w.Write(files[name]); err != nil {
+			return fmt.Errorf("failed to write bug report: %w", err)
+		}
+	}
+	if err := z.Close(); err != nil {
+		return fmt.Errorf("failed to write bug report: %w", err)
+	}
+	return f.Close()
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"archive/zip"
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/kitproj/splunk-cli/internal/config"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestCrashReports(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	settings = &config.Profile{Host: "splunk.example.com", Auth: "basic", Username: "alice", PostSearch: "notify --token s3cret"}
+	t.Cleanup(func() { settings = &config.Profile{} })
+
+	handler := recoverToolCrash(func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		panic("boom")
+	})
+	if _, err := handler(context.Background(), mcp.CallToolRequest{Params: mcp.CallToolParams{Name: "search"}}); err == nil || !strings.Contains(err.Error(), "tool search crashed: boom") {
+		t.Errorf("Expected the panic as an error, got: %v", err)
+	}
+
+	path := filepath.Join(t.TempDir(), "bugreport.zip")
+	if err := writeBugReport(path); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	z, err := zip.OpenReader(path)
+	if err != nil {
+		t.Fatalf("Expected a zip file, got: %v", err)
+	}
+	defer z.Close()
+
+	var names []string
+	var all strings.Builder
+	for _, f := range z.File {
+		names = append(names, f.Name)
+		r, _ := f.Open()
+		data, _ := io.ReadAll(r)
+		r.Close()
+		all.Write(data)
+	}
+	if len(names) != 2 || names[0] != "environment.txt" || !strings.HasPrefix(names[1], "crashes/crash-") {
+		t.Errorf("Expected the environment and the crash report, got: %v", names)
+	}
+	for _, want := range []string{"Panic: boom", `"auth": "basic"`, `"host": "REDACTED"`} {
+		if !strings.Contains(all.String(), want) {
+			t.Errorf("Expected the bug report to contain %q", want)
+		}
+	}
+	for _, secret := range []string{"splunk.example.com", "alice", "s3cret"} {
+		if strings.Contains(all.String(), secret) {
+			t.Errorf("Expected %q to be redacted from the bug report", secret)
+		}
+	}
+}
+
+func TestCommandLog(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	logCommands = true
+	defer func() { logCommands = false }()
+
+	// Commands logged at once each add a whole line
+	var wg sync.WaitGroup
+	for i := range 2 * maxCommandLog {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			logCommand(&command{name: fmt.Sprintf("command%d", i)}, flag.NewFlagSet("", flag.ContinueOnError))
+		}()
+	}
+	wg.Wait()
+
+	path, err := commandLogPath()
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := readCommandLog(path)
+	if len(lines) != maxCommandLog {
+		t.Fatalf("Expected the last %d commands, got: %d", maxCommandLog, len(lines))
+	}
+	for _, line := range lines {
+		if _, name, ok := strings.Cut(line, " "); !ok || !strings.HasPrefix(name, "command") || strings.Contains(name, " ") {
+			t.Errorf("Expected a time and a command, got: %q", line)
+		}
+	}
+
+	// The log is trimmed once it's twice as long as needed
+	logCommand(&command{name: "last"}, flag.NewFlagSet("", flag.ContinueOnError))
+	data, _ := os.ReadFile(path)
+	if n := strings.Count(string(data), "\n"); n != maxCommandLog {
+		t.Errorf("Expected the log to be trimmed to %d commands, got: %d", maxCommandLog, n)
+	}
+	if lines := readCommandLog(path); !strings.HasSuffix(lines[len(lines)-1], " last") {
+		t.Errorf("Expected the last command to be kept, got: %q", lines[len(lines)-1])
+	}
+}
@@ -0,0 +1,334 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sync"
+	"time"
+
+	"github.com/kitproj/splunk-cli/internal/config"
+	"github.com/kitproj/splunk-cli/internal/output"
+	"github.com/kitproj/splunk-cli/pkg/splunk"
+)
+
+// settings is the selected profile of the config file, or an empty profile if there isn't one
+var settings = &config.Profile{}
+
+// profileName returns the name of the selected profile, or "" for the default profile
+func profileName() string {
+	if profile != "" {
+		return profile
+	}
+	return os.Getenv("SPLUNK_PROFILE")
+}
+
+// searchNamespace returns the namespace searches and saved searches are in, from the -app and -owner flags or the profile
+func searchNamespace() splunk.Namespace {
+	return splunk.Namespace{Owner: defaultString(ownerName, settings.Owner), App: defaultString(appName, settings.App)}
+}
+
+// tlsOptions returns the TLS settings of the global flags, or else of the selected profile
+func tlsOptions() splunk.TLSOptions {
+	opts := splunk.TLSOptions{
+		CertFile: settings.ClientCert,
+		KeyFile:  settings.ClientKey,
+		Password: os.Getenv("SPLUNK_CLIENT_CERT_PASSWORD"),
+		CAFile:   defaultString(caCert, settings.CACert),
+		Insecure: settings.Insecure,
+	}
+	if clientCert != "" {
+		opts.CertFile, opts.KeyFile = clientCert, clientKey
+	}
+	if insecure != nil {
+		opts.Insecure = *insecure
+	}
+	return opts
+}
+
+// configureTLS configures a client of the server at url with the TLS settings, if there are any,
+// warning that the server isn't who it says it might be if its certificate isn't verified
+func configureTLS(c interface{ ConfigureTLS(splunk.TLSOptions) error }, url string) error {
+	opts := tlsOptions()
+	if opts.CertFile == "" && opts.CAFile == "" && !opts.Insecure {
+		return nil
+	}
+	if opts.Insecure {
+		slog.Warn("Not verifying the server's TLS certificate, so anyone in the way can read and change requests", "url", url)
+	}
+	return c.ConfigureTLS(opts)
+}
+
+// newClient creates a Splunk client that identifies the CLI on every request and caches metadata responses.
+// With basic auth, token is the password.
+func newClient(host, token string) (*splunk.Client, error) {
+	c := splunk.NewClient(host, token)
+	c.BaseURL = settings.BaseURL(host)
+	c.Namespace = searchNamespace()
+	if err := configureTLS(c, c.BaseURL); err != nil {
+		return nil, err
+	}
+	if proxy := defaultString(proxyURL, settings.Proxy); proxy != "" {
+		if err := c.ConfigureProxy(proxy); err != nil {
+			return nil, err
+		}
+	}
+	if settings.Auth == "basic" {
+		// Reuse the last session key until it expires, rather than logging in on every run
+		profile := profileName()
+		auth := &splunk.SessionAuth{Username: settings.Username, Password: token, SessionKey: config.LoadSessionKey(profile, host)}
+		auth.OnLogin = func(key string) {
+			_ = config.SaveSessionKey(profile, host, key)
+		}
+		c.Token = ""
+		c.Use(auth.Middleware)
+	}
+	c.Use(
+		splunk.WithHeader("User-Agent", fmt.Sprintf("splunk-cli/%s (%s; %s)", version, runtime.GOOS, runtime.GOARCH)),
+		splunk.WithRequestID(requestID),
+	)
+	if !noCache {
+		if dir, err := config.CacheDir(); err == nil {
+			cache := splunk.NewResponseCache(filepath.Join(dir, "responses"), time.Minute)
+			if settings.Auth == "basic" {
+				// Key entries by user rather than by session key, which changes with every login
+				cache.Identity = profileName() + "\n" + settings.Username
+			}
+			c.Use(cache.Middleware)
+		}
+	}
+	// Innermost, so a cache revalidation or a login that fails transiently is retried too
+	retry, err := retryPolicy()
+	if err != nil {
+		return nil, err
+	}
+	c.Use(retry.Middleware)
+	if t := tracer(); t != nil {
+		c.Use(t.Middleware)
+	}
+	return c, nil
+}
+
+// tracer returns the tracer that logs API calls at debug level, with -debug or -log-level debug, or
+// nil if debug messages aren't logged
+func tracer() *splunk.Tracer {
+	if logLevel.Level() > slog.LevelDebug {
+		return nil
+	}
+	return &splunk.Tracer{Logger: slog.Default(), Bodies: debugBody, Redact: redact}
+}
+
+// retryPolicy returns the default retry policy, with any of the profile's retry settings
+func retryPolicy() (*splunk.RetryPolicy, error) {
+	p := splunk.DefaultRetryPolicy()
+	p.OnRetry = func(req *http.Request, attempt int, delay time.Duration, reason string) {
+		slog.Warn("API call failed, retrying", "method", req.Method, "path", req.URL.Path, "reason", reason, "attempt", attempt, "delay", delay.Round(100*time.Millisecond).String())
+	}
+	r := settings.Retry
+	if r == nil {
+		return p, nil
+	}
+	if r.MaxRetries != 0 {
+		p.MaxRetries = max(r.MaxRetries, 0)
+	}
+	if r.Jitter < 0 || r.Jitter > 1 {
+		return nil, fmt.Errorf("retry jitter must be between 0 and 1, got %g", r.Jitter)
+	} else if r.Jitter != 0 {
+		p.Jitter = r.Jitter
+	}
+	for _, d := range []struct {
+		name  string
+		value string
+		dst   *time.Duration
+	}{{"backoff", r.Backoff, &p.Backoff}, {"max_backoff", r.MaxBackoff, &p.MaxBackoff}} {
+		if d.value == "" {
+			continue
+		}
+		v, err := time.ParseDuration(d.value)
+		if err != nil || v <= 0 {
+			return nil, fmt.Errorf("retry %s must be a positive duration, e.g. 1s, got %q", d.name, d.value)
+		}
+		*d.dst = v
+	}
+	return p, nil
+}
+
+// loadConfig loads the config file, or an empty config if there isn't one yet. A config file that
+// can't be read or parsed is an error, so commands that change it don't replace it.
+func loadConfig() (*config.Config, error) {
+	cfg, err := config.LoadConfig()
+	if errors.Is(err, fs.ErrNotExist) {
+		return &config.Config{}, nil
+	}
+	return cfg, err
+}
+
+// loadSettings selects the profile of the config file, checking its auth and output settings
+func loadSettings() error {
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	p, err := cfg.Select(profileName())
+	if err != nil {
+		return err
+	}
+	settings = p
+	switch settings.Auth {
+	case "", "token":
+	case "basic":
+		if settings.Username == "" {
+			return fmt.Errorf("username is required for basic auth")
+		}
+	default:
+		return fmt.Errorf("unknown auth %q (must be token or basic)", settings.Auth)
+	}
+	if o := settings.Output; o != nil {
+		if _, err := output.NewStyledWriter(io.Discard, o.Format, output.Style{Theme: o.Theme, Table: o.TableStyle}); err != nil {
+			return fmt.Errorf("invalid output settings: %w", err)
+		}
+	}
+	return nil
+}
+
+// newHECClient creates an HTTP Event Collector client for the configured host. The HEC token
+// is read from SPLUNK_HEC_TOKEN, or else the keyring, where "splunk configure --hec" saves it.
+func newHECClient() (*splunk.HECClient, error) {
+	if err := loadSettings(); err != nil {
+		return nil, err
+	}
+	host := defaultString(settings.Host, os.Getenv("SPLUNK_HOST"))
+	if host == "" && settings.HECURL == "" {
+		return nil, fmt.Errorf("host is required")
+	}
+	token := os.Getenv("SPLUNK_HEC_TOKEN")
+	if token == "" {
+		var err error
+		if token, err = config.LoadHECToken(profileName(), host); err != nil {
+			return nil, fmt.Errorf("HEC token is required (use 'splunk configure --hec <host>' or set SPLUNK_HEC_TOKEN): %w", err)
+		}
+	}
+
+	c := splunk.NewHECClient(host, token)
+	c.BaseURL = settings.CollectorURL(host)
+	if err := configureTLS(c, c.BaseURL); err != nil {
+		return nil, err
+	}
+	if proxy := defaultString(proxyURL, settings.Proxy); proxy != "" {
+		if err := c.ConfigureProxy(proxy); err != nil {
+			return nil, err
+		}
+	}
+	c.Use(
+		splunk.WithHeader("User-Agent", fmt.Sprintf("splunk-cli/%s (%s; %s)", version, runtime.GOOS, runtime.GOARCH)),
+		splunk.WithRequestID(requestID),
+	)
+	if t := tracer(); t != nil {
+		c.Use(t.Middleware)
+	}
+	return c, nil
+}
+
+// newClientSource creates a clientSource from the configured host and token.
+// SPLUNK_HOST_FILE and SPLUNK_TOKEN_FILE take precedence over all other
+// sources and are re-read whenever the files change.
+func newClientSource() (*clientSource, error) {
+	s := &clientSource{}
+	if err := loadSettings(); err != nil {
+		return nil, err
+	}
+
+	if path := os.Getenv("SPLUNK_HOST_FILE"); path != "" {
+		s.hostFile = config.NewFileValue(path)
+	} else if settings.Host != "" {
+		s.host = settings.Host
+	} else {
+		s.host = os.Getenv("SPLUNK_HOST")
+	}
+
+	if path := os.Getenv("SPLUNK_TOKEN_FILE"); path != "" {
+		s.tokenFile = config.NewFileValue(path)
+	} else if settings.Auth == "basic" {
+		// Load password from env var, or fall back to keyring
+		s.token = os.Getenv("SPLUNK_PASSWORD")
+	} else {
+		// Load token from env var, or fall back to keyring
+		s.token = os.Getenv("SPLUNK_TOKEN")
+	}
+
+	if _, err := s.Get(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// clientSource provides a Splunk client, rebuilding it when a mounted host or token file changes
+type clientSource struct {
+	hostFile  *config.FileValue
+	tokenFile *config.FileValue
+	host      string
+	token     string
+
+	mu          sync.Mutex
+	client      *splunk.Client
+	clientHost  string
+	clientToken string
+}
+
+// Get returns a client for the current host and token
+func (s *clientSource) Get() (*splunk.Client, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	host := s.host
+	if s.hostFile != nil {
+		var err error
+		host, err = s.hostFile.Get()
+		if err != nil {
+			return nil, err
+		}
+	}
+	if host == "" {
+		return nil, fmt.Errorf("host is required")
+	}
+
+	token := s.token
+	if s.tokenFile != nil {
+		var err error
+		token, err = s.tokenFile.Get()
+		if err != nil {
+			return nil, err
+		}
+	} else if token == "" && host == s.clientHost {
+		// The keyring token only changes with the host
+		token = s.clientToken
+	}
+	if token == "" {
+		var err error
+		token, err = config.LoadToken(profileName(), host)
+		if err != nil {
+			return nil, err
+		}
+		warnTokenExpiry(profileName(), host)
+	}
+	if token == "" {
+		return nil, fmt.Errorf("token is required")
+	}
+
+	if s.client == nil || host != s.clientHost || token != s.clientToken {
+		c, err := newClient(host, token)
+		if err != nil {
+			return nil, err
+		}
+		s.client = c
+		s.clientHost = host
+		s.clientToken = token
+	}
+	return s.client, nil
+}
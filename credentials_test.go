@@ -0,0 +1,75 @@
+package main
+
+import (
+	"bytes"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/kitproj/splunk-cli/internal/config"
+	"github.com/kitproj/splunk-cli/pkg/splunk"
+)
+
+func TestInsecure(t *testing.T) {
+	saved := settings
+	defer func() { settings, insecure = saved, nil }()
+	defer slog.SetDefault(slog.Default())
+	var logs bytes.Buffer
+	if err := setLogFormat(&logs, "text"); err != nil {
+		t.Fatal(err)
+	}
+
+	// -insecure=false overrides the profile's insecure
+	settings = &config.Profile{Insecure: true}
+	if !tlsOptions().Insecure {
+		t.Error("Expected the profile's insecure")
+	}
+	verify := false
+	insecure = &verify
+	if tlsOptions().Insecure {
+		t.Error("Expected -insecure=false to verify the certificate")
+	}
+
+	insecure = nil
+	c := splunk.NewClient("splunk.example.com", "test-token")
+	if err := configureTLS(c, c.BaseURL); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(logs.String(), "Warning: Not verifying the server's TLS certificate") {
+		t.Errorf("Expected a warning, got: %q", logs.String())
+	}
+}
+
+func TestLoadConfig(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("HOME", dir)
+	t.Setenv("XDG_CONFIG_HOME", dir)
+	t.Setenv("AppData", dir)
+
+	// There's no config file yet
+	cfg, err := loadConfig()
+	if err != nil || cfg == nil {
+		t.Fatalf("Expected an empty config, got: %v, %v", cfg, err)
+	}
+
+	// A broken config file is an error, rather than replaced by an empty config
+	configDir, _ := os.UserConfigDir()
+	path := filepath.Join(configDir, "splunk-cli", "config.json")
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(`{"host": "splunk.example.com",`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := loadConfig(); err == nil {
+		t.Error("Expected the broken config file to fail")
+	}
+	if err := configure("splunk.example.com", 8089, "https", "token", ""); err == nil {
+		t.Error("Expected configure to fail rather than replace the broken config file")
+	}
+	if data, _ := os.ReadFile(path); string(data) != `{"host": "splunk.example.com",` {
+		t.Errorf("Expected the config file to be unchanged, got: %s", data)
+	}
+}
@@ -0,0 +1,223 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kitproj/splunk-cli/pkg/splunk"
+)
+
+// dispatchArgs selects the search jobs whose artifacts 'splunk dispatch usage' reports or cleans
+type dispatchArgs struct {
+	user      string
+	olderThan string
+	clean     bool
+	// includeSaved cleans saved jobs too, which are otherwise kept as someone chose to keep them
+	includeSaved bool
+	yes          bool
+	format       string
+}
+
+func dispatchCommand() *command {
+	var args dispatchArgs
+	var format *string
+	return &command{
+		name:  "dispatch",
+		short: "Report and clean up search job artifacts on the search head",
+		subcommands: []*command{
+			{
+				name:  "usage",
+				short: "Show how many search job artifacts each user has, and their size",
+				long: "Show how many search jobs each user has in the search head's dispatch directory, and the size of their artifacts,\n" +
+					"largest first, to find who is filling it up before searches fail with \"dispatch directory full\".\n" +
+					"With --clean, delete the artifacts of the finished jobs that --user and --older-than select instead, after asking\n" +
+					"(or without asking, with --yes). Saved jobs are kept unless --include-saved is given.\n" +
+					"Deleting other users' jobs requires the admin_all_objects capability.",
+				flags: func(flags *flag.FlagSet) {
+					flags.StringVar(&args.user, "user", "", "only jobs of this user, or me for the current user (default: every user)")
+					flags.StringVar(&args.olderThan, "older-than", "", "only jobs dispatched longer ago than this, e.g. 2d or 12h (required with --clean)")
+					flags.BoolVar(&args.clean, "clean", false, "delete the selected jobs' artifacts rather than reporting them")
+					flags.BoolVar(&args.includeSaved, "include-saved", false, "delete the artifacts of saved jobs too, with --clean")
+					flags.BoolVar(&args.yes, "yes", false, "delete without asking, with --clean")
+					format = outputFlag(flags)
+				},
+				run: func(ctx context.Context, _ []string) error {
+					if args.clean && args.olderThan == "" {
+						return withExitCode(exitUsage, fmt.Errorf("--clean requires --older-than, e.g. --older-than 2d"))
+					}
+					args.format = *format
+					return executeCommand(ctx, func(ctx context.Context) error {
+						return runDispatchUsage(ctx, args)
+					})
+				},
+			},
+		},
+	}
+}
+
+// runDispatchUsage reports the artifacts of each user's search jobs, or deletes the selected ones with --clean
+func runDispatchUsage(ctx context.Context, args dispatchArgs) error {
+	var olderThan time.Duration
+	if args.olderThan != "" {
+		var err error
+		if olderThan, err = parseAge(args.olderThan); err != nil {
+			return withExitCode(exitUsage, err)
+		}
+	}
+	user := args.user
+	if user == "me" {
+		var err error
+		if user, _, err = client.CurrentUser(ctx); err != nil {
+			return fmt.Errorf("failed to get the current user: %w", err)
+		}
+	}
+
+	jobs, err := client.ListJobs(ctx, 0)
+	if err != nil {
+		return fmt.Errorf("failed to list search jobs: %w", err)
+	}
+	jobs, saved := selectJobs(jobs, user, olderThan, args.clean, args.includeSaved, time.Now())
+	if saved > 0 {
+		slog.Info("Keeping saved search jobs, use --include-saved to delete them too", "jobs", saved)
+	}
+	if !args.clean {
+		writer, err := newWriter(os.Stdout, args.format)
+		if err != nil {
+			return err
+		}
+		for _, row := range dispatchUsage(jobs) {
+			if err := writer.Write(row); err != nil {
+				return err
+			}
+		}
+		return writer.Close()
+	}
+
+	if len(jobs) == 0 {
+		slog.Info("No search jobs to delete")
+		return nil
+	}
+	var size int64
+	for _, job := range jobs {
+		size += int64(job.DiskUsage)
+	}
+	if !args.yes {
+		if !isTerminal(os.Stdin) {
+			return withExitCode(exitUsage, fmt.Errorf("not deleting %d search jobs without asking: use --yes to delete them", len(jobs)))
+		}
+		ok, err := confirm(bufio.NewReader(os.Stdin), fmt.Sprintf("Delete %d search jobs, using %s MB?", len(jobs), megabytes(size)))
+		if err != nil || !ok {
+			return err
+		}
+	}
+
+	failed := 0
+	for _, job := range jobs {
+		if err := client.CancelSearch(ctx, job.SID); err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			failed++
+			slog.Warn("Failed to delete search job", "sid", job.SID, "err", err)
+		}
+	}
+	slog.Info("Deleted search jobs", "jobs", len(jobs)-failed, "size_mb", megabytes(size))
+	if failed > 0 {
+		return withExitCode(exitPartial, fmt.Errorf("failed to delete %d of %d search jobs", failed, len(jobs)))
+	}
+	return nil
+}
+
+// selectJobs returns the jobs of a user, or every user, dispatched longer ago than olderThan. Only
+// finished jobs are selected for cleaning, so running searches are left alone, and saved jobs only
+// if includeSaved is set; it returns how many saved jobs were left out as well.
+func selectJobs(jobs []splunk.Job, user string, olderThan time.Duration, clean, includeSaved bool, now time.Time) ([]splunk.Job, int) {
+	var selected []splunk.Job
+	saved := 0
+	for _, job := range jobs {
+		if user != "" && job.Owner != user {
+			continue
+		}
+		if clean && !bool(job.IsDone) {
+			continue
+		}
+		if olderThan > 0 {
+			published, err := time.Parse(time.RFC3339, job.Published)
+			if err != nil || now.Sub(published) < olderThan {
+				continue
+			}
+		}
+		if clean && bool(job.IsSaved) && !includeSaved {
+			saved++
+			continue
+		}
+		selected = append(selected, job)
+	}
+	return selected, saved
+}
+
+// dispatchUsage sums the jobs and artifact sizes of each user, largest first
+func dispatchUsage(jobs []splunk.Job) []map[string]interface{} {
+	type usage struct {
+		user   string
+		jobs   int
+		size   int64
+		oldest string
+	}
+	byUser := map[string]*usage{}
+	for _, job := range jobs {
+		u := byUser[job.Owner]
+		if u == nil {
+			u = &usage{user: job.Owner}
+			byUser[job.Owner] = u
+		}
+		u.jobs++
+		u.size += int64(job.DiskUsage)
+		// Timestamps in the same format and zone sort as strings
+		if u.oldest == "" || job.Published < u.oldest {
+			u.oldest = job.Published
+		}
+	}
+
+	users := make([]*usage, 0, len(byUser))
+	for _, u := range byUser {
+		users = append(users, u)
+	}
+	sort.Slice(users, func(i, j int) bool {
+		if users[i].size != users[j].size {
+			return users[i].size > users[j].size
+		}
+		return users[i].user < users[j].user
+	})
+	rows := make([]map[string]interface{}, len(users))
+	for i, u := range users {
+		rows[i] = map[string]interface{}{"user": u.user, "jobs": u.jobs, "size_mb": megabytes(u.size), "oldest": u.oldest}
+	}
+	return rows
+}
+
+// megabytes formats a size in bytes as megabytes, to one decimal place
+func megabytes(bytes int64) string {
+	return strconv.FormatFloat(float64(bytes)/(1<<20), 'f', 1, 64)
+}
+
+// parseAge parses how long ago something was, as a duration, or in days, e.g. 2d
+func parseAge(s string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err == nil && n > 0 {
+			return time.Duration(n) * 24 * time.Hour, nil
+		}
+	} else if d, err := time.ParseDuration(s); err == nil && d > 0 {
+		return d, nil
+	}
+	return 0, fmt.Errorf("expected an age such as 2d or 12h, got %q", s)
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/kitproj/splunk-cli/pkg/splunk"
+)
+
+func TestDispatchUsage(t *testing.T) {
+	now := time.Date(2024, 6, 10, 0, 0, 0, 0, time.UTC)
+	jobs := []splunk.Job{
+		{SID: "1", Owner: "alice", IsDone: true, Published: "2024-06-09T12:00:00.000+00:00", DiskUsage: 1 << 20},
+		{SID: "2", Owner: "alice", IsDone: true, Published: "2024-06-01T12:00:00.000+00:00", DiskUsage: 3 << 20},
+		{SID: "3", Owner: "bob", IsDone: true, Published: "2024-06-02T12:00:00.000+00:00", DiskUsage: 10 << 20},
+		{SID: "4", Owner: "bob", IsDone: false, Published: "2024-06-02T12:00:00.000+00:00", DiskUsage: 1 << 20},
+		{SID: "5", Owner: "bob", IsDone: true, IsSaved: true, Published: "2024-06-02T12:00:00.000+00:00", DiskUsage: 1 << 20},
+	}
+
+	want := []map[string]interface{}{
+		{"user": "bob", "jobs": 3, "size_mb": "12.0", "oldest": "2024-06-02T12:00:00.000+00:00"},
+		{"user": "alice", "jobs": 2, "size_mb": "4.0", "oldest": "2024-06-01T12:00:00.000+00:00"},
+	}
+	if got, _ := selectJobs(jobs, "", 0, false, false, now); !reflect.DeepEqual(dispatchUsage(got), want) {
+		t.Errorf("Expected %v, got %v", want, dispatchUsage(got))
+	}
+
+	// Saved jobs are only cleaned if asked to, and are counted if not
+	for _, tt := range []struct {
+		includeSaved bool
+		want         []string
+		saved        int
+	}{
+		{false, []string{"2", "3"}, 1},
+		{true, []string{"2", "3", "5"}, 0},
+	} {
+		selected, saved := selectJobs(jobs, "", 2*24*time.Hour, true, tt.includeSaved, now)
+		var sids []string
+		for _, job := range selected {
+			sids = append(sids, job.SID)
+		}
+		if !reflect.DeepEqual(sids, tt.want) || saved != tt.saved {
+			t.Errorf("Expected to clean %v keeping %d saved jobs, got %v keeping %d", tt.want, tt.saved, sids, saved)
+		}
+	}
+	if got, _ := selectJobs(jobs, "alice", 2*24*time.Hour, true, false, now); len(got) != 1 || got[0].SID != "2" {
+		t.Errorf("Expected to clean alice's old job, got %v", got)
+	}
+}
+
+func TestParseAge(t *testing.T) {
+	for s, want := range map[string]time.Duration{"2d": 48 * time.Hour, "12h": 12 * time.Hour, "90m": 90 * time.Minute} {
+		if got, err := parseAge(s); err != nil || got != want {
+			t.Errorf("Expected %s for %s, got %s (%v)", want, s, got, err)
+		}
+	}
+	for _, s := range []string{"", "d", "-1d", "2w", "0h"} {
+		if _, err := parseAge(s); err == nil {
+			t.Errorf("Expected an error for %q", s)
+		}
+	}
+}
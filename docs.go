@@ -0,0 +1,126 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+func docsCommand(root *command) *command {
+	var manDir *string
+	return &command{
+		name:  "docs",
+		short: "Print the full command reference, or write man pages",
+		long:  "Print the reference of every command and its flags to stdout.\nWith --man-dir, write a man page for each command to the directory instead.",
+		flags: func(flags *flag.FlagSet) {
+			manDir = flags.String("man-dir", "", "directory to write man pages to")
+		},
+		run: func(ctx context.Context, args []string) error {
+			if *manDir != "" {
+				return writeManPages(root, *manDir)
+			}
+			return writeReference(os.Stdout, root)
+		},
+	}
+}
+
+// writeReference writes the help of every command in the tree
+func writeReference(w io.Writer, root *command) error {
+	var buf bytes.Buffer
+	for i, cmd := range root.tree() {
+		if i > 0 {
+			fmt.Fprintln(&buf)
+		}
+		fmt.Fprintf(&buf, "%s\n%s\n\n", cmd.path(), strings.Repeat("=", len(cmd.path())))
+		cmd.printHelp(&buf)
+	}
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// writeManPages writes a section 1 man page for every command
+func writeManPages(root *command, dir string) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create man page directory: %w", err)
+	}
+	for _, cmd := range root.tree() {
+		// Plugins pass their arguments through, and ship their own documentation
+		if cmd.rawArgs {
+			continue
+		}
+		name := strings.ReplaceAll(cmd.path(), " ", "-")
+		path := filepath.Join(dir, name+".1")
+		if err := os.WriteFile(path, manPage(cmd), 0644); err != nil {
+			return fmt.Errorf("failed to write %s: %w", path, err)
+		}
+	}
+	return nil
+}
+
+// manPage renders a command as a roff man page
+func manPage(cmd *command) []byte {
+	var buf bytes.Buffer
+	name := strings.ReplaceAll(cmd.path(), " ", "-")
+	fmt.Fprintf(&buf, ".TH %s 1 \"\" \"splunk %s\" \"Splunk CLI Manual\"\n", strings.ToUpper(roff(name)), roff(version))
+	fmt.Fprintf(&buf, ".SH NAME\n%s \\- %s\n", roff(name), roff(cmd.short))
+	fmt.Fprintf(&buf, ".SH SYNOPSIS\n.B %s\n", roff(cmd.usage()))
+	fmt.Fprintln(&buf, ".SH DESCRIPTION")
+	for i, line := range strings.Split(cmd.description(), "\n") {
+		if i > 0 {
+			fmt.Fprintln(&buf, ".PP")
+		}
+		fmt.Fprintln(&buf, roff(line))
+	}
+
+	if hasFlags(cmd.flagSet()) {
+		fmt.Fprintln(&buf, ".SH OPTIONS")
+		cmd.flagSet().VisitAll(func(f *flag.Flag) {
+			_, usage := flag.UnquoteUsage(f)
+			fmt.Fprintf(&buf, ".TP\n.B \\-%s\n%s\n", roff(f.Name), roff(usage))
+			if f.DefValue != "" && f.DefValue != "false" && f.DefValue != "0" {
+				fmt.Fprintf(&buf, "(default: %s)\n", roff(f.DefValue))
+			}
+		})
+	}
+
+	fmt.Fprintln(&buf, ".SH EXIT STATUS")
+	for _, c := range exitCodes {
+		fmt.Fprintf(&buf, ".TP\n.B %d\n%s\n", c.code, roff(c.description))
+	}
+
+	var related []string
+	for _, sub := range cmd.subcommands {
+		if !sub.hidden {
+			related = append(related, strings.ReplaceAll(sub.path(), " ", "-"))
+		}
+	}
+	if cmd.parent != nil {
+		related = append(related, strings.ReplaceAll(cmd.parent.path(), " ", "-"))
+	}
+	if len(related) > 0 {
+		fmt.Fprintln(&buf, ".SH SEE ALSO")
+		for i, name := range related {
+			sep := ","
+			if i == len(related)-1 {
+				sep = ""
+			}
+			fmt.Fprintf(&buf, ".BR %s (1)%s\n", roff(name), sep)
+		}
+	}
+	return buf.Bytes()
+}
+
+// roff escapes text for a roff document
+func roff(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\e`)
+	s = strings.ReplaceAll(s, "-", `\-`)
+	if strings.HasPrefix(s, ".") || strings.HasPrefix(s, "'") {
+		s = `\&` + s
+	}
+	return s
+}
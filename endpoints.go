@@ -0,0 +1,24 @@
+package main
+
+// The list and get commands of the endpoints in pkg/splunk/endpoints.json are generated,
+// along with their client methods. Run go generate after changing it.
+
+//go:generate go run ./internal/codegen
+
+// selectColumns returns the name and the given columns of a row
+func selectColumns(row map[string]interface{}, columns ...string) map[string]interface{} {
+	selected := map[string]interface{}{"name": row["name"]}
+	for _, column := range columns {
+		selected[column] = row[column]
+	}
+	return selected
+}
+
+// multivalue converts a multivalue field so output formats write it like one in search results
+func multivalue(values []string) []interface{} {
+	converted := make([]interface{}, len(values))
+	for i, value := range values {
+		converted[i] = value
+	}
+	return converted
+}
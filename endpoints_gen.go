@@ -0,0 +1,209 @@
+// Code generated by go run ./internal/codegen; DO NOT EDIT.
+
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/kitproj/splunk-cli/pkg/splunk"
+)
+
+// generatedCommands are the commands of the endpoints in pkg/splunk/endpoints.json
+func generatedCommands() []*command {
+	return []*command{
+		appsCommand(),
+		usersCommand(),
+		rolesCommand(),
+	}
+}
+
+func appsCommand() *command {
+	var listFormat, getFormat *string
+	return &command{
+		name:  "apps",
+		short: "List and show installed apps",
+		subcommands: []*command{
+			{
+				name:    "list",
+				aliases: []string{"ls"},
+				short:   "List the installed apps",
+				flags: func(flags *flag.FlagSet) {
+					listFormat = outputFlag(flags)
+				},
+				run: func(ctx context.Context, args []string) error {
+					return executeCommand(ctx, func(ctx context.Context) error {
+						items, err := client.ListApps(ctx)
+						if err != nil {
+							return fmt.Errorf("failed to list installed apps: %w", err)
+						}
+						rows := make([]map[string]interface{}, len(items))
+						for i, item := range items {
+							rows[i] = selectColumns(appFields(item), "label", "version", "visible", "disabled")
+						}
+						return writeAll(os.Stdout, *listFormat, rows)
+					})
+				},
+			},
+			{
+				name:    "get",
+				args:    "<name>",
+				short:   "Print every field of the app <name>",
+				minArgs: 1,
+				maxArgs: 1,
+				flags: func(flags *flag.FlagSet) {
+					getFormat = outputFlag(flags)
+				},
+				run: func(ctx context.Context, args []string) error {
+					return executeCommand(ctx, func(ctx context.Context) error {
+						item, err := client.GetApp(ctx, args[0])
+						if err != nil {
+							return fmt.Errorf("failed to get app: %w", err)
+						}
+						return writeAll(os.Stdout, *getFormat, []map[string]interface{}{appFields(*item)})
+					})
+				},
+			},
+		},
+	}
+}
+
+// appFields returns the fields of the app as an output row
+func appFields(item splunk.App) map[string]interface{} {
+	return map[string]interface{}{
+		"name":        item.Name,
+		"label":       item.Label,
+		"version":     item.Version,
+		"description": item.Description,
+		"author":      item.Author,
+		"visible":     item.Visible,
+		"disabled":    item.Disabled,
+	}
+}
+
+func usersCommand() *command {
+	var listFormat, getFormat *string
+	return &command{
+		name:  "users",
+		short: "List and show users",
+		subcommands: []*command{
+			{
+				name:    "list",
+				aliases: []string{"ls"},
+				short:   "List the users",
+				flags: func(flags *flag.FlagSet) {
+					listFormat = outputFlag(flags)
+				},
+				run: func(ctx context.Context, args []string) error {
+					return executeCommand(ctx, func(ctx context.Context) error {
+						items, err := client.ListUsers(ctx)
+						if err != nil {
+							return fmt.Errorf("failed to list users: %w", err)
+						}
+						rows := make([]map[string]interface{}, len(items))
+						for i, item := range items {
+							rows[i] = selectColumns(userFields(item), "realname", "email", "roles")
+						}
+						return writeAll(os.Stdout, *listFormat, rows)
+					})
+				},
+			},
+			{
+				name:    "get",
+				args:    "<name>",
+				short:   "Print every field of the user <name>",
+				minArgs: 1,
+				maxArgs: 1,
+				flags: func(flags *flag.FlagSet) {
+					getFormat = outputFlag(flags)
+				},
+				run: func(ctx context.Context, args []string) error {
+					return executeCommand(ctx, func(ctx context.Context) error {
+						item, err := client.GetUser(ctx, args[0])
+						if err != nil {
+							return fmt.Errorf("failed to get user: %w", err)
+						}
+						return writeAll(os.Stdout, *getFormat, []map[string]interface{}{userFields(*item)})
+					})
+				},
+			},
+		},
+	}
+}
+
+// userFields returns the fields of the user as an output row
+func userFields(item splunk.User) map[string]interface{} {
+	return map[string]interface{}{
+		"name":       item.Name,
+		"realname":   item.RealName,
+		"email":      item.Email,
+		"roles":      multivalue(item.Roles),
+		"defaultApp": item.DefaultApp,
+		"type":       item.Type,
+	}
+}
+
+func rolesCommand() *command {
+	var listFormat, getFormat *string
+	return &command{
+		name:  "roles",
+		short: "List and show roles",
+		subcommands: []*command{
+			{
+				name:    "list",
+				aliases: []string{"ls"},
+				short:   "List the roles",
+				flags: func(flags *flag.FlagSet) {
+					listFormat = outputFlag(flags)
+				},
+				run: func(ctx context.Context, args []string) error {
+					return executeCommand(ctx, func(ctx context.Context) error {
+						items, err := client.ListRoles(ctx)
+						if err != nil {
+							return fmt.Errorf("failed to list roles: %w", err)
+						}
+						rows := make([]map[string]interface{}, len(items))
+						for i, item := range items {
+							rows[i] = selectColumns(roleFields(item), "imported_roles", "srchIndexesAllowed", "srchJobsQuota")
+						}
+						return writeAll(os.Stdout, *listFormat, rows)
+					})
+				},
+			},
+			{
+				name:    "get",
+				args:    "<name>",
+				short:   "Print every field of the role <name>",
+				minArgs: 1,
+				maxArgs: 1,
+				flags: func(flags *flag.FlagSet) {
+					getFormat = outputFlag(flags)
+				},
+				run: func(ctx context.Context, args []string) error {
+					return executeCommand(ctx, func(ctx context.Context) error {
+						item, err := client.GetRole(ctx, args[0])
+						if err != nil {
+							return fmt.Errorf("failed to get role: %w", err)
+						}
+						return writeAll(os.Stdout, *getFormat, []map[string]interface{}{roleFields(*item)})
+					})
+				},
+			},
+		},
+	}
+}
+
+// roleFields returns the fields of the role as an output row
+func roleFields(item splunk.Role) map[string]interface{} {
+	return map[string]interface{}{
+		"name":               item.Name,
+		"imported_roles":     multivalue(item.ImportedRoles),
+		"capabilities":       multivalue(item.Capabilities),
+		"srchIndexesAllowed": multivalue(item.SearchIndexesAllowed),
+		"srchIndexesDefault": multivalue(item.SearchIndexesDefault),
+		"srchJobsQuota":      int64(item.SearchJobsQuota),
+		"srchDiskQuota":      int64(item.SearchDiskQuota),
+	}
+}
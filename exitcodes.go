@@ -0,0 +1,106 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/kitproj/splunk-cli/pkg/splunk"
+)
+
+// Exit codes are a documented contract, so scripts can branch on why a command failed without
+// parsing its error message
+const (
+	exitError        = 1
+	exitUsage        = 2
+	exitAuth         = 3
+	exitNetwork      = 4
+	exitSearchFailed = 5
+	exitTimeout      = 6
+	exitPartial      = 7
+)
+
+// exitCodes describes each exit code, for help and man pages
+var exitCodes = []struct {
+	code        int
+	name        string
+	description string
+}{
+	{0, "ok", "success"},
+	{exitError, "error", "any other error"},
+	{exitUsage, "usage", "invalid command, flags or arguments"},
+	{exitAuth, "auth", "authentication failed or permission denied (401 or 403)"},
+	{exitNetwork, "network", "network error, or Splunk is unavailable or rate limiting requests (429, 502, 503 or 504)"},
+	{exitSearchFailed, "search failed", "the search failed, e.g. with a syntax error"},
+	{exitTimeout, "timeout", "a request or search timed out"},
+	{exitPartial, "partial results", "the results are partial, with --strict, or slices of a partitioned export failed"},
+}
+
+// exitCodesHelp summarizes the exit codes in one line
+func exitCodesHelp() string {
+	var codes []string
+	for _, c := range exitCodes {
+		codes = append(codes, fmt.Sprintf("%d %s", c.code, c.name))
+	}
+	return "Exit codes: " + strings.Join(codes, ", ")
+}
+
+// exitCodeError is an error with the exit code it should end the CLI with
+type exitCodeError struct {
+	code int
+	err  error
+}
+
+func (e *exitCodeError) Error() string {
+	return e.err.Error()
+}
+
+func (e *exitCodeError) Unwrap() error {
+	return e.err
+}
+
+// withExitCode marks an error with the exit code it should end the CLI with
+func withExitCode(code int, err error) error {
+	return &exitCodeError{code: code, err: err}
+}
+
+// searchFailed marks an error dispatching a search as the search failing, if splunkd rejected it
+// as invalid, e.g. for a syntax error, rather than failing for another reason such as the token
+func searchFailed(err error) error {
+	var apiErr *splunk.APIError
+	if errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusBadRequest {
+		return withExitCode(exitSearchFailed, err)
+	}
+	return err
+}
+
+// exitCode returns the exit code an error should end the CLI with
+func exitCode(err error) int {
+	var coded *exitCodeError
+	if errors.As(err, &coded) {
+		return coded.code
+	}
+	var apiErr *splunk.APIError
+	if errors.As(err, &apiErr) {
+		switch apiErr.StatusCode {
+		case http.StatusUnauthorized, http.StatusForbidden:
+			return exitAuth
+		case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+			return exitNetwork
+		}
+		return exitError
+	}
+	var netErr net.Error
+	if errors.Is(err, context.DeadlineExceeded) || (errors.As(err, &netErr) && netErr.Timeout()) {
+		return exitTimeout
+	}
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) || errors.As(err, &netErr) {
+		return exitNetwork
+	}
+	return exitError
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kitproj/splunk-cli/pkg/splunk"
+)
+
+func TestExitCode(t *testing.T) {
+	root := (&command{name: "splunk", subcommands: []*command{{name: "results", minArgs: 1, maxArgs: 1, run: func(ctx context.Context, args []string) error { return nil }}}}).link()
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{"other", fmt.Errorf("boom"), exitError},
+		{"unknown command", root.execute(context.Background(), []string{"bogus"}), exitUsage},
+		{"missing argument", root.execute(context.Background(), []string{"results"}), exitUsage},
+		{"unknown flag", root.execute(context.Background(), []string{"results", "-bogus", "123"}), exitUsage},
+		{"bad token", fmt.Errorf("failed to run search: %w", &splunk.APIError{StatusCode: 401}), exitAuth},
+		{"failed login", fmt.Errorf("failed to log in as admin: %w", &splunk.APIError{StatusCode: 401}), exitAuth},
+		{"unavailable", &splunk.APIError{StatusCode: 503}, exitNetwork},
+		{"server error", &splunk.APIError{StatusCode: 500}, exitError},
+		{"connection refused", &url.Error{Op: "Get", URL: "https://splunk:8089", Err: &net.OpError{Op: "dial", Err: fmt.Errorf("connection refused")}}, exitNetwork},
+		{"timeout", fmt.Errorf("failed to get search status: %w", context.DeadlineExceeded), exitTimeout},
+		{"syntax error", searchFailed(fmt.Errorf("failed to run search: %w", &splunk.APIError{StatusCode: 400})), exitSearchFailed},
+		{"search with bad token", searchFailed(fmt.Errorf("failed to run search: %w", &splunk.APIError{StatusCode: 403})), exitAuth},
+		{"partial", checkComplete(nil, &splunk.SearchResult{Messages: splunk.Messages{{Type: "WARN", Text: "peer down"}}}, 0, 0, true, newProgress(false)), exitPartial},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := exitCode(tt.err); got != tt.want {
+				t.Errorf("Expected exit code %d for %v, got %d", tt.want, tt.err, got)
+			}
+		})
+	}
+}
+
+func TestWithTimeout(t *testing.T) {
+	defer func(d time.Duration) { commandTimeout = d }(commandTimeout)
+	commandTimeout = 10 * time.Millisecond
+
+	err := withTimeout(context.Background(), func(ctx context.Context) error {
+		<-ctx.Done()
+		return fmt.Errorf("failed to get search status: %w", ctx.Err())
+	})
+	if got := exitCode(err); got != exitTimeout {
+		t.Errorf("Expected exit code %d for %v, got %d", exitTimeout, err, got)
+	}
+	if err == nil || !strings.Contains(err.Error(), "timed out after 10ms") {
+		t.Errorf("Expected the timeout in the error, got %v", err)
+	}
+}
@@ -0,0 +1,204 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/kitproj/splunk-cli/pkg/splunk"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// commandDescriptions say what common SPL commands do, to explain a search's stages
+var commandDescriptions = map[string]string{
+	"append":       "appends the results of a subsearch",
+	"appendcols":   "appends the fields of a subsearch's results to the results, row by row",
+	"bin":          "puts a numeric or time field's values into buckets",
+	"bucket":       "puts a numeric or time field's values into buckets",
+	"chart":        "aggregates the results into a table, by one or two fields",
+	"collect":      "writes the results to a summary index",
+	"convert":      "converts fields' values, e.g. times to strings",
+	"datamodel":    "searches a data model's dataset",
+	"dedup":        "removes results with the same values of the fields as an earlier one",
+	"delete":       "makes the events unsearchable",
+	"eval":         "calculates fields from expressions",
+	"eventstats":   "adds aggregates of all the results to each result",
+	"fields":       "keeps or removes fields",
+	"fillnull":     "replaces empty fields' values",
+	"head":         "keeps the first results",
+	"inputlookup":  "reads the results from a lookup",
+	"iplocation":   "adds the location of IP addresses",
+	"join":         "joins the results with a subsearch's on fields",
+	"lookup":       "adds fields from a lookup by matching fields",
+	"makemv":       "splits a field into a multivalue field",
+	"makeresults":  "generates empty results, e.g. to test evals",
+	"map":          "runs a search for each result",
+	"metadata":     "lists the hosts, sources or sourcetypes of indexes",
+	"mstats":       "aggregates metrics from metrics indexes",
+	"multisearch":  "runs several streaming searches at once",
+	"mvexpand":     "splits a multivalue field's values into separate results",
+	"outputcsv":    "writes the results to a CSV file",
+	"outputlookup": "writes the results to a lookup",
+	"predict":      "forecasts the future values of fields",
+	"rare":         "finds the least common values of fields",
+	"regex":        "keeps the results whose field matches a regular expression",
+	"rename":       "renames fields",
+	"rest":         "reads a Splunk REST endpoint",
+	"reverse":      "reverses the order of the results",
+	"rex":          "extracts fields with a regular expression",
+	"search":       "filters events or results by terms and field values",
+	"sendemail":    "emails the results",
+	"sort":         "sorts the results by fields",
+	"spath":        "extracts fields from JSON or XML",
+	"stats":        "aggregates the results, optionally by fields",
+	"streamstats":  "adds running aggregates to each result, in order",
+	"table":        "keeps only the fields, as a table in that order",
+	"tail":         "keeps the last results",
+	"timechart":    "aggregates the results over time, optionally by a field",
+	"top":          "finds the most common values of fields",
+	"transaction":  "groups events into transactions",
+	"tstats":       "aggregates indexed fields or accelerated data models, without reading the raw events",
+	"where":        "keeps the results for which an expression is true",
+	"xyseries":     "turns results into a table of one field's values by another's",
+}
+
+// lookupCommands are the commands whose first argument that isn't an option is a lookup
+var lookupCommands = map[string]bool{"inputlookup": true, "lookup": true, "outputlookup": true}
+
+// indexTerm matches the index a search term such as index=main or index::"web" selects
+var indexTerm = regexp.MustCompile(`(?i)\bindex\s*(?:=|::)\s*("(?:[^"\\]|\\.)*"|[^\s|)\]]+)`)
+
+// explainedStage is a command of a search's pipeline, with what it does
+type explainedStage struct {
+	Command     string `json:"command"`
+	Args        string `json:"args,omitempty"`
+	Description string `json:"description,omitempty"`
+	// Writes is set for commands that write to indexes, lookups or files, or act outside Splunk
+	Writes bool `json:"writes,omitempty"`
+	// Blocked is set for commands the server's policy would refuse to run
+	Blocked bool `json:"blocked,omitempty"`
+}
+
+func explainQueryHandler(ctx context.Context, client splunk.API, request mcp.CallToolRequest, allowedSPL map[string]bool) (*mcp.CallToolResult, error) {
+	query, err := request.RequireString("query")
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Missing or invalid 'query' argument: %v", err)), nil
+	}
+
+	// The parser expands the query's macros, and rejects it if it's invalid
+	parsed, err := client.ParseSearch(ctx, ensureSearchCommand(query))
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("The query isn't valid: %v", err)), nil
+	}
+
+	stages := make([]explainedStage, len(parsed.Commands))
+	expanded := make([]string, len(parsed.Commands))
+	for i, command := range parsed.Commands {
+		// A stage writes or is blocked if it or its subsearches use such a command
+		expanded[i] = strings.TrimSpace(command.Command + " " + command.RawArgs)
+		stages[i] = explainedStage{
+			Command:     command.Command,
+			Args:        strings.TrimSpace(command.RawArgs),
+			Description: commandDescriptions[strings.ToLower(command.Command)],
+			Writes:      len(writeCommands(expanded[i])) > 0,
+			Blocked:     len(blockedCommands(expanded[i], allowedSPL)) > 0,
+		}
+	}
+	expandedQuery := strings.Join(expanded, " | ")
+	indexes, lookups := queryIndexes(expandedQuery), queryLookups(parsed.Commands)
+	macros := queryMacros(query)
+
+	var text strings.Builder
+	text.WriteString(fmt.Sprintf("The query is valid. With its macros expanded, it is:\n  %s\n\n", expandedQuery))
+	text.WriteString("Its stages:\n")
+	for i, stage := range stages {
+		text.WriteString(fmt.Sprintf("%d. %s", i+1, stage.Command))
+		if stage.Description != "" {
+			text.WriteString(" - " + stage.Description)
+		}
+		if stage.Writes {
+			text.WriteString(" (writes data)")
+		}
+		if stage.Blocked {
+			text.WriteString(" (blocked by the server's policy)")
+		}
+		text.WriteString("\n")
+	}
+	text.WriteString("\n")
+	if len(macros) > 0 {
+		text.WriteString(fmt.Sprintf("Macros: %s\n", strings.Join(macros, ", ")))
+	}
+	if len(indexes) > 0 {
+		text.WriteString(fmt.Sprintf("Indexes: %s\n", strings.Join(indexes, ", ")))
+	} else {
+		text.WriteString("Indexes: none named, so it searches the user's default indexes\n")
+	}
+	if len(lookups) > 0 {
+		text.WriteString(fmt.Sprintf("Lookups: %s\n", strings.Join(lookups, ", ")))
+	}
+	if parsed.RemoteSearch != "" {
+		text.WriteString(fmt.Sprintf("Part run on the indexers: %s\n", parsed.RemoteSearch))
+	}
+
+	result := mcp.NewToolResultText(text.String())
+	result.StructuredContent = map[string]interface{}{
+		"expanded_query": expandedQuery,
+		"stages":         stages,
+		"macros":         macros,
+		"indexes":        indexes,
+		"lookups":        lookups,
+		"remote_search":  parsed.RemoteSearch,
+	}
+	return result, nil
+}
+
+// queryIndexes returns the indexes a query's terms name, in order
+func queryIndexes(query string) []string {
+	indexes := []string{}
+	seen := map[string]bool{}
+	for _, match := range indexTerm.FindAllStringSubmatch(query, -1) {
+		index := match[1]
+		if strings.HasPrefix(index, `"`) {
+			index = strings.NewReplacer(`\"`, `"`, `\\`, `\`).Replace(index[1 : len(index)-1])
+		}
+		if !seen[index] {
+			seen[index] = true
+			indexes = append(indexes, index)
+		}
+	}
+	return indexes
+}
+
+// queryLookups returns the lookups a parsed query's commands read or write, in order
+func queryLookups(commands []splunk.ParsedCommand) []string {
+	lookups := []string{}
+	for _, command := range commands {
+		if !lookupCommands[strings.ToLower(command.Command)] {
+			continue
+		}
+		for _, arg := range strings.Fields(command.RawArgs) {
+			if !strings.Contains(arg, "=") {
+				lookups = append(lookups, strings.Trim(arg, `"`))
+				break
+			}
+		}
+	}
+	return lookups
+}
+
+// queryMacros returns the names of the macros a query calls, in order
+func queryMacros(query string) []string {
+	macros := []string{}
+	seen := map[string]bool{}
+	// Macro calls are between pairs of backticks, so are every other part of the query
+	parts := strings.Split(query, "`")
+	for i := 1; i < len(parts)-1; i += 2 {
+		name, _, _ := strings.Cut(parts[i], "(")
+		if name = strings.TrimSpace(name); name != "" && !seen[name] {
+			seen[name] = true
+			macros = append(macros, name)
+		}
+	}
+	return macros
+}
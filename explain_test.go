@@ -0,0 +1,65 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/kitproj/splunk-cli/pkg/splunk"
+	"github.com/kitproj/splunk-cli/pkg/splunk/splunktest"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestExplainQueryHandler(t *testing.T) {
+	fake := &splunktest.Fake{
+		ParseSearchFunc: func(ctx context.Context, searchQuery string) (*splunk.ParsedSearch, error) {
+			if searchQuery != "search `web_errors` | lookup hosts.csv host | sendemail to=ops@example.com" {
+				return nil, fmt.Errorf("unexpected query %q", searchQuery)
+			}
+			return &splunk.ParsedSearch{
+				RemoteSearch: `search index="web" status>=500`,
+				Commands: []splunk.ParsedCommand{
+					{Command: "search", RawArgs: `index="web" status>=500`},
+					{Command: "lookup", RawArgs: "local=t hosts.csv host"},
+					{Command: "sendemail", RawArgs: "to=ops@example.com"},
+				},
+			}, nil
+		},
+	}
+	request := mcp.CallToolRequest{Params: mcp.CallToolParams{Arguments: map[string]interface{}{
+		"query": "`web_errors` | lookup hosts.csv host | sendemail to=ops@example.com",
+	}}}
+	result, err := explainQueryHandler(context.Background(), fake, request, map[string]bool{})
+	if err != nil || result.IsError {
+		t.Fatalf("Expected a result, got %v and: %v", result, err)
+	}
+	structured := result.StructuredContent.(map[string]interface{})
+	if got := structured["expanded_query"]; got != `search index="web" status>=500 | lookup local=t hosts.csv host | sendemail to=ops@example.com` {
+		t.Errorf("Expected the expanded query, got: %v", got)
+	}
+	for name, want := range map[string][]string{"macros": {"web_errors"}, "indexes": {"web"}, "lookups": {"hosts.csv"}} {
+		if got := structured[name]; !reflect.DeepEqual(got, want) {
+			t.Errorf("Expected %s %v, got %v", name, want, got)
+		}
+	}
+	stages := structured["stages"].([]explainedStage)
+	if len(stages) != 3 || stages[1].Description == "" || stages[1].Writes || !stages[2].Writes || !stages[2].Blocked {
+		t.Errorf("Expected the sendemail stage to write and be blocked, got: %+v", stages)
+	}
+
+	fake.ParseSearchFunc = func(ctx context.Context, searchQuery string) (*splunk.ParsedSearch, error) {
+		return nil, &splunk.APIError{StatusCode: 400, Body: "Unknown search command 'stat'"}
+	}
+	result, err = explainQueryHandler(context.Background(), fake, request, nil)
+	if err != nil || !result.IsError {
+		t.Errorf("Expected an error result for an invalid query, got %v and: %v", result, err)
+	}
+}
+
+func TestQueryIndexes(t *testing.T) {
+	got := queryIndexes(`search (index=main OR index::"web app") sourcetype=x | append [search INDEX = _internal] | where index="main"`)
+	if want := []string{"main", "web app", "_internal"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+}
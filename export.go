@@ -0,0 +1,239 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/kitproj/splunk-cli/internal/output"
+	"github.com/kitproj/splunk-cli/pkg/splunk"
+)
+
+// sliceAttempts is how many times a failed slice of a partitioned export is tried before giving up on it
+const sliceAttempts = 3
+
+// timeSlice is one partition of an export's time range, from Earliest (inclusive) to Latest (exclusive)
+type timeSlice struct {
+	Earliest time.Time `json:"earliest"`
+	Latest   time.Time `json:"latest"`
+}
+
+func (s timeSlice) String() string {
+	return s.Earliest.Format(time.RFC3339) + " to " + s.Latest.Format(time.RFC3339)
+}
+
+// partitionRange splits earliest to latest into slices of size, newest first, which is the order
+// Splunk returns events in. The oldest slice is shorter if the range doesn't divide evenly.
+func partitionRange(earliest, latest time.Time, size time.Duration) []timeSlice {
+	var slices []timeSlice
+	for end := latest; end.After(earliest); end = end.Add(-size) {
+		start := end.Add(-size)
+		if start.Before(earliest) {
+			start = earliest
+		}
+		slices = append(slices, timeSlice{Earliest: start, Latest: end})
+	}
+	return slices
+}
+
+// exportPartitions exports each slice of the search's time range as a separate job, up to concurrency
+// at once, and writes their results in order. Each slice is spooled to a file so a failed attempt can
+// be retried without duplicating results. If slices still fail, the others are written and the
+// failures are reported in the error. The spooled slices and a ledger of their progress are kept
+// until every slice has succeeded, so with resume, only the slices that failed or never ran are exported again.
+func exportPartitions(ctx context.Context, query string, opts splunk.SearchOptions, partition time.Duration, concurrency int, resume bool, writer output.Writer) (int, error) {
+	ledger, err := openExportLedger(ctx, query, opts, partition, resume)
+	if err != nil {
+		return 0, err
+	}
+	slices := ledger.Slices
+	pending := 0
+	for i := range slices {
+		if !ledger.done(i) {
+			pending++
+		}
+	}
+	slog.Info("Exporting slices", "pending", pending, "slices", len(slices), "partition", partition.String(), "concurrency", concurrency)
+
+	// Workers are cancelled, then waited for, whenever this returns
+	var wg sync.WaitGroup
+	defer wg.Wait()
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// Each slice's error is sent once it is spooled, so slices can be written in order as they finish
+	done := make([]chan error, len(slices))
+	for i := range done {
+		done[i] = make(chan error, 1)
+	}
+	work := make(chan int)
+	for range max(concurrency, 1) {
+		wg.Add(1)
+		go func() {
+			defer recoverCrash()
+			defer wg.Done()
+			for i := range work {
+				n, err := exportSlice(ctx, query, opts, slices[i].timeSlice, ledger.spoolPath(i))
+				if ctx.Err() == nil {
+					if recordErr := ledger.record(i, n, err); err == nil {
+						err = recordErr
+					}
+				}
+				done[i] <- err
+			}
+		}()
+	}
+	go func() {
+		defer recoverCrash()
+		defer close(work)
+		for i := range slices {
+			if ledger.done(i) {
+				done[i] <- nil
+				continue
+			}
+			select {
+			case work <- i:
+			case <-ctx.Done():
+				for ; i < len(slices); i++ {
+					done[i] <- ctx.Err()
+				}
+				return
+			}
+		}
+	}()
+
+	count, failed := 0, 0
+	for i, slice := range slices {
+		if err := <-done[i]; err != nil {
+			if ctx.Err() != nil {
+				slog.Warn("Export interrupted; run it again with --resume to export the remaining slices")
+				return count, ctx.Err()
+			}
+			failed++
+			slog.Warn("Slice failed", "slice", slice.timeSlice.String(), "err", err)
+			continue
+		}
+		n, err := copySpool(ledger.spoolPath(i), writer)
+		count += n
+		if err != nil {
+			return count, err
+		}
+	}
+	if failed > 0 {
+		slog.Info("Run the export again with --resume to retry only the failed slices")
+		return count, withExitCode(exitPartial, fmt.Errorf("%d of %d slices failed", failed, len(slices)))
+	}
+	if err := ledger.remove(); err != nil {
+		return count, fmt.Errorf("failed to remove export ledger: %w", err)
+	}
+	return count, nil
+}
+
+// openExportLedger loads the ledger of an earlier run to resume, or starts a new one by resolving the
+// time range. A resumed export keeps the original run's time range, even if it was relative, such as -7d.
+func openExportLedger(ctx context.Context, query string, opts splunk.SearchOptions, partition time.Duration, resume bool) (*exportLedger, error) {
+	if opts.EarliestTime == "" {
+		return nil, fmt.Errorf("--partition requires an earliest time")
+	}
+	dir, err := ledgerDir(query, opts, partition)
+	if err != nil {
+		return nil, err
+	}
+	if resume {
+		ledger, err := loadExportLedger(dir)
+		if err != nil {
+			return nil, err
+		}
+		if ledger != nil {
+			return ledger, nil
+		}
+		slog.Info("No earlier run of this export to resume; exporting every slice")
+	}
+
+	earliest, err := client.ResolveTime(ctx, opts.EarliestTime)
+	if err != nil {
+		return nil, err
+	}
+	latest, err := client.ResolveTime(ctx, defaultString(opts.LatestTime, "now"))
+	if err != nil {
+		return nil, err
+	}
+	slices := partitionRange(earliest, latest, partition)
+	if len(slices) == 0 {
+		return nil, fmt.Errorf("the time range %s to %s is empty", opts.EarliestTime, defaultString(opts.LatestTime, "now"))
+	}
+	return newExportLedger(dir, query, partition, slices)
+}
+
+// exportSlice exports a slice's results to path, retrying failed attempts, and returns how many there were
+func exportSlice(ctx context.Context, query string, opts splunk.SearchOptions, slice timeSlice, path string) (int, error) {
+	opts.EarliestTime = strconv.FormatInt(slice.Earliest.Unix(), 10)
+	opts.LatestTime = strconv.FormatInt(slice.Latest.Unix(), 10)
+
+	var err error
+	for attempt := 1; attempt <= sliceAttempts; attempt++ {
+		if attempt > 1 {
+			slog.Warn("Retrying slice", "slice", slice.String(), "attempt", attempt, "attempts", sliceAttempts, "err", err)
+		}
+		if err = waitForJobSlot(ctx, client); err != nil {
+			return 0, err
+		}
+		var n int
+		if n, err = spoolExport(ctx, query, opts, path); err == nil || ctx.Err() != nil {
+			return n, err
+		}
+	}
+	return 0, err
+}
+
+// spoolExport exports the search's results to path as ndjson, replacing anything from an earlier attempt
+func spoolExport(ctx context.Context, query string, opts splunk.SearchOptions, path string) (int, error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create spool file: %w", err)
+	}
+	count := 0
+	enc := json.NewEncoder(f)
+	err = client.Export(ctx, ensureSearchCommand(query), opts, func(result map[string]interface{}) error {
+		count++
+		return enc.Encode(result)
+	})
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return 0, searchFailed(fmt.Errorf("failed to export search: %w", err))
+	}
+	return count, nil
+}
+
+// copySpool writes the results spooled to path, returning how many there were
+func copySpool(path string, writer output.Writer) (int, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, fmt.Errorf("failed to open spool file: %w", err)
+	}
+	defer f.Close()
+
+	count := 0
+	dec := json.NewDecoder(f)
+	for {
+		var result map[string]interface{}
+		if err := dec.Decode(&result); errors.Is(err, io.EOF) {
+			return count, nil
+		} else if err != nil {
+			return count, fmt.Errorf("failed to read spool file: %w", err)
+		}
+		if err := writer.Write(result); err != nil {
+			return count, err
+		}
+		count++
+	}
+}
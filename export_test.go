@@ -0,0 +1,110 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/kitproj/splunk-cli/internal/output"
+	"github.com/kitproj/splunk-cli/pkg/splunk"
+)
+
+func TestPartitionRange(t *testing.T) {
+	earliest := time.Unix(0, 0)
+	slices := partitionRange(earliest, earliest.Add(150*time.Minute), time.Hour)
+
+	var got []string
+	for _, s := range slices {
+		got = append(got, fmt.Sprintf("%d-%d", s.Earliest.Unix()/60, s.Latest.Unix()/60))
+	}
+	if strings.Join(got, ",") != "90-150,30-90,0-30" {
+		t.Errorf("Expected newest slices first, with a short oldest slice, got: %v", got)
+	}
+}
+
+func TestExportPartitions(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	t.Setenv("LocalAppData", t.TempDir())
+
+	var flaky atomic.Int32
+	var broken atomic.Bool
+	broken.Store(true)
+	exports := map[string]int{}
+	var mu sync.Mutex
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/services/search/timeparser":
+			epochs := map[string]string{"-3h": "0", "now": "10800"}
+			fmt.Fprintf(w, `{%q:%q}`, r.URL.Query().Get("time"), epochs[r.URL.Query().Get("time")])
+		case "/services/search/jobs/export":
+			earliest := r.FormValue("earliest_time")
+			mu.Lock()
+			exports[earliest]++
+			mu.Unlock()
+			switch {
+			case earliest == "0" && broken.Load():
+				w.WriteHeader(http.StatusInternalServerError)
+				return
+			case earliest == "3600" && flaky.Add(1) == 1:
+				// The first attempt fails part way through, after a result
+				fmt.Fprintf(w, "{\"result\":{\"slice\":%q}}\n{", earliest)
+				return
+			}
+			fmt.Fprintf(w, "{\"result\":{\"slice\":%q}}\n", earliest)
+		}
+	}))
+	defer server.Close()
+
+	saved := client
+	defer func() { client = saved }()
+	client = splunk.NewClient("localhost", "test-token")
+	client.BaseURL = server.URL
+
+	var buf bytes.Buffer
+	writer, _ := output.NewWriter(&buf, "ndjson")
+	opts := splunk.SearchOptions{EarliestTime: "-3h", LatestTime: "now"}
+	count, err := exportPartitions(context.Background(), "search *", opts, time.Hour, 2, false, writer)
+	if err == nil || !strings.Contains(err.Error(), "1 of 3 slices failed") {
+		t.Errorf("Expected the failing slice to be reported, got: %v", err)
+	}
+	if count != 2 {
+		t.Errorf("Expected 2 results, got: %d", count)
+	}
+	if got := buf.String(); got != "{\"slice\":\"7200\"}\n{\"slice\":\"3600\"}\n" {
+		t.Errorf("Expected the other slices in order without duplicates, got: %q", got)
+	}
+
+	// Resuming only exports the failed slice, and writes every slice
+	broken.Store(false)
+	buf.Reset()
+	count, err = exportPartitions(context.Background(), "search *", opts, time.Hour, 2, true, writer)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if count != 3 {
+		t.Errorf("Expected 3 results, got: %d", count)
+	}
+	if got := buf.String(); got != "{\"slice\":\"7200\"}\n{\"slice\":\"3600\"}\n{\"slice\":\"0\"}\n" {
+		t.Errorf("Expected every slice in order, got: %q", got)
+	}
+	if exports["7200"] != 1 || exports["3600"] != 2 || exports["0"] != 4 {
+		t.Errorf("Expected only the failed slice to be exported again, got: %v", exports)
+	}
+
+	dir, err := ledgerDir("search *", opts, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(dir); !os.IsNotExist(err) {
+		t.Errorf("Expected the ledger to be removed after a successful export, got: %v", err)
+	}
+}
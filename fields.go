@@ -0,0 +1,131 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kitproj/splunk-cli/internal/output"
+	"github.com/kitproj/splunk-cli/pkg/splunk"
+)
+
+func fieldsCommand() *command {
+	var sourcetype, format *string
+	var last *time.Duration
+	var examples *int
+	return &command{
+		name:    "fields",
+		args:    "<index>",
+		short:   "Summarize the fields of an index: coverage, distinct counts and example values",
+		long:    "Summarize the fields of an index's events with fieldsummary: the share of events each field is in,\nits number of distinct values and its most common values. Approximate distinct counts end with +.",
+		minArgs: 1,
+		maxArgs: 1,
+		flags: func(flags *flag.FlagSet) {
+			sourcetype = flags.String("sourcetype", "", "only summarize events of this sourcetype")
+			last = flags.Duration("last", 24*time.Hour, "summarize events from this long ago until now")
+			examples = flags.Int("examples", 3, "number of example values to show for each field")
+			format = flags.String("output", "table", "output format: "+strings.Join(output.Formats, ", "))
+			flags.StringVar(format, "o", "table", "shorthand for --output")
+		},
+		run: func(ctx context.Context, args []string) error {
+			return executeCommand(ctx, func(ctx context.Context) error {
+				return runFields(ctx, args[0], *sourcetype, *last, *examples, *format)
+			})
+		},
+	}
+}
+
+// runFields prints a row for each field of the index's events, most common first
+func runFields(ctx context.Context, index, sourcetype string, last time.Duration, examples int, format string) error {
+	writer, err := newWriter(os.Stdout, format)
+	if err != nil {
+		return err
+	}
+
+	query := fieldSummaryQuery(index, sourcetype, examples)
+	earliest := fmt.Sprintf("-%ds", int(last.Seconds()))
+
+	slog.Info("Running search", "query", query)
+	job := searchJob{client: client, query: query, opts: splunk.SearchOptions{EarliestTime: earliest, LatestTime: "now"}}
+	finished, err := job.run(ctx)
+	if err != nil {
+		return err
+	}
+
+	events := finished.status.Content.EventCount
+	slog.Info("Found fields", "fields", finished.returned, "events", events)
+	for _, row := range fieldSummaryRows(finished.results.Results, int(events), examples) {
+		if err := writer.Write(row); err != nil {
+			return err
+		}
+	}
+	return writer.Close()
+}
+
+// fieldSummaryQuery returns the search summarizing the fields of an index's events, optionally
+// only those of a sourcetype, with up to examples of each field's most common values
+func fieldSummaryQuery(index, sourcetype string, examples int) string {
+	query := "search index=" + quoteSPL(index)
+	if sourcetype != "" {
+		query += " sourcetype=" + quoteSPL(sourcetype)
+	}
+	return query + fmt.Sprintf(" | fieldsummary maxvals=%d", max(examples, 1))
+}
+
+// fieldSummaryRows converts the results of fieldsummary over events into rows of
+// each field's coverage, distinct count and example values, most common first
+func fieldSummaryRows(results []map[string]interface{}, events, examples int) []map[string]interface{} {
+	number := func(v interface{}) int {
+		n, _ := strconv.Atoi(output.FormatValue(v, ""))
+		return n
+	}
+	sort.SliceStable(results, func(i, j int) bool {
+		ci, cj := number(results[i]["count"]), number(results[j]["count"])
+		if ci != cj {
+			return ci > cj
+		}
+		return output.FormatValue(results[i]["field"], "") < output.FormatValue(results[j]["field"], "")
+	})
+
+	rows := make([]map[string]interface{}, 0, len(results))
+	for _, result := range results {
+		count := number(result["count"])
+		coverage := ""
+		if events > 0 {
+			coverage = fmt.Sprintf("%.1f%%", float64(count)*100/float64(events))
+		}
+		distinct := output.FormatValue(result["distinct_count"], "")
+		if output.FormatValue(result["is_exact"], "") == "0" {
+			distinct += "+"
+		}
+
+		// values is a JSON array of the most common values and their counts
+		var values []struct {
+			Value interface{} `json:"value"`
+		}
+		_ = json.Unmarshal([]byte(output.FormatValue(result["values"], "")), &values)
+		var example []interface{}
+		for _, v := range values {
+			if len(example) == examples {
+				break
+			}
+			example = append(example, output.FormatValue(v.Value, ""))
+		}
+
+		rows = append(rows, map[string]interface{}{
+			"field":    result["field"],
+			"events":   count,
+			"coverage": coverage,
+			"distinct": distinct,
+			"examples": example,
+		})
+	}
+	return rows
+}
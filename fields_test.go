@@ -0,0 +1,22 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFieldSummaryRows(t *testing.T) {
+	results := []map[string]interface{}{
+		{"field": "user", "count": "50", "distinct_count": "12", "is_exact": "1", "values": `[{"value":"alice","count":30},{"value":"bob","count":20}]`},
+		{"field": "status", "count": "200", "distinct_count": "100", "is_exact": "0", "values": `[{"value":"200","count":150},{"value":"404","count":40},{"value":"500","count":10}]`},
+	}
+
+	rows := fieldSummaryRows(results, 200, 2)
+	want := []map[string]interface{}{
+		{"field": "status", "events": 200, "coverage": "100.0%", "distinct": "100+", "examples": []interface{}{"200", "404"}},
+		{"field": "user", "events": 50, "coverage": "25.0%", "distinct": "12", "examples": []interface{}{"alice", "bob"}},
+	}
+	if !reflect.DeepEqual(rows, want) {
+		t.Errorf("Expected %v, got: %v", want, rows)
+	}
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"flag"
+	"strings"
+
+	"github.com/kitproj/splunk-cli/internal/output"
+)
+
+// parseFlags parses flags that may be interspersed with positional arguments,
+// e.g. "<sid> --post <spl>", and returns the positional arguments
+func parseFlags(flags *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for len(args) > 0 {
+		// Stop before relative times such as -1h, which would otherwise be parsed as flags
+		end := nextRelativeTime(flags, args)
+		if err := flags.Parse(args[:end]); err != nil {
+			return nil, err
+		}
+
+		remaining := flags.Args()
+		consumed := args[:end-len(remaining)]
+		// Everything after "--" is positional, even if it looks like a flag
+		if len(consumed) > 0 && consumed[len(consumed)-1] == "--" {
+			positional = append(positional, remaining...)
+			return append(positional, args[end:]...), nil
+		}
+
+		if len(remaining) > 0 {
+			positional = append(positional, remaining[0])
+			args = append(append([]string{}, remaining[1:]...), args[end:]...)
+		} else if end < len(args) {
+			positional = append(positional, args[end])
+			args = args[end+1:]
+		} else {
+			args = nil
+		}
+	}
+	return positional, nil
+}
+
+// nextRelativeTime returns the index of the first argument that is a relative
+// time like -1h rather than a flag's value, or len(args) if there isn't one
+func nextRelativeTime(flags *flag.FlagSet, args []string) int {
+	for i, arg := range args {
+		if arg == "--" {
+			break
+		}
+		if len(arg) > 1 && arg[0] == '-' && arg[1] >= '0' && arg[1] <= '9' && (i == 0 || !takesValue(flags, args[i-1])) {
+			return i
+		}
+	}
+	return len(args)
+}
+
+// takesValue reports whether arg is a flag whose value is the next argument
+func takesValue(flags *flag.FlagSet, arg string) bool {
+	if !strings.HasPrefix(arg, "-") || strings.Contains(arg, "=") {
+		return false
+	}
+	f := flags.Lookup(strings.TrimLeft(arg, "-"))
+	if f == nil {
+		return false
+	}
+	if b, ok := f.Value.(interface{ IsBoolFlag() bool }); ok && b.IsBoolFlag() {
+		return false
+	}
+	return true
+}
+
+// outputFlag defines the -o/--output flag for choosing the result format
+func outputFlag(flags *flag.FlagSet) *string {
+	format := flags.String("output", "", "output format: "+strings.Join(output.Formats, ", ")+" (default: the profile's output format, or text)")
+	flags.StringVar(format, "o", "", "shorthand for --output")
+	return format
+}
+
+// fieldsFlag is a comma-separated list of fields, which may be given more than once
+type fieldsFlag []string
+
+func (f *fieldsFlag) String() string {
+	return strings.Join(*f, ",")
+}
+
+func (f *fieldsFlag) Set(value string) error {
+	for _, field := range strings.Split(value, ",") {
+		if field = strings.TrimSpace(field); field != "" {
+			*f = append(*f, field)
+		}
+	}
+	return nil
+}
+
+const fieldsUsage = "comma-separated fields to return, e.g. host,source,message, so Splunk doesn't send the others"
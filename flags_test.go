@@ -0,0 +1,67 @@
+package main
+
+import (
+	"flag"
+	"strings"
+	"testing"
+)
+
+func TestParseFlagsInterspersed(t *testing.T) {
+	tests := []struct {
+		args       []string
+		post       string
+		positional string
+	}{
+		{[]string{"123", "--post", "| stats count"}, "| stats count", "123"},
+		{[]string{"--post", "| stats count", "123"}, "| stats count", "123"},
+		{[]string{"123", "--", "--post"}, "", "123,--post"},
+	}
+
+	for _, tt := range tests {
+		flags := flag.NewFlagSet("test", flag.ContinueOnError)
+		post := flags.String("post", "", "")
+
+		positional, err := parseFlags(flags, tt.args)
+		if err != nil {
+			t.Fatalf("Expected no error, got: %v", err)
+		}
+		if *post != tt.post {
+			t.Errorf("%v: expected post %q, got: %q", tt.args, tt.post, *post)
+		}
+		if got := strings.Join(positional, ","); got != tt.positional {
+			t.Errorf("%v: expected positional %q, got: %q", tt.args, tt.positional, got)
+		}
+	}
+}
+
+func TestParseFlagsRelativeTimes(t *testing.T) {
+	flags := flag.NewFlagSet("test", flag.ContinueOnError)
+	format := outputFlag(flags)
+	earliest := flags.String("earliest", "", "")
+
+	positional, err := parseFlags(flags, []string{"error", "-1h", "now", "-o", "json", "--earliest", "-2d"})
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if got := strings.Join(positional, ","); got != "error,-1h,now" {
+		t.Errorf("Expected positional error,-1h,now, got: %q", got)
+	}
+	if *format != "json" {
+		t.Errorf("Expected json output, got: %q", *format)
+	}
+	if *earliest != "-2d" {
+		t.Errorf("Expected earliest -2d, got: %q", *earliest)
+	}
+}
+
+func TestFieldsFlag(t *testing.T) {
+	var fields fieldsFlag
+	for _, value := range []string{"host, source", "message,"} {
+		if err := fields.Set(value); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+	if got := fields.String(); got != "host,source,message" {
+		t.Errorf("Expected host,source,message, got %q", got)
+	}
+}
@@ -0,0 +1,176 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+
+	"github.com/kitproj/splunk-cli/internal/output"
+	"github.com/kitproj/splunk-cli/pkg/splunk"
+)
+
+// searchHook describes a search to the pre_search and post_search hooks of the selected profile
+type searchHook struct {
+	client   splunk.API
+	query    string
+	earliest string
+	latest   string
+}
+
+// before runs the pre_search hook, if any. A failing hook stops the search from running.
+func (h searchHook) before(ctx context.Context) error {
+	if settings.PreSearch == "" {
+		return nil
+	}
+	if err := h.run(ctx, settings.PreSearch, nil); err != nil {
+		return fmt.Errorf("pre_search hook failed: %w", err)
+	}
+	return nil
+}
+
+// after runs the post_search hook, if any, with the job's results written to a temporary JSON file
+func (h searchHook) after(ctx context.Context, sid string, results *splunk.SearchResult) error {
+	recorded, err := h.record()
+	if err != nil {
+		return err
+	}
+	defer recorded.remove()
+	for _, row := range results.Results {
+		if err := recorded.Write(row); err != nil {
+			return err
+		}
+	}
+	return h.afterRecorded(ctx, sid, recorded)
+}
+
+// afterRecorded runs the post_search hook, if any, with the results recorded for it
+func (h searchHook) afterRecorded(ctx context.Context, sid string, recorded *hookResults) error {
+	if recorded == nil {
+		return nil
+	}
+	_, err := recorded.f.WriteString("]\n")
+	if closeErr := recorded.f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write results file: %w", err)
+	}
+
+	err = h.run(ctx, settings.PostSearch, []string{
+		"SPLUNK_SID=" + sid,
+		"SPLUNK_RESULTS=" + recorded.f.Name(),
+		fmt.Sprintf("SPLUNK_RESULT_COUNT=%d", recorded.n),
+	})
+	if err != nil {
+		return fmt.Errorf("post_search hook failed: %w", err)
+	}
+	return nil
+}
+
+// hookResults writes results to a temporary JSON file for the post_search hook as they're got, so
+// results that are streamed rather than held in memory can be passed to it too. It's nil, and
+// ignores results, if there's no post_search hook.
+type hookResults struct {
+	f *os.File
+	n int
+}
+
+// record starts writing results for the post_search hook, if there is one
+func (h searchHook) record() (*hookResults, error) {
+	if settings.PostSearch == "" {
+		return nil, nil
+	}
+	f, err := os.CreateTemp("", "splunk-results-*.json")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create results file: %w", err)
+	}
+	if _, err := f.WriteString("["); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return nil, fmt.Errorf("failed to write results file: %w", err)
+	}
+	return &hookResults{f: f}, nil
+}
+
+func (r *hookResults) Write(row map[string]interface{}) error {
+	if r == nil {
+		return nil
+	}
+	data, err := json.Marshal(row)
+	if err != nil {
+		return fmt.Errorf("failed to marshal result: %w", err)
+	}
+	if r.n > 0 {
+		data = append([]byte(","), data...)
+	}
+	r.n++
+	if _, err := r.f.Write(data); err != nil {
+		return fmt.Errorf("failed to write results file: %w", err)
+	}
+	return nil
+}
+
+// hookWriter is a Writer that also records the rows written to it for the post_search hook, for
+// commands that write results as they arrive
+type hookWriter struct {
+	output.Writer
+	recorded *hookResults
+}
+
+func (w hookWriter) Write(row map[string]interface{}) error {
+	if err := w.recorded.Write(row); err != nil {
+		return err
+	}
+	return w.Writer.Write(row)
+}
+
+// jobHook describes an existing search job to the hooks. The job is only looked up if there's a
+// hook to describe it to.
+func jobHook(ctx context.Context, sid string) (searchHook, error) {
+	hook := searchHook{client: client}
+	if settings.PreSearch == "" && settings.PostSearch == "" {
+		return hook, nil
+	}
+	job, err := client.InspectJob(ctx, sid)
+	if err != nil {
+		return hook, fmt.Errorf("failed to get search job: %w", err)
+	}
+	hook.query, _ = job["search"].(string)
+	hook.earliest, _ = job["earliestTime"].(string)
+	hook.latest, _ = job["latestTime"].(string)
+	return hook, nil
+}
+
+// remove deletes the results file
+func (r *hookResults) remove() {
+	if r != nil {
+		r.f.Close()
+		os.Remove(r.f.Name())
+	}
+}
+
+// run runs a hook command with the shell, describing the search in its environment. The
+// hook's output goes to stderr, so it never mixes with results or the MCP protocol on stdout.
+func (h searchHook) run(ctx context.Context, command string, env []string) error {
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.CommandContext(ctx, "cmd", "/C", command)
+	} else {
+		cmd = exec.CommandContext(ctx, "sh", "-c", command)
+	}
+	cmd.Stdout = os.Stderr
+	cmd.Stderr = os.Stderr
+	// Only a real client has a server for the hook to call
+	c, _ := h.client.(splunk.Connection)
+	cmd.Env = append(os.Environ(), cliEnv(c)...)
+	cmd.Env = append(cmd.Env,
+		"SPLUNK_QUERY="+redact(h.query),
+		"SPLUNK_EARLIEST="+h.earliest,
+		"SPLUNK_LATEST="+h.latest,
+	)
+	cmd.Env = append(cmd.Env, env...)
+	return cmd.Run()
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"runtime"
+	"testing"
+
+	"github.com/kitproj/splunk-cli/internal/config"
+	"github.com/kitproj/splunk-cli/pkg/splunk"
+	"github.com/kitproj/splunk-cli/pkg/splunk/splunktest"
+)
+
+func TestSearchHooks(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the hooks use sh")
+	}
+	saved := settings
+	defer func() { settings = saved }()
+	settings = &config.Profile{
+		PreSearch:  `test "$SPLUNK_QUERY" = "search allowed"`,
+		PostSearch: `test "$SPLUNK_SID" = 123 && test "$SPLUNK_RESULT_COUNT" = 1 && grep -q '"status":"500"' "$SPLUNK_RESULTS"`,
+	}
+
+	if err := (searchHook{query: "search denied"}).before(context.Background()); err == nil {
+		t.Error("Expected a failing pre_search hook to stop the search")
+	}
+
+	hook := searchHook{query: "search allowed"}
+	if err := hook.before(context.Background()); err != nil {
+		t.Errorf("Expected no error, got: %v", err)
+	}
+	results := &splunk.SearchResult{Results: []map[string]interface{}{{"status": "500"}}}
+	if err := hook.after(context.Background(), "123", results); err != nil {
+		t.Errorf("Expected no error, got: %v", err)
+	}
+}
+
+func TestFollowHooks(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the hooks use sh")
+	}
+	savedSettings, savedClient := settings, client
+	defer func() { settings, client = savedSettings, savedClient }()
+	settings = &config.Profile{
+		PreSearch:  `test "$SPLUNK_QUERY" = "search index=web" && test "$SPLUNK_EARLIEST" = 2024-01-01T00:00:00Z`,
+		PostSearch: `test "$SPLUNK_SID" = 1 && test "$SPLUNK_RESULT_COUNT" = 2`,
+	}
+
+	// Following describes the existing job to the hooks, and records the results it prints
+	s := splunktest.NewServer(t)
+	s.HandleFunc("GET /services/search/jobs/1", func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, `{"entry":[{"name":"search index=web","content":{"earliestTime":"2024-01-01T00:00:00Z"}}],"content":{"isDone":true}}`)
+	})
+	s.Handle("GET /services/search/jobs/1/results_preview", http.StatusOK, `{"results":[{"n":"0"},{"n":"1"}]}`)
+	client = s.Client()
+	if err := runFollow(context.Background(), io.Discard, "1", "ndjson"); err != nil {
+		t.Errorf("Expected no error, got: %v", err)
+	}
+}
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"math"
+	"os"
+	"slices"
+	"time"
+
+	"github.com/kitproj/splunk-cli/pkg/splunk"
+)
+
+func indexCommand() *command {
+	var reportFormat *string
+	return &command{
+		name:    "index",
+		aliases: []string{"indexes"},
+		short:   "Report on indexes",
+		subcommands: []*command{
+			{
+				name:  "report",
+				args:  "[index...]",
+				short: "Report each index's storage and retention, e.g. for a capacity review",
+				long: "Report the storage each index uses on hot/warm and cold volumes, its oldest event and retention policy,\n" +
+					"and how many days until its oldest bucket is frozen, i.e. archived or deleted. Buckets are frozen once their\n" +
+					"newest event is older than the retention period, or, oldest first, once the index is bigger than its maximum\n" +
+					"size, which is projected from how fast the index has grown. frozen_by says which comes first.\n" +
+					"Bucket sizes come from the dbinspect command, so with indexer clustering they include every copy of each bucket.",
+				maxArgs: -1,
+				flags: func(flags *flag.FlagSet) {
+					reportFormat = outputFlag(flags)
+				},
+				run: func(ctx context.Context, args []string) error {
+					return executeCommand(ctx, func(ctx context.Context) error {
+						return runIndexReport(ctx, args, *reportFormat)
+					})
+				},
+			},
+		},
+	}
+}
+
+// runIndexReport prints the storage and retention of each index, or only the named ones
+func runIndexReport(ctx context.Context, names []string, format string) error {
+	writer, err := newWriter(os.Stdout, format)
+	if err != nil {
+		return err
+	}
+	indexes, err := client.ListIndexes(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list indexes: %w", err)
+	}
+	buckets, err := client.ListBucketStats(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to inspect buckets: %w", err)
+	}
+
+	now := time.Now()
+	for _, index := range indexes {
+		if len(names) > 0 && !slices.Contains(names, index.Name) {
+			continue
+		}
+		if err := writer.Write(indexReport(index, buckets, now)); err != nil {
+			return err
+		}
+	}
+	return writer.Close()
+}
+
+// indexReport combines an index's settings with the stats of its buckets into a row of the report
+func indexReport(index splunk.Index, buckets []splunk.BucketStats, now time.Time) map[string]interface{} {
+	var hot, warm, cold, thawed float64
+	var earliest, oldestEnd time.Time
+	for _, b := range buckets {
+		if b.Index != index.Name {
+			continue
+		}
+		switch b.State {
+		case "hot":
+			hot += b.SizeMB
+		case "warm":
+			warm += b.SizeMB
+		case "cold":
+			cold += b.SizeMB
+		default:
+			thawed += b.SizeMB
+		}
+		earliest = earlierTime(earliest, b.Earliest)
+		// Hot buckets roll to warm before they can be frozen, and thawed buckets are never frozen
+		if b.State == "warm" || b.State == "cold" {
+			oldestEnd = earlierTime(oldestEnd, b.OldestEnd)
+		}
+	}
+	total := hot + warm + cold + thawed
+
+	row := map[string]interface{}{
+		"index":             index.Name,
+		"hot_mb":            math.Round(hot),
+		"warm_mb":           math.Round(warm),
+		"cold_mb":           math.Round(cold),
+		"total_mb":          math.Round(total),
+		"max_mb":            index.MaxSizeMB,
+		"oldest_event":      "",
+		"retention_days":    index.RetentionSecs / 86400,
+		"days_until_frozen": "",
+		"frozen_by":         "",
+	}
+	if !earliest.IsZero() {
+		row["oldest_event"] = earliest.UTC().Format(time.DateOnly)
+	}
+
+	// Days until the oldest bucket is too old, and until the index is too big at the rate it has grown
+	days, by := math.Inf(1), ""
+	if !oldestEnd.IsZero() && index.RetentionSecs > 0 {
+		days, by = oldestEnd.Add(time.Duration(index.RetentionSecs)*time.Second).Sub(now).Hours()/24, "age"
+	}
+	if age := now.Sub(earliest).Hours() / 24; index.MaxSizeMB > 0 && total > 0 && age > 0 {
+		if full := (float64(index.MaxSizeMB) - total) / (total / age); full < days {
+			days, by = full, "size"
+		}
+	}
+	if by != "" {
+		row["days_until_frozen"] = int64(math.Max(days, 0))
+		row["frozen_by"] = by
+	}
+	return row
+}
+
+// earlierTime returns the earlier of two times, ignoring zero times
+func earlierTime(a, b time.Time) time.Time {
+	if a.IsZero() || (!b.IsZero() && b.Before(a)) {
+		return b
+	}
+	return a
+}
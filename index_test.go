@@ -0,0 +1,53 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kitproj/splunk-cli/pkg/splunk"
+)
+
+func TestIndexReport(t *testing.T) {
+	now := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	day := 24 * time.Hour
+	buckets := []splunk.BucketStats{
+		{Index: "main", State: "hot", Buckets: 3, SizeMB: 100, Earliest: now.Add(-2 * day)},
+		{Index: "main", State: "warm", Buckets: 10, SizeMB: 400, Earliest: now.Add(-10 * day), OldestEnd: now.Add(-9 * day)},
+		{Index: "main", State: "cold", Buckets: 20, SizeMB: 500, Earliest: now.Add(-100 * day), OldestEnd: now.Add(-95 * day)},
+		{Index: "web", State: "warm", Buckets: 5, SizeMB: 900, Earliest: now.Add(-10 * day), OldestEnd: now.Add(-9 * day)},
+	}
+	tests := []struct {
+		name  string
+		index splunk.Index
+		want  map[string]interface{}
+	}{
+		{
+			name:  "frozen by age",
+			index: splunk.Index{Name: "main", MaxSizeMB: 500000, RetentionSecs: 180 * 86400},
+			want: map[string]interface{}{
+				"hot_mb": 100.0, "warm_mb": 400.0, "cold_mb": 500.0, "total_mb": 1000.0, "oldest_event": "2024-02-22",
+				"retention_days": int64(180), "days_until_frozen": int64(85), "frozen_by": "age",
+			},
+		},
+		{
+			name:  "frozen by size",
+			index: splunk.Index{Name: "web", MaxSizeMB: 1000, RetentionSecs: 180 * 86400},
+			want:  map[string]interface{}{"total_mb": 900.0, "days_until_frozen": int64(1), "frozen_by": "size"},
+		},
+		{
+			name:  "empty",
+			index: splunk.Index{Name: "empty", MaxSizeMB: 1000, RetentionSecs: 180 * 86400},
+			want:  map[string]interface{}{"total_mb": 0.0, "oldest_event": "", "days_until_frozen": "", "frozen_by": ""},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			row := indexReport(tt.index, buckets, now)
+			for key, want := range tt.want {
+				if row[key] != want {
+					t.Errorf("Expected %s to be %v, got %v", key, want, row[key])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,150 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/kitproj/splunk-cli/internal/config"
+)
+
+// runInit walks through first-run setup: host, token and default time range,
+// verifying connectivity before saving and optionally registering the MCP server
+func runInit(ctx context.Context) error {
+	in := bufio.NewReader(os.Stdin)
+
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	p := cfg.Ensure(profileName())
+	// init sets up token auth; use "splunk configure --auth basic" for a username and password
+	p.Auth, p.Username = "", ""
+	settings = p
+
+	fmt.Fprintf(os.Stderr, "This will set up splunk-cli%s. Press Enter to accept the [default].\n\n", profileSuffix())
+
+	address, err := prompt(in, "Splunk host (with :port if the management port isn't 8089)", hostAddress(p))
+	if err != nil {
+		return err
+	}
+	if address == "" {
+		return fmt.Errorf("host is required")
+	}
+	if p.Host, p.Port, p.Scheme, err = config.ParseHost(address); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(os.Stderr, "\nCreate a token at https://%s:8000 under Settings > Tokens.\n", p.Host)
+	token, err := readSecret("API token")
+	if err != nil {
+		return err
+	}
+
+	if p.Earliest, err = prompt(in, "Default earliest time for searches", defaultString(p.Earliest, "-24h")); err != nil {
+		return err
+	}
+	if p.Latest, err = prompt(in, "Default latest time for searches", defaultString(p.Latest, "now")); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(os.Stderr, "\nVerifying connection to %s...\n", p.Host)
+	c, err := newClient(p.Host, token)
+	if err != nil {
+		return err
+	}
+	info, err := c.GetServerInfo(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to connect to Splunk, nothing was saved: %w", err)
+	}
+	fmt.Fprintf(os.Stderr, "Connected to %v (Splunk %v).\n", info["serverName"], info["version"])
+
+	if err := config.SaveConfig(cfg); err != nil {
+		return err
+	}
+	if err := config.SaveToken(profileName(), p.Host, token); err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "Configuration saved successfully for host: %s%s\n\n", p.Host, profileSuffix())
+
+	for _, mcpClient := range []struct{ name, label string }{
+		{"claude", "Claude Desktop"},
+		{"cursor", "Cursor"},
+	} {
+		yes, err := confirm(in, fmt.Sprintf("Register the MCP server with %s?", mcpClient.label))
+		if err != nil {
+			return err
+		}
+		if !yes {
+			continue
+		}
+		path, err := installMCPServer(mcpClient.name)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(os.Stderr, "Updated %s\n", path)
+	}
+
+	return nil
+}
+
+// prompt asks for a value on stderr and reads a line from in, returning def if the line is empty
+func prompt(in *bufio.Reader, label, def string) (string, error) {
+	if def != "" {
+		fmt.Fprintf(os.Stderr, "%s [%s]: ", label, def)
+	} else {
+		fmt.Fprintf(os.Stderr, "%s: ", label)
+	}
+
+	line, err := in.ReadString('\n')
+	if err != nil && (err != io.EOF || line == "") {
+		return "", fmt.Errorf("failed to read %s: %w", strings.ToLower(label), err)
+	}
+
+	if line = strings.TrimSpace(line); line == "" {
+		return def, nil
+	}
+	return line, nil
+}
+
+// confirm asks a yes/no question, defaulting to no
+func confirm(in *bufio.Reader, question string) (bool, error) {
+	answer, err := prompt(in, question+" (y/N)", "")
+	if err != nil {
+		return false, err
+	}
+	answer = strings.ToLower(answer)
+	return answer == "y" || answer == "yes", nil
+}
+
+// profileSuffix describes the selected profile for messages, e.g. " (profile prod)"
+func profileSuffix() string {
+	if name := profileName(); name != "" {
+		return fmt.Sprintf(" (profile %s)", name)
+	}
+	return ""
+}
+
+func defaultString(value, def string) string {
+	if value == "" {
+		return def
+	}
+	return value
+}
+
+// hostAddress returns the profile's host with its port and scheme, if they aren't the defaults
+func hostAddress(p *config.Profile) string {
+	address := p.Host
+	if p.Port != 0 {
+		address = net.JoinHostPort(p.Host, strconv.Itoa(p.Port))
+	}
+	if p.Scheme != "" && address != "" {
+		address = p.Scheme + "://" + address
+	}
+	return address
+}
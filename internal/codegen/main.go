@@ -0,0 +1,291 @@
+// Command codegen generates the typed client methods and CLI commands of the endpoints described
+// in pkg/splunk/endpoints.json. Run it with go generate from the repository root.
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"go/format"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+)
+
+// spec is the endpoints file
+type spec struct {
+	Endpoints []endpoint `json:"endpoints"`
+}
+
+// endpoint describes a collection of the REST API whose entries can be listed and fetched by name
+type endpoint struct {
+	// Type is the Go type of an entry, e.g. App
+	Type string `json:"type"`
+	// Plural is the plural of Type, used in method names, e.g. ListApps
+	Plural string `json:"plural"`
+	// Noun is the name of an entry in messages, e.g. app
+	Noun string `json:"noun"`
+	// Command is the name of the CLI command, e.g. apps
+	Command string `json:"command"`
+	// Summary describes the entries in doc comments and help, e.g. installed apps
+	Summary string `json:"summary"`
+	// Path is the path of the collection in a namespace, e.g. /apps/local
+	Path string `json:"path"`
+	// Columns are the fields listed by the list command, after the name
+	Columns []string `json:"columns"`
+	Fields  []field  `json:"fields"`
+}
+
+// field is a field of an entry's content
+type field struct {
+	// Name is the Go name of the field
+	Name string `json:"name"`
+	// JSON is Splunk's name of the field
+	JSON string `json:"json"`
+	// Type is one of string, bool, int or strings (a multivalue field)
+	Type string `json:"type"`
+	// Doc, if set, completes the doc comment "<Name> ..."
+	Doc string `json:"doc"`
+}
+
+var goTypes = map[string]string{"string": "string", "bool": "bool", "int": "Number", "strings": "[]string"}
+
+func main() {
+	dir := flag.String("dir", ".", "root directory of the repository")
+	flag.Parse()
+
+	if err := run(*dir); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// run generates the files from the endpoints file under dir
+func run(dir string) error {
+	files, err := generate(dir)
+	if err != nil {
+		return err
+	}
+	for path, src := range files {
+		if err := os.WriteFile(filepath.Join(dir, path), src, 0644); err != nil {
+			return fmt.Errorf("failed to write %s: %w", path, err)
+		}
+	}
+	return nil
+}
+
+// generate returns the generated files, keyed by their path under dir
+func generate(dir string) (map[string][]byte, error) {
+	s, err := load(filepath.Join(dir, "pkg", "splunk", "endpoints.json"))
+	if err != nil {
+		return nil, err
+	}
+	files := map[string][]byte{}
+	for path, tmpl := range map[string]*template.Template{
+		filepath.Join("pkg", "splunk", "endpoints_gen.go"): clientTemplate,
+		"endpoints_gen.go": commandsTemplate,
+	} {
+		var buf bytes.Buffer
+		if err := tmpl.Execute(&buf, s); err != nil {
+			return nil, fmt.Errorf("failed to generate %s: %w", path, err)
+		}
+		src, err := format.Source(buf.Bytes())
+		if err != nil {
+			return nil, fmt.Errorf("failed to format %s: %w", path, err)
+		}
+		files[path] = src
+	}
+	return files, nil
+}
+
+// load reads and checks the endpoints file
+func load(path string) (*spec, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read endpoints: %w", err)
+	}
+	var s spec
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	for _, e := range s.Endpoints {
+		if e.Type == "" || e.Plural == "" || e.Noun == "" || e.Command == "" || !strings.HasPrefix(e.Path, "/") {
+			return nil, fmt.Errorf("endpoint %q needs a type, plural, noun, command and path", e.Type)
+		}
+		fields := map[string]bool{}
+		for _, f := range e.Fields {
+			if _, ok := goTypes[f.Type]; !ok {
+				return nil, fmt.Errorf("field %s.%s has unknown type %q", e.Type, f.Name, f.Type)
+			}
+			fields[f.JSON] = true
+		}
+		for _, column := range e.Columns {
+			if !fields[column] {
+				return nil, fmt.Errorf("column %q of %s is not a field", column, e.Type)
+			}
+		}
+	}
+	return &s, nil
+}
+
+var funcs = template.FuncMap{
+	"goType": func(t string) string { return goTypes[t] },
+	// ident turns a command name into a Go identifier, e.g. saved-search into savedSearch
+	"ident": func(name string) string {
+		parts := strings.Split(name, "-")
+		for i := 1; i < len(parts); i++ {
+			parts[i] = strings.ToUpper(parts[i][:1]) + parts[i][1:]
+		}
+		return strings.Join(parts, "")
+	},
+	// value is the expression of a field's value in an output row
+	"value": func(f field) string {
+		switch f.Type {
+		case "int":
+			return "int64(item." + f.Name + ")"
+		case "strings":
+			return "multivalue(item." + f.Name + ")"
+		default:
+			return "item." + f.Name
+		}
+	},
+	"quote": func(s string) string { return fmt.Sprintf("%q", s) },
+}
+
+var clientTemplate = template.Must(template.New("client").Funcs(funcs).Parse(`// Code generated by go run ./internal/codegen; DO NOT EDIT.
+
+package splunk
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+)
+{{range .Endpoints}}
+// {{.Type}} is one of the {{.Summary}}, from {{.Path}}
+type {{.Type}} struct {
+	Name string ` + "`json:\"name\"`" + `
+{{- range .Fields}}
+{{- if .Doc}}
+	// {{.Name}} {{.Doc}}
+{{- end}}
+	{{.Name}} {{goType .Type}} ` + "`json:\"{{.JSON}}\"`" + `
+{{- end}}
+}
+
+// List{{.Plural}} lists the {{.Summary}}
+func (c *Client) List{{.Plural}}(ctx context.Context) ([]{{.Type}}, error) {
+	return get{{.Plural}}(ctx, c, c.Namespace.Path("{{.Path}}")+"?output_mode=json&count=0")
+}
+
+// Get{{.Type}} gets the {{.Noun}} with the given name
+func (c *Client) Get{{.Type}}(ctx context.Context, name string) (*{{.Type}}, error) {
+	items, err := get{{.Plural}}(ctx, c, c.Namespace.Path("{{.Path}}/"+url.PathEscape(name))+"?output_mode=json")
+	if err != nil {
+		return nil, err
+	}
+	if len(items) == 0 {
+		return nil, fmt.Errorf("{{.Noun}} %q not found", name)
+	}
+	return &items[0], nil
+}
+
+// get{{.Plural}} gets the {{.Summary}} in a feed of {{.Noun}} entries
+func get{{.Plural}}(ctx context.Context, c *Client, path string) ([]{{.Type}}, error) {
+	entries, err := getEntries[{{.Type}}](ctx, c, path)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]{{.Type}}, len(entries))
+	for i, entry := range entries {
+		items[i] = entry.Content
+		items[i].Name = entry.Name
+	}
+	return items, nil
+}
+{{end}}`))
+
+var commandsTemplate = template.Must(template.New("commands").Funcs(funcs).Parse(`// Code generated by go run ./internal/codegen; DO NOT EDIT.
+
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/kitproj/splunk-cli/pkg/splunk"
+)
+
+// generatedCommands are the commands of the endpoints in pkg/splunk/endpoints.json
+func generatedCommands() []*command {
+	return []*command{
+{{- range .Endpoints}}
+		{{ident .Command}}Command(),
+{{- end}}
+	}
+}
+{{range .Endpoints}}
+func {{ident .Command}}Command() *command {
+	var listFormat, getFormat *string
+	return &command{
+		name:  "{{.Command}}",
+		short: "List and show {{.Summary}}",
+		subcommands: []*command{
+			{
+				name:    "list",
+				aliases: []string{"ls"},
+				short:   "List the {{.Summary}}",
+				flags: func(flags *flag.FlagSet) {
+					listFormat = outputFlag(flags)
+				},
+				run: func(ctx context.Context, args []string) error {
+					return executeCommand(ctx, func(ctx context.Context) error {
+						items, err := client.List{{.Plural}}(ctx)
+						if err != nil {
+							return fmt.Errorf("failed to list {{.Summary}}: %w", err)
+						}
+						rows := make([]map[string]interface{}, len(items))
+						for i, item := range items {
+							rows[i] = selectColumns({{ident .Noun}}Fields(item){{range .Columns}}, {{quote .}}{{end}})
+						}
+						return writeAll(os.Stdout, *listFormat, rows)
+					})
+				},
+			},
+			{
+				name:    "get",
+				args:    "<name>",
+				short:   "Print every field of the {{.Noun}} <name>",
+				minArgs: 1,
+				maxArgs: 1,
+				flags: func(flags *flag.FlagSet) {
+					getFormat = outputFlag(flags)
+				},
+				run: func(ctx context.Context, args []string) error {
+					return executeCommand(ctx, func(ctx context.Context) error {
+						item, err := client.Get{{.Type}}(ctx, args[0])
+						if err != nil {
+							return fmt.Errorf("failed to get {{.Noun}}: %w", err)
+						}
+						return writeAll(os.Stdout, *getFormat, []map[string]interface{}{ {{- ident .Noun}}Fields(*item)})
+					})
+				},
+			},
+		},
+	}
+}
+
+// {{ident .Noun}}Fields returns the fields of the {{.Noun}} as an output row
+func {{ident .Noun}}Fields(item splunk.{{.Type}}) map[string]interface{} {
+	return map[string]interface{}{
+		"name": item.Name,
+{{- range .Fields}}
+		"{{.JSON}}": {{value .}},
+{{- end}}
+	}
+}
+{{end}}`))
@@ -0,0 +1,25 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestGeneratedFilesAreUpToDate(t *testing.T) {
+	dir := filepath.Join("..", "..")
+	files, err := generate(dir)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	for path, src := range files {
+		existing, err := os.ReadFile(filepath.Join(dir, path))
+		if err != nil {
+			t.Fatalf("Expected no error, got: %v", err)
+		}
+		if !bytes.Equal(existing, src) {
+			t.Errorf("%s is out of date, run go generate", path)
+		}
+	}
+}
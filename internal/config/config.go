@@ -3,8 +3,13 @@ package config
 import (
 	"encoding/json"
 	"fmt"
+	"net"
 	"os"
 	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/zalando/go-keyring"
 )
@@ -14,9 +19,213 @@ const (
 	configFile  = "config.json"
 )
 
-// config represents the splunk-cli configuration
-type config struct {
+// Config represents the splunk-cli configuration
+type Config struct {
+	// Profile is the default profile, used when no named profile is selected
+	Profile
+	// Profiles are named profiles for other Splunk environments, e.g. "dev" and "prod"
+	Profiles map[string]*Profile `json:"profiles,omitempty"`
+}
+
+// Profile is the connection settings and search defaults of a Splunk environment
+type Profile struct {
 	Host string `json:"host"`
+	// Port and Scheme are those of the management API, 8089 and https if unset
+	Port   int    `json:"port,omitempty"`
+	Scheme string `json:"scheme,omitempty"`
+	// Auth is how to authenticate: "token" (the default) or "basic", which logs in as Username
+	// with the password in the keyring for a session key
+	Auth     string `json:"auth,omitempty"`
+	Username string `json:"username,omitempty"`
+	// ClientCert and ClientKey are a client certificate and key for servers that require mutual TLS.
+	// ClientCert may instead be a PKCS#12 bundle (.p12 or .pfx) of both.
+	ClientCert string `json:"client_cert,omitempty"`
+	ClientKey  string `json:"client_key,omitempty"`
+	// CACert is a PEM bundle of CA certificates to trust as well as the system's, e.g. a corporate CA
+	CACert string `json:"ca_cert,omitempty"`
+	// Insecure skips verifying the server's certificate, e.g. for lab instances with self-signed certificates
+	Insecure bool `json:"insecure,omitempty"`
+	// Proxy is the URL of an HTTP, HTTPS or SOCKS5 proxy to connect through, e.g. socks5://localhost:1080,
+	// instead of those in the HTTP_PROXY, HTTPS_PROXY and NO_PROXY environment variables
+	Proxy string `json:"proxy,omitempty"`
+	// HECURL is the URL of the HTTP Event Collector, if it isn't on port 8088 of the host
+	HECURL string `json:"hec_url,omitempty"`
+	// WebURL is the URL of Splunk Web, if it isn't on port 8000 of the host, e.g. https://example.splunkcloud.com
+	WebURL string `json:"web_url,omitempty"`
+	// App and Owner are the namespace searches and saved searches are in, e.g. to use an app's knowledge objects.
+	// Either may be unset, for any app or owner.
+	App   string `json:"app,omitempty"`
+	Owner string `json:"owner,omitempty"`
+	// Earliest and Latest are the default time range for searches
+	Earliest string `json:"earliest,omitempty"`
+	Latest   string `json:"latest,omitempty"`
+	// PreSearch and PostSearch are shell commands run before and after each search,
+	// e.g. to audit queries or file tickets from results
+	PreSearch  string `json:"pre_search,omitempty"`
+	PostSearch string `json:"post_search,omitempty"`
+	// JobQuotaShare, if set, holds back new search jobs while the user's running jobs
+	// use more than this fraction of their concurrent search job quota, e.g. 0.5
+	JobQuotaShare float64 `json:"job_quota_share,omitempty"`
+	// TerminalRows is how many results may be printed to a terminal before asking first,
+	// 1000 if unset; -1 never asks
+	TerminalRows int `json:"terminal_rows,omitempty"`
+	// Retry is how API requests that fail transiently are retried
+	Retry *RetrySettings `json:"retry,omitempty"`
+	// Output is how commands print results unless their flags say otherwise
+	Output *OutputSettings `json:"output,omitempty"`
+	// Jira and ServiceNow are where 'splunk alerts export-ticket' files tickets
+	Jira       *JiraSettings       `json:"jira,omitempty"`
+	ServiceNow *ServiceNowSettings `json:"servicenow,omitempty"`
+}
+
+// RetrySettings is how API requests that fail transiently, e.g. with a 429 or 503, are retried.
+// Unset fields keep their defaults.
+type RetrySettings struct {
+	// MaxRetries is how many times a request is retried; -1 disables retries
+	MaxRetries int `json:"max_retries,omitempty"`
+	// Backoff is the delay before the first retry, which doubles up to MaxBackoff, e.g. "1s"
+	Backoff    string `json:"backoff,omitempty"`
+	MaxBackoff string `json:"max_backoff,omitempty"`
+	// Jitter is the fraction of each delay that is random, e.g. 0.5
+	Jitter float64 `json:"jitter,omitempty"`
+}
+
+// OutputSettings is how commands print results, e.g. json to pipe from a production profile and
+// boxed tables to read from a development one. Flags override each of them.
+type OutputSettings struct {
+	// Format is the output format of commands with an -o flag, text if unset
+	Format string `json:"format,omitempty"`
+	// Theme colors text and table output on a terminal: none (the default), dark or light
+	Theme string `json:"theme,omitempty"`
+	// TableStyle is the style of table output: plain (the default), boxed or markdown
+	TableStyle string `json:"table_style,omitempty"`
+	// MaxWidth, if set, is the most characters a table column may be wide
+	MaxWidth int `json:"max_width,omitempty"`
+}
+
+// JiraSettings is a Jira site to file tickets in. The API token is read from JIRA_API_TOKEN.
+type JiraSettings struct {
+	URL string `json:"url"`
+	// Username is the account's email address on Jira Cloud
+	Username  string `json:"username"`
+	Project   string `json:"project,omitempty"`
+	IssueType string `json:"issue_type,omitempty"`
+}
+
+// ServiceNowSettings is a ServiceNow instance to file incidents in. The password is read from SERVICENOW_PASSWORD.
+type ServiceNowSettings struct {
+	URL             string `json:"url"`
+	Username        string `json:"username"`
+	AssignmentGroup string `json:"assignment_group,omitempty"`
+}
+
+// BaseURL returns the URL of the management API on host. A port or scheme in host, such as
+// "splunk:8090" or "http://splunk", takes precedence over the profile's.
+func (p *Profile) BaseURL(host string) string {
+	scheme, port := p.Scheme, p.Port
+	if h, hostPort, hostScheme, err := ParseHost(host); err == nil {
+		host = h
+		if hostPort != 0 {
+			port = hostPort
+		}
+		if hostScheme != "" {
+			scheme = hostScheme
+		}
+	}
+	if scheme == "" {
+		scheme = "https"
+	}
+	if port == 0 {
+		port = 8089
+	}
+	return fmt.Sprintf("%s://%s", scheme, net.JoinHostPort(host, strconv.Itoa(port)))
+}
+
+// CollectorURL returns the URL of the HTTP Event Collector for host
+func (p *Profile) CollectorURL(host string) string {
+	if p.HECURL != "" {
+		return strings.TrimSuffix(p.HECURL, "/")
+	}
+	scheme := p.Scheme
+	if h, _, hostScheme, err := ParseHost(host); err == nil {
+		host = h
+		if hostScheme != "" {
+			scheme = hostScheme
+		}
+	}
+	if scheme == "" {
+		scheme = "https"
+	}
+	return fmt.Sprintf("%s://%s", scheme, net.JoinHostPort(host, "8088"))
+}
+
+// SplunkWebURL returns the URL of Splunk Web for host
+func (p *Profile) SplunkWebURL(host string) string {
+	if p.WebURL != "" {
+		return strings.TrimSuffix(p.WebURL, "/")
+	}
+	scheme := "https"
+	if h, _, hostScheme, err := ParseHost(host); err == nil {
+		host = h
+		if hostScheme != "" {
+			scheme = hostScheme
+		}
+	}
+	return fmt.Sprintf("%s://%s", scheme, net.JoinHostPort(host, "8000"))
+}
+
+// ParseHost splits a host that may include a port and scheme, such as "splunk", "splunk:8090" or
+// "http://splunk:8089", returning 0 and "" for a port or scheme that isn't given
+func ParseHost(s string) (host string, port int, scheme string, err error) {
+	if i := strings.Index(s, "://"); i >= 0 {
+		scheme, s = s[:i], s[i+3:]
+		if scheme != "http" && scheme != "https" {
+			return "", 0, "", fmt.Errorf("unknown scheme %q (must be http or https)", scheme)
+		}
+	}
+	s = strings.TrimSuffix(s, "/")
+	host = s
+	if h, p, splitErr := net.SplitHostPort(s); splitErr == nil {
+		host = h
+		if port, err = strconv.Atoi(p); err != nil || port <= 0 || port > 65535 {
+			return "", 0, "", fmt.Errorf("invalid port %q", p)
+		}
+	}
+	if host == "" || strings.ContainsAny(host, "/ ") {
+		return "", 0, "", fmt.Errorf("invalid host %q", s)
+	}
+	return host, port, scheme, nil
+}
+
+// Select returns the named profile, or the default profile if name is empty
+func (c *Config) Select(name string) (*Profile, error) {
+	if name == "" {
+		return &c.Profile, nil
+	}
+	if p, ok := c.Profiles[name]; ok && p != nil {
+		return p, nil
+	}
+	names := make([]string, 0, len(c.Profiles))
+	for n := range c.Profiles {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	if len(names) == 0 {
+		return nil, fmt.Errorf("unknown profile %q (no profiles are configured)", name)
+	}
+	return nil, fmt.Errorf("unknown profile %q (must be one of %s)", name, strings.Join(names, ", "))
+}
+
+// Ensure returns the named profile, or the default profile if name is empty, creating it if needed
+func (c *Config) Ensure(name string) *Profile {
+	if p, err := c.Select(name); err == nil {
+		return p
+	}
+	if c.Profiles == nil {
+		c.Profiles = map[string]*Profile{}
+	}
+	c.Profiles[name] = &Profile{}
+	return c.Profiles[name]
 }
 
 // getConfigPath returns the path to the config file
@@ -30,8 +239,18 @@ func getConfigPath() (string, error) {
 	return configPath, nil
 }
 
-// SaveConfig saves the host to the config file
-func SaveConfig(host string) error {
+// CacheDir returns the directory for cached API responses
+func CacheDir() (string, error) {
+	cacheDirPath, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get cache directory: %w", err)
+	}
+
+	return filepath.Join(cacheDirPath, "splunk-cli"), nil
+}
+
+// SaveConfig saves the config to the config file
+func SaveConfig(cfg *Config) error {
 	configPath, err := getConfigPath()
 	if err != nil {
 		return err
@@ -43,7 +262,6 @@ func SaveConfig(host string) error {
 		return fmt.Errorf("failed to create config directory: %w", err)
 	}
 
-	cfg := config{Host: host}
 	data, err := json.MarshalIndent(cfg, "", "  ")
 	if err != nil {
 		return fmt.Errorf("failed to marshal config: %w", err)
@@ -56,32 +274,106 @@ func SaveConfig(host string) error {
 	return nil
 }
 
-// LoadConfig loads the host from the config file
-func LoadConfig() (string, error) {
+// LoadConfig loads the config from the config file
+func LoadConfig() (*Config, error) {
 	configPath, err := getConfigPath()
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
 	data, err := os.ReadFile(configPath)
 	if err != nil {
-		return "", fmt.Errorf("failed to read config file: %w", err)
+		return nil, fmt.Errorf("failed to read config file: %w", err)
 	}
 
-	var cfg config
-	if err := json.Unmarshal(data, &cfg); err != nil {
-		return "", fmt.Errorf("failed to parse config file: %w", err)
+	var cfg Config
+	if err := validateConfig(configPath, data, &cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse config file: %w", err)
 	}
 
-	return cfg.Host, nil
+	return &cfg, nil
 }
 
-// SaveToken saves the token to the keyring
-func SaveToken(host, token string) error {
-	return keyring.Set(serviceName, host, token)
+// SaveToken saves the token (or password, with basic auth) of a profile's host to the keyring
+func SaveToken(profile, host, token string) error {
+	return keyring.Set(serviceName, keyringUser(profile, host), token)
 }
 
-// LoadToken loads the token from the keyring
-func LoadToken(host string) (string, error) {
-	return keyring.Get(serviceName, host)
+// LoadToken loads the token (or password, with basic auth) of a profile's host from the keyring
+func LoadToken(profile, host string) (string, error) {
+	return keyring.Get(serviceName, keyringUser(profile, host))
+}
+
+// TokenInfo is what's known about a saved token, so the CLI can remind users to rotate it before it expires
+type TokenInfo struct {
+	// ID identifies the token in Splunk, so it can be revoked
+	ID      string    `json:"id,omitempty"`
+	User    string    `json:"user,omitempty"`
+	Created time.Time `json:"created,omitempty"`
+	// Expires is when the token expires, or zero if it never does
+	Expires time.Time `json:"expires,omitempty"`
+}
+
+// SaveTokenInfo saves what's known about the token of a profile's host to the keyring, alongside it
+func SaveTokenInfo(profile, host string, info TokenInfo) error {
+	data, err := json.Marshal(info)
+	if err != nil {
+		return fmt.Errorf("failed to marshal token info: %w", err)
+	}
+	return keyring.Set(serviceName, keyringUser(profile, host)+"#meta", string(data))
+}
+
+// LoadTokenInfo loads what's known about the token of a profile's host from the keyring, if anything
+func LoadTokenInfo(profile, host string) (TokenInfo, bool) {
+	var info TokenInfo
+	data, err := keyring.Get(serviceName, keyringUser(profile, host)+"#meta")
+	if err != nil || json.Unmarshal([]byte(data), &info) != nil {
+		return TokenInfo{}, false
+	}
+	return info, true
+}
+
+// SaveSessionKey caches the session key of a profile's host in the keyring
+func SaveSessionKey(profile, host, key string) error {
+	return keyring.Set(serviceName, keyringUser(profile, host)+"#session", key)
+}
+
+// LoadSessionKey loads the cached session key of a profile's host from the keyring, if there is one
+func LoadSessionKey(profile, host string) string {
+	key, _ := keyring.Get(serviceName, keyringUser(profile, host)+"#session")
+	return key
+}
+
+// SaveHECToken saves the HTTP Event Collector token of a profile's host to the keyring
+func SaveHECToken(profile, host, token string) error {
+	return keyring.Set(serviceName, keyringUser(profile, host)+"#hec", token)
+}
+
+// LoadHECToken loads the HTTP Event Collector token of a profile's host from the keyring
+func LoadHECToken(profile, host string) (string, error) {
+	return keyring.Get(serviceName, keyringUser(profile, host)+"#hec")
+}
+
+// SaveParamSecret saves the value of a profile's secret search parameter to the keyring
+func SaveParamSecret(profile, name, value string) error {
+	return keyring.Set(serviceName, keyringUser(profile, "param:"+name), value)
+}
+
+// LoadParamSecret loads the value of a profile's secret search parameter from the keyring
+func LoadParamSecret(profile, name string) (string, error) {
+	return keyring.Get(serviceName, keyringUser(profile, "param:"+name))
+}
+
+// DeleteParamSecret deletes the value of a profile's secret search parameter from the keyring
+func DeleteParamSecret(profile, name string) error {
+	return keyring.Delete(serviceName, keyringUser(profile, "param:"+name))
+}
+
+// keyringUser returns the keyring entry of a profile's token. The default
+// profile's is just the host, as it was before profiles existed.
+func keyringUser(profile, host string) string {
+	if profile == "" {
+		return host
+	}
+	return profile + "/" + host
 }
@@ -0,0 +1,53 @@
+package config
+
+import "testing"
+
+func TestProfileBaseURL(t *testing.T) {
+	for _, tc := range []struct {
+		profile Profile
+		host    string
+		want    string
+	}{
+		{Profile{}, "splunk", "https://splunk:8089"},
+		{Profile{Port: 443, Scheme: "http"}, "splunk", "http://splunk:443"},
+		{Profile{Port: 443}, "splunk:8090", "https://splunk:8090"},
+		{Profile{}, "http://localhost", "http://localhost:8089"},
+		{Profile{}, "[::1]:8090", "https://[::1]:8090"},
+	} {
+		if got := tc.profile.BaseURL(tc.host); got != tc.want {
+			t.Errorf("BaseURL(%q) = %q, expected %q", tc.host, got, tc.want)
+		}
+	}
+}
+
+func TestParseHost(t *testing.T) {
+	host, port, scheme, err := ParseHost("http://splunk.example.com:8000/")
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if host != "splunk.example.com" || port != 8000 || scheme != "http" {
+		t.Errorf("Expected splunk.example.com, 8000 and http, got: %s, %d and %s", host, port, scheme)
+	}
+
+	for _, bad := range []string{"ftp://splunk", "splunk:http", "splunk:0", ":8089", "splunk/services"} {
+		if _, _, _, err := ParseHost(bad); err == nil {
+			t.Errorf("Expected an error for %q", bad)
+		}
+	}
+}
+
+func TestProfileSplunkWebURL(t *testing.T) {
+	for _, tc := range []struct {
+		profile Profile
+		host    string
+		want    string
+	}{
+		{Profile{Port: 8090}, "splunk", "https://splunk:8000"},
+		{Profile{}, "http://splunk:8089", "http://splunk:8000"},
+		{Profile{WebURL: "https://example.splunkcloud.com/"}, "splunk", "https://example.splunkcloud.com"},
+	} {
+		if got := tc.profile.SplunkWebURL(tc.host); got != tc.want {
+			t.Errorf("SplunkWebURL(%q) = %q, expected %q", tc.host, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,48 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// FileValue is a value read from a file, such as a mounted Kubernetes Secret,
+// that is re-read whenever the file changes
+type FileValue struct {
+	path string
+
+	mu      sync.Mutex
+	modTime time.Time
+	value   string
+}
+
+// NewFileValue creates a FileValue for the file at path
+func NewFileValue(path string) *FileValue {
+	return &FileValue{path: path}
+}
+
+// Get returns the trimmed contents of the file, re-reading it if it has been modified since the last read
+func (f *FileValue) Get() (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	// Stat follows symlinks, so the atomic symlink swap Kubernetes uses to
+	// update mounted secrets shows up as a new modification time
+	info, err := os.Stat(f.path)
+	if err != nil {
+		return "", fmt.Errorf("failed to stat %s: %w", f.path, err)
+	}
+
+	if !info.ModTime().Equal(f.modTime) || f.value == "" {
+		data, err := os.ReadFile(f.path)
+		if err != nil {
+			return "", fmt.Errorf("failed to read %s: %w", f.path, err)
+		}
+		f.value = strings.TrimSpace(string(data))
+		f.modTime = info.ModTime()
+	}
+
+	return f.value, nil
+}
@@ -0,0 +1,41 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestFileValueReloadsOnChange(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "token")
+	if err := os.WriteFile(path, []byte("first\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	v := NewFileValue(path)
+	got, err := v.Get()
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if got != "first" {
+		t.Errorf("Expected first, got: %q", got)
+	}
+
+	if err := os.WriteFile(path, []byte("second\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	// Make sure the modification time changes even on coarse-grained filesystems
+	later := time.Now().Add(time.Second)
+	if err := os.Chtimes(path, later, later); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err = v.Get()
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if got != "second" {
+		t.Errorf("Expected second, got: %q", got)
+	}
+}
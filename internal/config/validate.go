@@ -0,0 +1,169 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// validateConfig checks data against the schema of v, which must be a pointer
+// to a struct, and reports the file, line and key of the first problem found
+func validateConfig(path string, data []byte, v interface{}) error {
+	var raw interface{}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		var syntaxErr *json.SyntaxError
+		if errors.As(err, &syntaxErr) {
+			return fmt.Errorf("%s:%d: invalid JSON: %v", path, lineAt(data, syntaxErr.Offset), err)
+		}
+		return fmt.Errorf("%s: invalid JSON: %w", path, err)
+	}
+
+	if err := checkKeys(path, data, raw, reflect.TypeOf(v).Elem(), ""); err != nil {
+		return err
+	}
+
+	if err := json.Unmarshal(data, v); err != nil {
+		var typeErr *json.UnmarshalTypeError
+		if errors.As(err, &typeErr) {
+			return fmt.Errorf("%s:%d: %q must be %s, not %s", path, lineAt(data, typeErr.Offset), typeErr.Field, describeKind(typeErr.Type), typeErr.Value)
+		}
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	return nil
+}
+
+// checkKeys reports any key of raw that is not a field of the struct type t, recursing into nested objects
+func checkKeys(path string, data []byte, raw interface{}, t reflect.Type, prefix string) error {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		obj, ok := raw.(map[string]interface{})
+		if !ok {
+			return nil // reported as a type error when unmarshalling
+		}
+
+		fields := map[string]reflect.Type{}
+		jsonFields(t, fields)
+
+		for _, key := range sortedKeys(obj) {
+			fieldType, ok := fields[key]
+			if !ok {
+				msg := fmt.Sprintf("%s:%d: unknown key %q", path, lineOfKey(data, key), prefix+key)
+				if suggestion := closest(key, fields); suggestion != "" {
+					msg += fmt.Sprintf(" (did you mean %q?)", prefix+suggestion)
+				}
+				return errors.New(msg)
+			}
+			if err := checkKeys(path, data, obj[key], fieldType, prefix+key+"."); err != nil {
+				return err
+			}
+		}
+	case reflect.Map:
+		obj, ok := raw.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		for _, key := range sortedKeys(obj) {
+			if err := checkKeys(path, data, obj[key], t.Elem(), prefix+key+"."); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// jsonFields adds the JSON keys of the struct type t to fields, including those of embedded structs
+func jsonFields(t reflect.Type, fields map[string]reflect.Type) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "" && field.Anonymous && field.Type.Kind() == reflect.Struct {
+			jsonFields(field.Type, fields)
+		} else if name != "" && name != "-" {
+			fields[name] = field.Type
+		}
+	}
+}
+
+func sortedKeys(obj map[string]interface{}) []string {
+	keys := make([]string, 0, len(obj))
+	for key := range obj {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// closest returns the field name nearest to key, if it is near enough to be a likely typo
+func closest(key string, fields map[string]reflect.Type) string {
+	best, bestDistance := "", 3
+	for name := range fields {
+		if d := editDistance(strings.ToLower(key), name); d < bestDistance || (d == bestDistance && name < best) {
+			best, bestDistance = name, d
+		}
+	}
+	return best
+}
+
+// editDistance returns the Levenshtein distance between a and b
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur := make([]int, len(b)+1)
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev = cur
+	}
+	return prev[len(b)]
+}
+
+// lineAt returns the 1-based line number of a byte offset
+func lineAt(data []byte, offset int64) int {
+	if offset > int64(len(data)) {
+		offset = int64(len(data))
+	}
+	return bytes.Count(data[:offset], []byte("\n")) + 1
+}
+
+// lineOfKey returns the line number on which a key first appears
+func lineOfKey(data []byte, key string) int {
+	quoted, _ := json.Marshal(key)
+	i := bytes.Index(data, quoted)
+	if i < 0 {
+		return 1
+	}
+	return lineAt(data, int64(i))
+}
+
+func describeKind(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.String:
+		return "a string"
+	case reflect.Bool:
+		return "true or false"
+	case reflect.Int, reflect.Int64, reflect.Float64:
+		return "a number"
+	case reflect.Map, reflect.Struct:
+		return "an object"
+	case reflect.Slice:
+		return "a list"
+	default:
+		return t.String()
+	}
+}
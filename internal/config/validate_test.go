@@ -0,0 +1,37 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidateConfig(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want string
+	}{
+		{"valid", `{"host": "splunk.example.com"}`, ""},
+		{"unknown key", "{\n  \"hots\": \"splunk.example.com\"\n}", `config.json:2: unknown key "hots" (did you mean "host"?)`},
+		{"wrong type", "{\n  \"host\": 8089\n}", `config.json:2: "host" must be a string, not number`},
+		{"profile", `{"host": "a", "profiles": {"prod": {"host": "b", "port": 443}}}`, ""},
+		{"unknown profile key", "{\n  \"profiles\": {\"prod\": {\n    \"hots\": \"b\"\n  }}\n}", `config.json:3: unknown key "profiles.prod.hots" (did you mean "profiles.prod.host"?)`},
+		{"syntax error", "{\n  \"host\": \"a\",\n}", "config.json:3: invalid JSON"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var cfg Config
+			err := validateConfig("config.json", []byte(tt.data), &cfg)
+			if tt.want == "" {
+				if err != nil {
+					t.Fatalf("Expected no error, got: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.HasPrefix(err.Error(), tt.want) {
+				t.Errorf("Expected error starting with %q, got: %v", tt.want, err)
+			}
+		})
+	}
+}
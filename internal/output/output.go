@@ -0,0 +1,399 @@
+package output
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"unicode/utf8"
+)
+
+// Formats are the supported output formats
+var Formats = []string{"text", "json", "ndjson", "csv", "table"}
+
+// Themes are the supported color themes of text and table output
+var Themes = []string{"none", "dark", "light"}
+
+// TableStyles are the supported styles of tables
+var TableStyles = []string{"plain", "boxed", "markdown"}
+
+// themeColors are the ANSI colors of each theme's headings and field names
+var themeColors = map[string]struct{ heading, field string }{
+	"dark":  {"\x1b[1;36m", "\x1b[36m"},
+	"light": {"\x1b[1;34m", "\x1b[34m"},
+}
+
+const resetColor = "\x1b[0m"
+
+// Style is how text and table output looks. The zero Style is plain and uncolored.
+type Style struct {
+	// Theme colors the headings and field names of text output and the headers of tables
+	Theme string
+	// Table is the style of tables, plain if unset
+	Table string
+	// MaxWidth, if positive, is the most characters a table column may be wide; longer cells are cut short
+	MaxWidth int
+}
+
+// Check returns an error if the style's theme or table style is unknown
+func (s Style) Check() error {
+	if s.Theme != "" && !slices.Contains(Themes, s.Theme) {
+		return fmt.Errorf("unknown theme %q (must be one of %s)", s.Theme, strings.Join(Themes, ", "))
+	}
+	if s.Table != "" && !slices.Contains(TableStyles, s.Table) {
+		return fmt.Errorf("unknown table style %q (must be one of %s)", s.Table, strings.Join(TableStyles, ", "))
+	}
+	return nil
+}
+
+// color wraps text in the color, if there is one
+func color(text, color string) string {
+	if color == "" {
+		return text
+	}
+	return color + text + resetColor
+}
+
+// Writer writes rows in an output format
+type Writer interface {
+	// Write writes a single row
+	Write(row map[string]interface{}) error
+	// Close flushes any buffered rows and terminates the output
+	Close() error
+}
+
+// NewWriter creates a Writer for the named format
+func NewWriter(w io.Writer, format string) (Writer, error) {
+	return NewStyledWriter(w, format, Style{})
+}
+
+// NewStyledWriter creates a Writer for the named format, with text and tables in the style
+func NewStyledWriter(w io.Writer, format string, style Style) (Writer, error) {
+	if err := style.Check(); err != nil {
+		return nil, err
+	}
+	switch format {
+	case "", "text":
+		return &textWriter{w: w, colors: themeColors[style.Theme]}, nil
+	case "json":
+		return &jsonWriter{w: w}, nil
+	case "ndjson":
+		return &ndjsonWriter{enc: json.NewEncoder(w)}, nil
+	case "csv":
+		return &tabularWriter{w: w, render: renderCSV}, nil
+	case "table":
+		return &tabularWriter{w: w, render: func(w io.Writer, columns []string, rows []map[string]interface{}) error {
+			return renderTable(w, columns, rows, style)
+		}}, nil
+	default:
+		return nil, fmt.Errorf("unknown output format %q (must be one of %s)", format, strings.Join(Formats, ", "))
+	}
+}
+
+// WriteAll writes rows in the named format
+func WriteAll(w io.Writer, format string, rows []map[string]interface{}) error {
+	writer, err := NewWriter(w, format)
+	if err != nil {
+		return err
+	}
+	for _, row := range rows {
+		if err := writer.Write(row); err != nil {
+			return err
+		}
+	}
+	return writer.Close()
+}
+
+// textWriter writes each row as a numbered block of key/value pairs
+type textWriter struct {
+	w      io.Writer
+	n      int
+	colors struct{ heading, field string }
+}
+
+func (t *textWriter) Write(row map[string]interface{}) error {
+	t.n++
+	if _, err := fmt.Fprintln(t.w, color(fmt.Sprintf("Result %d:", t.n), t.colors.heading)); err != nil {
+		return err
+	}
+	for _, key := range SortFields(keys(row)) {
+		if _, err := fmt.Fprintf(t.w, "  %s: %v\n", color(key, t.colors.field), row[key]); err != nil {
+			return err
+		}
+	}
+	_, err := fmt.Fprintln(t.w)
+	return err
+}
+
+func (t *textWriter) Close() error { return nil }
+
+// jsonWriter streams rows as the elements of a JSON array
+type jsonWriter struct {
+	w io.Writer
+	n int
+}
+
+func (j *jsonWriter) Write(row map[string]interface{}) error {
+	data, err := json.Marshal(row)
+	if err != nil {
+		return fmt.Errorf("failed to marshal row: %w", err)
+	}
+	sep := ",\n  "
+	if j.n == 0 {
+		sep = "[\n  "
+	}
+	j.n++
+	_, err = fmt.Fprintf(j.w, "%s%s", sep, data)
+	return err
+}
+
+func (j *jsonWriter) Close() error {
+	if j.n == 0 {
+		_, err := fmt.Fprintln(j.w, "[]")
+		return err
+	}
+	_, err := fmt.Fprint(j.w, "\n]\n")
+	return err
+}
+
+// ndjsonWriter writes one JSON object per line
+type ndjsonWriter struct {
+	enc *json.Encoder
+}
+
+func (n *ndjsonWriter) Write(row map[string]interface{}) error {
+	return n.enc.Encode(row)
+}
+
+func (n *ndjsonWriter) Close() error { return nil }
+
+// tabularWriter buffers rows so every column is known before rendering
+type tabularWriter struct {
+	w      io.Writer
+	rows   []map[string]interface{}
+	render func(w io.Writer, columns []string, rows []map[string]interface{}) error
+}
+
+func (t *tabularWriter) Write(row map[string]interface{}) error {
+	t.rows = append(t.rows, row)
+	return nil
+}
+
+func (t *tabularWriter) Close() error {
+	seen := map[string]bool{}
+	var columns []string
+	for _, row := range t.rows {
+		for key := range row {
+			if !seen[key] {
+				seen[key] = true
+				columns = append(columns, key)
+			}
+		}
+	}
+	return t.render(t.w, SortFields(columns), t.rows)
+}
+
+func renderCSV(w io.Writer, columns []string, rows []map[string]interface{}) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(columns); err != nil {
+		return err
+	}
+	for _, row := range rows {
+		if err := cw.Write(cells(columns, row, "\n")); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+func renderTable(w io.Writer, columns []string, rows []map[string]interface{}, style Style) error {
+	header := make([]string, len(columns))
+	for i, column := range columns {
+		header[i] = truncate(strings.ToUpper(column), style.MaxWidth)
+	}
+	lines := make([][]string, len(rows))
+	for i, row := range rows {
+		// Cells must stay on one line to keep the columns aligned
+		values := cells(columns, row, ", ")
+		for j, value := range values {
+			values[j] = truncate(strings.ReplaceAll(value, "\n", " "), style.MaxWidth)
+		}
+		lines[i] = values
+	}
+	heading := themeColors[style.Theme].heading
+
+	switch style.Table {
+	case "boxed":
+		return renderBoxed(w, header, lines, heading)
+	case "markdown":
+		return renderMarkdown(w, header, lines, heading)
+	}
+	var buf bytes.Buffer
+	tw := tabwriter.NewWriter(&buf, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, strings.Join(header, "\t"))
+	for _, values := range lines {
+		fmt.Fprintln(tw, strings.Join(values, "\t"))
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	// The header is colored after the columns are aligned, as tabwriter counts escape codes as text
+	first, rest, _ := strings.Cut(buf.String(), "\n")
+	_, err := fmt.Fprintf(w, "%s\n%s", color(first, heading), rest)
+	return err
+}
+
+// renderBoxed renders a table with lines between its columns and around its header
+func renderBoxed(w io.Writer, header []string, lines [][]string, heading string) error {
+	widths := columnWidths(header, lines)
+	border := func(left, middle, right string) string {
+		parts := make([]string, len(widths))
+		for i, width := range widths {
+			parts[i] = strings.Repeat("─", width+2)
+		}
+		return left + strings.Join(parts, middle) + right + "\n"
+	}
+	row := func(values []string, c string) string {
+		parts := make([]string, len(values))
+		for i, value := range values {
+			parts[i] = " " + color(pad(value, widths[i]), c) + " "
+		}
+		return "│" + strings.Join(parts, "│") + "│\n"
+	}
+
+	var b strings.Builder
+	b.WriteString(border("┌", "┬", "┐"))
+	b.WriteString(row(header, heading))
+	b.WriteString(border("├", "┼", "┤"))
+	for _, values := range lines {
+		b.WriteString(row(values, ""))
+	}
+	b.WriteString(border("└", "┴", "┘"))
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// renderMarkdown renders a table as a GitHub-flavored Markdown table, e.g. to paste into an issue
+func renderMarkdown(w io.Writer, header []string, lines [][]string, heading string) error {
+	escape := strings.NewReplacer("|", `\|`)
+	header = escapeCells(escape, header)
+	for i, values := range lines {
+		lines[i] = escapeCells(escape, values)
+	}
+	widths := columnWidths(header, lines)
+	row := func(values []string, c string) string {
+		parts := make([]string, len(values))
+		for i, value := range values {
+			parts[i] = color(pad(value, widths[i]), c)
+		}
+		return "| " + strings.Join(parts, " | ") + " |\n"
+	}
+
+	var b strings.Builder
+	b.WriteString(row(header, heading))
+	rules := make([]string, len(widths))
+	for i, width := range widths {
+		rules[i] = strings.Repeat("-", max(width, 3))
+	}
+	b.WriteString("| " + strings.Join(rules, " | ") + " |\n")
+	for _, values := range lines {
+		b.WriteString(row(values, ""))
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// columnWidths returns the widest cell of each column, in characters
+func columnWidths(header []string, lines [][]string) []int {
+	widths := make([]int, len(header))
+	for _, values := range append([][]string{header}, lines...) {
+		for i, value := range values {
+			widths[i] = max(widths[i], utf8.RuneCountInString(value))
+		}
+	}
+	return widths
+}
+
+func escapeCells(escape *strings.Replacer, values []string) []string {
+	escaped := make([]string, len(values))
+	for i, value := range values {
+		escaped[i] = escape.Replace(value)
+	}
+	return escaped
+}
+
+// pad pads value with spaces to width characters
+func pad(value string, width int) string {
+	return value + strings.Repeat(" ", width-utf8.RuneCountInString(value))
+}
+
+// truncate cuts value short to width characters, ending it with an ellipsis, if width is positive
+func truncate(value string, width int) string {
+	if width <= 0 || utf8.RuneCountInString(value) <= width {
+		return value
+	}
+	return string([]rune(value)[:width-1]) + "…"
+}
+
+// cells returns a row's values in column order, joining multivalue fields with sep
+func cells(columns []string, row map[string]interface{}, sep string) []string {
+	values := make([]string, len(columns))
+	for i, column := range columns {
+		values[i] = FormatValue(row[column], sep)
+	}
+	return values
+}
+
+// FormatValue formats a field value, joining multivalue fields with sep
+func FormatValue(value interface{}, sep string) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case []interface{}:
+		parts := make([]string, len(v))
+		for i, part := range v {
+			parts[i] = FormatValue(part, sep)
+		}
+		return strings.Join(parts, sep)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+func keys(row map[string]interface{}) []string {
+	names := make([]string, 0, len(row))
+	for key := range row {
+		names = append(names, key)
+	}
+	return names
+}
+
+// SortFields sorts field names for display: _time first, then regular fields
+// alphabetically, then the remaining internal (underscore) fields
+func SortFields(fields []string) []string {
+	rank := func(field string) int {
+		switch {
+		case field == "_time":
+			return 0
+		case !strings.HasPrefix(field, "_"):
+			return 1
+		default:
+			return 2
+		}
+	}
+	sort.Slice(fields, func(i, j int) bool {
+		if ri, rj := rank(fields[i]), rank(fields[j]); ri != rj {
+			return ri < rj
+		}
+		return fields[i] < fields[j]
+	})
+	return fields
+}
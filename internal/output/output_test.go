@@ -0,0 +1,80 @@
+package output
+
+import (
+	"bytes"
+	"testing"
+)
+
+var rows = []map[string]interface{}{
+	{"_time": "2024-01-01T00:00:00", "status": "500", "host": "web-1"},
+	{"_time": "2024-01-01T00:01:00", "status": "200", "host": []interface{}{"web-2", "web-3"}},
+}
+
+func TestWriteAll(t *testing.T) {
+	tests := []struct {
+		format string
+		want   string
+	}{
+		{"csv", "_time,host,status\n2024-01-01T00:00:00,web-1,500\n2024-01-01T00:01:00,\"web-2\nweb-3\",200\n"},
+		{"ndjson", "{\"_time\":\"2024-01-01T00:00:00\",\"host\":\"web-1\",\"status\":\"500\"}\n{\"_time\":\"2024-01-01T00:01:00\",\"host\":[\"web-2\",\"web-3\"],\"status\":\"200\"}\n"},
+		{"json", "[\n  {\"_time\":\"2024-01-01T00:00:00\",\"host\":\"web-1\",\"status\":\"500\"},\n  {\"_time\":\"2024-01-01T00:01:00\",\"host\":[\"web-2\",\"web-3\"],\"status\":\"200\"}\n]\n"},
+		{"table", "_TIME                HOST          STATUS\n2024-01-01T00:00:00  web-1         500\n2024-01-01T00:01:00  web-2, web-3  200\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := WriteAll(&buf, tt.format, rows); err != nil {
+				t.Fatalf("Expected no error, got: %v", err)
+			}
+			if buf.String() != tt.want {
+				t.Errorf("Expected:\n%s\ngot:\n%s", tt.want, buf.String())
+			}
+		})
+	}
+}
+
+func TestNewWriterUnknownFormat(t *testing.T) {
+	if _, err := NewWriter(&bytes.Buffer{}, "xml"); err == nil {
+		t.Error("Expected error for unknown format")
+	}
+}
+
+func TestStyledTable(t *testing.T) {
+	tests := []struct {
+		name  string
+		style Style
+		want  string
+	}{
+		{"boxed", Style{Table: "boxed"}, "┌─────────────────────┬──────────────┬────────┐\n│ _TIME               │ HOST         │ STATUS │\n├─────────────────────┼──────────────┼────────┤\n│ 2024-01-01T00:00:00 │ web-1        │ 500    │\n│ 2024-01-01T00:01:00 │ web-2, web-3 │ 200    │\n└─────────────────────┴──────────────┴────────┘\n"},
+		{"markdown", Style{Table: "markdown"}, "| _TIME               | HOST         | STATUS |\n| ------------------- | ------------ | ------ |\n| 2024-01-01T00:00:00 | web-1        | 500    |\n| 2024-01-01T00:01:00 | web-2, web-3 | 200    |\n"},
+		{"max width", Style{MaxWidth: 6}, "_TIME   HOST    STATUS\n2024-…  web-1   500\n2024-…  web-2…  200\n"},
+		{"theme", Style{Theme: "dark"}, "\x1b[1;36m_TIME                HOST          STATUS\x1b[0m\n2024-01-01T00:00:00  web-1         500\n2024-01-01T00:01:00  web-2, web-3  200\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			writer, err := NewStyledWriter(&buf, "table", tt.style)
+			if err != nil {
+				t.Fatalf("Expected no error, got: %v", err)
+			}
+			for _, row := range rows {
+				writer.Write(row)
+			}
+			if err := writer.Close(); err != nil {
+				t.Fatalf("Expected no error, got: %v", err)
+			}
+			if buf.String() != tt.want {
+				t.Errorf("Expected:\n%s\ngot:\n%s", tt.want, buf.String())
+			}
+		})
+	}
+
+	if _, err := NewStyledWriter(&bytes.Buffer{}, "table", Style{Table: "fancy"}); err == nil {
+		t.Error("Expected error for unknown table style")
+	}
+	if _, err := NewStyledWriter(&bytes.Buffer{}, "text", Style{Theme: "neon"}); err == nil {
+		t.Error("Expected error for unknown theme")
+	}
+}
@@ -0,0 +1,152 @@
+// Package ticket files tickets in issue trackers and ITSM tools, such as Jira and ServiceNow
+package ticket
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Ticket is the tracker-independent content of a ticket
+type Ticket struct {
+	Summary string
+	// Description is plain text, and Details is preformatted text such as a table of results
+	Description string
+	Details     string
+	Labels      []string
+}
+
+// Created identifies a ticket that was filed
+type Created struct {
+	Key string
+	URL string
+}
+
+// Tracker files tickets
+type Tracker interface {
+	Create(ctx context.Context, t Ticket) (*Created, error)
+}
+
+// Jira files issues in a Jira project with the REST API, authenticating with a username
+// (the account's email address on Jira Cloud) and an API token
+type Jira struct {
+	URL        string
+	Username   string
+	Token      string
+	Project    string
+	IssueType  string
+	HTTPClient *http.Client
+}
+
+// Create creates an issue
+func (j *Jira) Create(ctx context.Context, t Ticket) (*Created, error) {
+	description := t.Description
+	if t.Details != "" {
+		description += "\n\n{noformat}\n" + t.Details + "\n{noformat}"
+	}
+	issueType := j.IssueType
+	if issueType == "" {
+		issueType = "Task"
+	}
+	fields := map[string]interface{}{
+		"project":     map[string]string{"key": j.Project},
+		"summary":     t.Summary,
+		"description": description,
+		"issuetype":   map[string]string{"name": issueType},
+	}
+	if len(t.Labels) > 0 {
+		fields["labels"] = t.Labels
+	}
+
+	var result struct {
+		Key string `json:"key"`
+	}
+	base := strings.TrimSuffix(j.URL, "/")
+	if err := post(ctx, j.HTTPClient, base+"/rest/api/2/issue", j.Username, j.Token, map[string]interface{}{"fields": fields}, &result); err != nil {
+		return nil, fmt.Errorf("failed to create Jira issue: %w", err)
+	}
+	return &Created{Key: result.Key, URL: base + "/browse/" + url.PathEscape(result.Key)}, nil
+}
+
+// ServiceNow files records, incidents by default, with the Table API
+type ServiceNow struct {
+	URL      string
+	Username string
+	Password string
+	// Table is the table to create records in, "incident" if empty
+	Table string
+	// AssignmentGroup, if set, is the group the record is assigned to
+	AssignmentGroup string
+	HTTPClient      *http.Client
+}
+
+// Create creates a record
+func (s *ServiceNow) Create(ctx context.Context, t Ticket) (*Created, error) {
+	table := s.Table
+	if table == "" {
+		table = "incident"
+	}
+	description := t.Description
+	if t.Details != "" {
+		description += "\n\n" + t.Details
+	}
+	record := map[string]interface{}{
+		"short_description": t.Summary,
+		"description":       description,
+	}
+	if s.AssignmentGroup != "" {
+		record["assignment_group"] = s.AssignmentGroup
+	}
+
+	var result struct {
+		Result struct {
+			Number string `json:"number"`
+			SysID  string `json:"sys_id"`
+		} `json:"result"`
+	}
+	base := strings.TrimSuffix(s.URL, "/")
+	if err := post(ctx, s.HTTPClient, base+"/api/now/table/"+url.PathEscape(table), s.Username, s.Password, record, &result); err != nil {
+		return nil, fmt.Errorf("failed to create ServiceNow %s: %w", table, err)
+	}
+	link := fmt.Sprintf("%s/nav_to.do?uri=%s", base, url.QueryEscape(table+".do?sys_id="+result.Result.SysID))
+	return &Created{Key: result.Result.Number, URL: link}, nil
+}
+
+// post sends body as JSON with basic auth and decodes the JSON response into result
+func post(ctx context.Context, client *http.Client, endpoint, username, password string, body, result interface{}) error {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, "POST", endpoint, bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.SetBasicAuth(username, password)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+
+	if client == nil {
+		client = &http.Client{Timeout: 30 * time.Second}
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to execute request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("request failed with status %d: %s", resp.StatusCode, string(body))
+	}
+	if err := json.NewDecoder(resp.Body).Decode(result); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	return nil
+}
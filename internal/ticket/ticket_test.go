@@ -0,0 +1,69 @@
+package ticket
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestJiraCreate(t *testing.T) {
+	var fields map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/rest/api/2/issue" {
+			t.Errorf("Unexpected path: %s", r.URL.Path)
+		}
+		if user, token, _ := r.BasicAuth(); user != "me@example.com" || token != "secret" {
+			t.Errorf("Unexpected credentials: %s:%s", user, token)
+		}
+		var body struct {
+			Fields map[string]interface{} `json:"fields"`
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+		fields = body.Fields
+		w.Write([]byte(`{"id":"10001","key":"OPS-42"}`))
+	}))
+	defer server.Close()
+
+	j := &Jira{URL: server.URL + "/", Username: "me@example.com", Token: "secret", Project: "OPS"}
+	created, err := j.Create(context.Background(), Ticket{Summary: "Alert", Description: "It fired", Details: "STATUS\n500", Labels: []string{"splunk"}})
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if created.Key != "OPS-42" || created.URL != server.URL+"/browse/OPS-42" {
+		t.Errorf("Unexpected ticket: %+v", created)
+	}
+	if fields["project"].(map[string]interface{})["key"] != "OPS" || fields["issuetype"].(map[string]interface{})["name"] != "Task" {
+		t.Errorf("Expected a task in OPS, got: %v", fields)
+	}
+	if description := fields["description"].(string); !strings.Contains(description, "{noformat}\nSTATUS\n500\n{noformat}") {
+		t.Errorf("Expected the details to be preformatted, got: %s", description)
+	}
+}
+
+func TestServiceNowCreate(t *testing.T) {
+	var record map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/now/table/incident" {
+			t.Errorf("Unexpected path: %s", r.URL.Path)
+		}
+		json.NewDecoder(r.Body).Decode(&record)
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"result":{"number":"INC0010001","sys_id":"abc"}}`))
+	}))
+	defer server.Close()
+
+	s := &ServiceNow{URL: server.URL, Username: "svc", Password: "secret", AssignmentGroup: "Ops"}
+	created, err := s.Create(context.Background(), Ticket{Summary: "Alert", Description: "It fired"})
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if created.Key != "INC0010001" || !strings.Contains(created.URL, "sys_id%3Dabc") {
+		t.Errorf("Unexpected ticket: %+v", created)
+	}
+	if record["short_description"] != "Alert" || record["assignment_group"] != "Ops" {
+		t.Errorf("Unexpected record: %v", record)
+	}
+}
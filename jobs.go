@@ -0,0 +1,182 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+)
+
+func jobsCommand() *command {
+	var listCount *int
+	var listFormat, inspectFormat, cloneFormat *string
+	var clone searchArgs
+	return &command{
+		name:    "jobs",
+		aliases: []string{"job"},
+		short:   "List, inspect and manage search jobs",
+		subcommands: []*command{
+			{
+				name:    "list",
+				aliases: []string{"ls"},
+				short:   "List search jobs, most recent first",
+				flags: func(flags *flag.FlagSet) {
+					listCount = flags.Int("count", 50, "maximum number of jobs to list (0 for all)")
+					listFormat = outputFlag(flags)
+				},
+				run: func(ctx context.Context, args []string) error {
+					return executeCommand(ctx, func(ctx context.Context) error {
+						return runJobsList(ctx, *listCount, *listFormat)
+					})
+				},
+			},
+			{
+				name:    "inspect",
+				args:    "<sid>",
+				short:   "Print every property of a search job",
+				minArgs: 1,
+				maxArgs: 1,
+				flags: func(flags *flag.FlagSet) {
+					inspectFormat = outputFlag(flags)
+				},
+				run: func(ctx context.Context, args []string) error {
+					return executeCommand(ctx, func(ctx context.Context) error {
+						job, err := client.InspectJob(ctx, args[0])
+						if err != nil {
+							return fmt.Errorf("failed to inspect search job: %w", err)
+						}
+						return writeAll(os.Stdout, *inspectFormat, []map[string]interface{}{job})
+					})
+				},
+			},
+			{
+				name:  "clone",
+				args:  "<sid>",
+				short: "Re-run a search job's search, e.g. over a new time range",
+				long: "Re-run a search job's search in the same app, printing its results like 'splunk search'.\n" +
+					"It runs over the job's original time range unless --earliest or --latest say otherwise,\n" +
+					"e.g. 'splunk jobs clone <sid> --earliest -2d --latest -1d' re-runs yesterday's investigation over the day before.",
+				minArgs: 1,
+				maxArgs: 1,
+				flags: func(flags *flag.FlagSet) {
+					flags.StringVar(&clone.earliestTime, "earliest", "", "earliest time of the new search (default: the job's)")
+					flags.StringVar(&clone.latestTime, "latest", "", "latest time of the new search (default: the job's)")
+					flags.IntVar(&clone.count, "count", 100, "maximum number of results to print")
+					flags.BoolVar(&clone.all, "all", false, "print every result, fetching them in pages")
+					flags.StringVar(&clone.out, "out", "", "file to write results to (default: stdout)")
+					flags.BoolVar(&clone.yes, "yes", false, "print the results even if there are more than terminal_rows for a terminal, without asking")
+					cloneFormat = outputFlag(flags)
+				},
+				run: func(ctx context.Context, args []string) error {
+					return executeCommand(ctx, func(ctx context.Context) error {
+						job, err := client.InspectJob(ctx, args[0])
+						if err != nil {
+							return fmt.Errorf("failed to inspect search job: %w", err)
+						}
+						search, err := cloneSearch(job, clone)
+						if err != nil {
+							return err
+						}
+						search.format = *cloneFormat
+						return runSearch(ctx, search)
+					})
+				},
+			},
+			{
+				name:    "cancel",
+				args:    "<sid>",
+				short:   "Cancel a search job and delete its results",
+				minArgs: 1,
+				maxArgs: 1,
+				run: func(ctx context.Context, args []string) error {
+					return executeCommand(ctx, func(ctx context.Context) error {
+						if err := client.CancelSearch(ctx, args[0]); err != nil {
+							return fmt.Errorf("failed to cancel search job: %w", err)
+						}
+						slog.Info("Cancelled search job", "sid", args[0])
+						return nil
+					})
+				},
+			},
+			{
+				name:    "finalize",
+				args:    "<sid>",
+				short:   "Stop a search job, keeping the results it has so far",
+				minArgs: 1,
+				maxArgs: 1,
+				run: func(ctx context.Context, args []string) error {
+					return executeCommand(ctx, func(ctx context.Context) error {
+						if err := client.FinalizeSearch(ctx, args[0]); err != nil {
+							return fmt.Errorf("failed to finalize search job: %w", err)
+						}
+						slog.Info("Finalized search job", "sid", args[0])
+						return nil
+					})
+				},
+			},
+		},
+	}
+}
+
+// runJobsList prints a summary of each search job
+func runJobsList(ctx context.Context, count int, format string) error {
+	writer, err := newWriter(os.Stdout, format)
+	if err != nil {
+		return err
+	}
+
+	jobs, err := client.ListJobs(ctx, count)
+	if err != nil {
+		return fmt.Errorf("failed to list search jobs: %w", err)
+	}
+
+	for _, job := range jobs {
+		err := writer.Write(map[string]interface{}{
+			"sid":      job.SID,
+			"owner":    job.Owner,
+			"state":    job.DispatchState,
+			"progress": fmt.Sprintf("%.0f%%", job.DoneProgress*100),
+			"events":   job.EventCount,
+			"results":  job.ResultCount,
+			"runtime":  fmt.Sprintf("%.1fs", job.RunDuration),
+			"search":   job.Search,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return writer.Close()
+}
+
+// cloneSearch returns the search a job ran, in its app, over the time range in args, or the job's
+// own if args has none. The job's range is the one it was dispatched with, so a relative range
+// such as -24h is relative to now again.
+func cloneSearch(job map[string]interface{}, args searchArgs) (searchArgs, error) {
+	args.query, _ = job["search"].(string)
+	if args.query == "" {
+		return args, fmt.Errorf("search job %v has no search to clone", job["sid"])
+	}
+	request, _ := job["request"].(map[string]interface{})
+	if args.earliestTime == "" {
+		args.earliestTime = jobTime(request["earliest_time"], job["earliestTime"])
+	}
+	if args.latestTime == "" {
+		args.latestTime = jobTime(request["latest_time"], job["latestTime"])
+	}
+	if acl, ok := job["eai:acl"].(map[string]interface{}); ok {
+		args.app, _ = acl["app"].(string)
+	}
+	args.level, _ = request["adhoc_search_level"].(string)
+	return args, nil
+}
+
+// jobTime returns the first of a job's times that is set
+func jobTime(times ...interface{}) string {
+	for _, t := range times {
+		if s, ok := t.(string); ok && s != "" {
+			return s
+		}
+	}
+	return ""
+}
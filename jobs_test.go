@@ -0,0 +1,35 @@
+package main
+
+import "testing"
+
+func TestCloneSearch(t *testing.T) {
+	job := map[string]interface{}{
+		"sid":          "1700000000.123",
+		"search":       "search index=main error",
+		"earliestTime": "2024-01-01T00:00:00.000+00:00",
+		"latestTime":   "2024-01-02T00:00:00.000+00:00",
+		"request":      map[string]interface{}{"earliest_time": "-24h", "adhoc_search_level": "verbose"},
+		"eai:acl":      map[string]interface{}{"app": "security"},
+	}
+
+	// The job's requested time range is preferred, and its resolved time is the fallback
+	search, err := cloneSearch(job, searchArgs{count: 100})
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if search.query != "search index=main error" || search.earliestTime != "-24h" || search.latestTime != "2024-01-02T00:00:00.000+00:00" || search.app != "security" || search.level != "verbose" || search.count != 100 {
+		t.Errorf("Unexpected search: %+v", search)
+	}
+
+	search, err = cloneSearch(job, searchArgs{earliestTime: "-2d", latestTime: "-1d"})
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if search.earliestTime != "-2d" || search.latestTime != "-1d" {
+		t.Errorf("Expected the new time range, got: %+v", search)
+	}
+
+	if _, err := cloneSearch(map[string]interface{}{"sid": "123"}, searchArgs{}); err == nil {
+		t.Error("Expected an error for a job without a search")
+	}
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+
+	"golang.org/x/term"
+)
+
+// defaultTerminalRows is how many results may be printed to a terminal without asking first
+const defaultTerminalRows = 1000
+
+// isTerminal reports whether f is a terminal; tests replace it
+var isTerminal = func(f *os.File) bool {
+	return term.IsTerminal(int(f.Fd()))
+}
+
+// confirmOutput asks before printing more results to a terminal than the profile's terminal_rows,
+// so an unexpectedly large search doesn't scroll 100,000 events past. Without a terminal to ask on,
+// it fails unless yes is set.
+func confirmOutput(rows int, yes bool) error {
+	limit := defaultTerminalRows
+	if settings.TerminalRows != 0 {
+		limit = settings.TerminalRows
+	}
+	if yes || limit < 0 || rows <= limit || !isTerminal(os.Stdout) {
+		return nil
+	}
+
+	refused := withExitCode(exitUsage, fmt.Errorf("not printing %d results to the terminal: use --count to print fewer, --out to write them to a file, or --yes to print them anyway", rows))
+	if !isTerminal(os.Stdin) {
+		return refused
+	}
+	ok, err := confirm(bufio.NewReader(os.Stdin), fmt.Sprintf("Print %d results to the terminal? Use --out to write them to a file instead.", rows))
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return refused
+	}
+	return nil
+}
+
+// openOutput opens the file results are written to, or stdout if out is empty
+func openOutput(out string) (io.WriteCloser, error) {
+	if out == "" {
+		return nopCloser{os.Stdout}, nil
+	}
+	f, err := os.Create(out)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create output file: %w", err)
+	}
+	return f, nil
+}
+
+type nopCloser struct {
+	io.Writer
+}
+
+func (nopCloser) Close() error {
+	return nil
+}
@@ -0,0 +1,38 @@
+package main
+
+import (
+	"os"
+	"testing"
+
+	"github.com/kitproj/splunk-cli/internal/config"
+)
+
+func TestConfirmOutput(t *testing.T) {
+	saved, savedIsTerminal := settings, isTerminal
+	defer func() { settings, isTerminal = saved, savedIsTerminal }()
+	settings = &config.Profile{}
+	// stdout is a terminal, but there's no terminal on stdin to ask on
+	isTerminal = func(f *os.File) bool { return f == os.Stdout }
+
+	if err := confirmOutput(defaultTerminalRows, false); err != nil {
+		t.Errorf("Expected no error for %d results, got: %v", defaultTerminalRows, err)
+	}
+	if err := confirmOutput(defaultTerminalRows+1, false); exitCode(err) != exitUsage {
+		t.Errorf("Expected a usage error for %d results, got: %v", defaultTerminalRows+1, err)
+	}
+	if err := confirmOutput(defaultTerminalRows+1, true); err != nil {
+		t.Errorf("Expected no error with --yes, got: %v", err)
+	}
+
+	settings.TerminalRows = -1
+	if err := confirmOutput(100000, false); err != nil {
+		t.Errorf("Expected no error with terminal_rows -1, got: %v", err)
+	}
+
+	// Output that isn't to a terminal is never held back
+	settings.TerminalRows = 10
+	isTerminal = func(f *os.File) bool { return false }
+	if err := confirmOutput(100000, false); err != nil {
+		t.Errorf("Expected no error when stdout isn't a terminal, got: %v", err)
+	}
+}
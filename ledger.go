@@ -0,0 +1,125 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/kitproj/splunk-cli/internal/config"
+	"github.com/kitproj/splunk-cli/pkg/splunk"
+)
+
+// exportLedger records the progress of a partitioned export, next to the results of its finished
+// slices, so --resume can re-run only the slices that failed or never ran
+type exportLedger struct {
+	Query     string        `json:"query"`
+	Partition string        `json:"partition"`
+	Slices    []ledgerEntry `json:"slices"`
+
+	dir string
+	mu  sync.Mutex
+}
+
+// ledgerEntry is the progress of one slice
+type ledgerEntry struct {
+	timeSlice
+	// Status is "done" or "failed", or empty if the slice hasn't finished
+	Status  string `json:"status,omitempty"`
+	Results int    `json:"results,omitempty"`
+	Error   string `json:"error,omitempty"`
+}
+
+// ledgerDir returns the directory of the ledger for an export. Runs of the same search, time range
+// and partition with the same profile share a ledger, so they can resume each other.
+func ledgerDir(query string, opts splunk.SearchOptions, partition time.Duration) (string, error) {
+	dir, err := config.CacheDir()
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.New()
+	for _, s := range []string{profileName(), opts.Namespace.App, query, opts.EarliestTime, opts.LatestTime, opts.AdhocSearchLevel, partition.String()} {
+		fmt.Fprintf(sum, "%q\n", s)
+	}
+	return filepath.Join(dir, "exports", hex.EncodeToString(sum.Sum(nil))[:16]), nil
+}
+
+// newExportLedger starts a ledger in dir, discarding any earlier run's
+func newExportLedger(dir, query string, partition time.Duration, slices []timeSlice) (*exportLedger, error) {
+	if err := os.RemoveAll(dir); err != nil {
+		return nil, fmt.Errorf("failed to remove previous export: %w", err)
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, fmt.Errorf("failed to create export directory: %w", err)
+	}
+	l := &exportLedger{Query: redact(query), Partition: partition.String(), dir: dir}
+	for _, s := range slices {
+		l.Slices = append(l.Slices, ledgerEntry{timeSlice: s})
+	}
+	return l, l.save()
+}
+
+// loadExportLedger loads the ledger in dir, returning nil if there isn't one
+func loadExportLedger(dir string) (*exportLedger, error) {
+	data, err := os.ReadFile(filepath.Join(dir, "ledger.json"))
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to read export ledger: %w", err)
+	}
+	l := &exportLedger{dir: dir}
+	if err := json.Unmarshal(data, l); err != nil {
+		return nil, fmt.Errorf("failed to parse export ledger: %w", err)
+	}
+	return l, nil
+}
+
+// spoolPath returns the file slice i's results are written to
+func (l *exportLedger) spoolPath(i int) string {
+	return filepath.Join(l.dir, strconv.Itoa(i)+".ndjson")
+}
+
+// done reports whether slice i finished in this or an earlier run
+func (l *exportLedger) done(i int) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.Slices[i].Status == "done"
+}
+
+// record records the outcome of slice i
+func (l *exportLedger) record(i, results int, err error) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	entry := &l.Slices[i]
+	entry.Status, entry.Results, entry.Error = "done", results, ""
+	if err != nil {
+		entry.Status, entry.Results, entry.Error = "failed", 0, err.Error()
+	}
+	return l.save()
+}
+
+// save writes the ledger, replacing the file so an interrupted write can't corrupt it
+func (l *exportLedger) save() error {
+	data, err := json.MarshalIndent(l, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := filepath.Join(l.dir, "ledger.json.tmp")
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return fmt.Errorf("failed to write export ledger: %w", err)
+	}
+	if err := os.Rename(tmp, filepath.Join(l.dir, "ledger.json")); err != nil {
+		return fmt.Errorf("failed to write export ledger: %w", err)
+	}
+	return nil
+}
+
+// remove deletes the ledger and spooled results once the export has succeeded
+func (l *exportLedger) remove() error {
+	return os.RemoveAll(l.dir)
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"net/url"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+func linkCommand() *command {
+	var earliest, latest *string
+	var open *bool
+	return &command{
+		name:  "link",
+		args:  "<query>",
+		short: "Print the Splunk Web URL of a search, to share it",
+		long: "Print the URL of a search in Splunk Web, so it can be handed to someone who works in the UI.\n" +
+			"Splunk Web is assumed to be on port 8000 of the host; set web_url in the profile if it isn't.",
+		minArgs: 1,
+		maxArgs: 1,
+		flags: func(flags *flag.FlagSet) {
+			earliest = flags.String("earliest", "", "earliest time of the search, e.g. -1h (default: the profile's)")
+			latest = flags.String("latest", "", "latest time of the search, e.g. now (default: the profile's)")
+			open = flags.Bool("open", false, "open the URL in the browser as well")
+		},
+		run: func(ctx context.Context, args []string) error {
+			if err := loadSettings(); err != nil {
+				return err
+			}
+			host := defaultString(settings.Host, os.Getenv("SPLUNK_HOST"))
+			if host == "" && settings.WebURL == "" {
+				return fmt.Errorf("host is required")
+			}
+			link := searchLink(settings.SplunkWebURL(host), settings.App, args[0],
+				defaultString(*earliest, settings.Earliest), defaultString(*latest, settings.Latest))
+			fmt.Println(link)
+			if *open {
+				return openBrowser(link)
+			}
+			return nil
+		},
+	}
+}
+
+// searchLink returns the URL of the search page of Splunk Web at base for a query, in the app
+// (or the search app) and over the time range, if given
+func searchLink(base, app, query, earliest, latest string) string {
+	params := url.Values{}
+	params.Set("q", ensureSearchCommand(query))
+	if earliest != "" {
+		params.Set("earliest", earliest)
+	}
+	if latest != "" {
+		params.Set("latest", latest)
+	}
+	// Splunk Web redirects to the user's locale, so the URL doesn't need one
+	return fmt.Sprintf("%s/app/%s/search?%s", base, url.PathEscape(defaultString(app, "search")), params.Encode())
+}
+
+// webSearch is a search parsed from a Splunk Web URL
+type webSearch struct {
+	query, app, earliest, latest string
+}
+
+// parseSearchLink parses the URL of a search page of Splunk Web, such as one from searchLink or a
+// browser's address bar, reporting whether s is one
+func parseSearchLink(s string) (webSearch, bool) {
+	if !strings.HasPrefix(s, "http://") && !strings.HasPrefix(s, "https://") {
+		return webSearch{}, false
+	}
+	u, err := url.Parse(s)
+	if err != nil {
+		return webSearch{}, false
+	}
+	query := u.Query()
+	if query.Get("q") == "" {
+		return webSearch{}, false
+	}
+
+	// The path is /app/<app>/search, after the locale and any root endpoint, e.g. /en-US/app/search/search
+	segments := strings.Split(strings.Trim(u.Path, "/"), "/")
+	n := len(segments)
+	if n < 3 || segments[n-3] != "app" || segments[n-1] != "search" {
+		return webSearch{}, false
+	}
+	return webSearch{
+		query:    query.Get("q"),
+		app:      segments[n-2],
+		earliest: query.Get("earliest"),
+		latest:   query.Get("latest"),
+	}, true
+}
+
+// openBrowser opens a URL with the desktop's default browser
+func openBrowser(link string) error {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = exec.Command("open", link)
+	case "windows":
+		cmd = exec.Command("rundll32", "url.dll,FileProtocolHandler", link)
+	default:
+		cmd = exec.Command("xdg-open", link)
+	}
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("failed to open browser: %w", err)
+	}
+	return cmd.Process.Release()
+}
@@ -0,0 +1,39 @@
+package main
+
+import "testing"
+
+func TestSearchLink(t *testing.T) {
+	got := searchLink("https://splunk:8000", "", "index=main status=500", "-1h", "")
+	want := "https://splunk:8000/app/search/search?earliest=-1h&q=search+index%3Dmain+status%3D500"
+	if got != want {
+		t.Errorf("Expected %s, got: %s", want, got)
+	}
+
+	got = searchLink("https://splunk:8000", "my app", "| tstats count", "", "")
+	want = "https://splunk:8000/app/my%20app/search?q=%7C+tstats+count"
+	if got != want {
+		t.Errorf("Expected %s, got: %s", want, got)
+	}
+}
+
+func TestParseSearchLink(t *testing.T) {
+	link := "https://splunk.example.com:8000/en-US/app/my_app/search?q=search%20index%3Dmain%20error&earliest=-4h%40h&latest=now&display.page.search.mode=smart"
+	got, ok := parseSearchLink(link)
+	want := webSearch{query: "search index=main error", app: "my_app", earliest: "-4h@h", latest: "now"}
+	if !ok || got != want {
+		t.Errorf("Expected %+v, got: %+v", want, got)
+	}
+
+	// Links from the link command round trip
+	got, ok = parseSearchLink(searchLink("https://splunk:8000", "", "| tstats count", "-1h", ""))
+	want = webSearch{query: "| tstats count", app: "search", earliest: "-1h"}
+	if !ok || got != want {
+		t.Errorf("Expected %+v, got: %+v", want, got)
+	}
+
+	for _, query := range []string{"index=main https://example.com", "https://splunk:8000/en-US/app/search/dashboards", "https://splunk:8000/en-US/app/search/search"} {
+		if _, ok := parseSearchLink(query); ok {
+			t.Errorf("Expected %q not to be a search link", query)
+		}
+	}
+}
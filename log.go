@@ -0,0 +1,128 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// logFormats are the formats diagnostics can be logged to stderr in
+var logFormats = []string{"text", "json"}
+
+// logLevel is the least severe level logged, set by --log-level
+var logLevel slog.LevelVar
+
+// setDebug sets the log level to debug, which logs API calls, if value is true, and sets flag, if
+// given, to value
+func setDebug(value string, flag *bool) error {
+	on, err := strconv.ParseBool(value)
+	if err != nil {
+		return err
+	}
+	if flag != nil {
+		*flag = on
+	}
+	if on {
+		logLevel.Set(slog.LevelDebug)
+	}
+	return nil
+}
+
+// setLogFormat logs diagnostics to w in a format, text for people or json for log pipelines
+func setLogFormat(w io.Writer, format string) error {
+	switch format {
+	case "text":
+		slog.SetDefault(slog.New(&textHandler{w: w, level: &logLevel, mu: &sync.Mutex{}}))
+	case "json":
+		slog.SetDefault(slog.New(slog.NewJSONHandler(w, &slog.HandlerOptions{Level: &logLevel})))
+	default:
+		return fmt.Errorf("unknown log format %q (must be %s)", format, strings.Join(logFormats, " or "))
+	}
+	return nil
+}
+
+// textHandler logs records as plain lines, e.g. "Warning: slice failed slice=2024-01-01 err=...", without
+// the time and level fields of slog's text handler, which make progress messages hard to read in a terminal
+type textHandler struct {
+	w      io.Writer
+	level  slog.Leveler
+	attrs  []slog.Attr
+	prefix string
+	mu     *sync.Mutex
+}
+
+func (h *textHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return level >= h.level.Level()
+}
+
+func (h *textHandler) Handle(_ context.Context, r slog.Record) error {
+	var b strings.Builder
+	switch {
+	case r.Level >= slog.LevelError:
+		b.WriteString("Error: ")
+	case r.Level >= slog.LevelWarn:
+		b.WriteString("Warning: ")
+	case r.Level < slog.LevelInfo:
+		b.WriteString("Debug: ")
+	}
+	b.WriteString(r.Message)
+	for _, a := range h.attrs {
+		writeAttr(&b, "", a)
+	}
+	r.Attrs(func(a slog.Attr) bool {
+		writeAttr(&b, h.prefix, a)
+		return true
+	})
+	b.WriteByte('\n')
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	_, err := io.WriteString(h.w, b.String())
+	return err
+}
+
+func (h *textHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	c := *h
+	c.attrs = append([]slog.Attr{}, h.attrs...)
+	for _, a := range attrs {
+		c.attrs = append(c.attrs, slog.Attr{Key: h.prefix + a.Key, Value: a.Value})
+	}
+	return &c
+}
+
+func (h *textHandler) WithGroup(name string) slog.Handler {
+	c := *h
+	c.prefix += name + "."
+	return &c
+}
+
+// writeAttr writes an attribute as key=value, quoting the value if it has spaces, quotes or equals signs
+func writeAttr(b *strings.Builder, prefix string, a slog.Attr) {
+	a.Value = a.Value.Resolve()
+	if a.Equal(slog.Attr{}) {
+		return
+	}
+	if a.Value.Kind() == slog.KindGroup {
+		for _, g := range a.Value.Group() {
+			writeAttr(b, prefix+a.Key+".", g)
+		}
+		return
+	}
+	value := a.Value.String()
+	if value == "" || strings.ContainsAny(value, " \t\n\"=") {
+		value = strconv.Quote(value)
+	}
+	fmt.Fprintf(b, " %s%s=%s", prefix, a.Key, value)
+}
+
+// profileAttr is the selected profile to log, or an empty attribute, which isn't logged, for the default profile
+func profileAttr() slog.Attr {
+	if name := profileName(); name != "" {
+		return slog.String("profile", name)
+	}
+	return slog.Attr{}
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"testing"
+)
+
+func TestSetLogFormat(t *testing.T) {
+	defer slog.SetDefault(slog.Default())
+	defer logLevel.Set(logLevel.Level())
+
+	var buf bytes.Buffer
+	if err := setLogFormat(&buf, "text"); err != nil {
+		t.Fatal(err)
+	}
+	slog.Info("Running search", "query", "index=main error", "count", 10)
+	slog.Warn("Slice failed", "err", errors.New("boom"), slog.Attr{})
+	slog.Debug("Not logged")
+	logLevel.Set(slog.LevelWarn)
+	slog.Info("Not logged either")
+	want := "Running search query=\"index=main error\" count=10\nWarning: Slice failed err=boom\n"
+	if buf.String() != want {
+		t.Errorf("Expected %q, got %q", want, buf.String())
+	}
+
+	buf.Reset()
+	if err := setLogFormat(&buf, "json"); err != nil {
+		t.Fatal(err)
+	}
+	slog.Warn("Lost the search", "sid", "123")
+	var record map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &record); err != nil {
+		t.Fatal(err)
+	}
+	if record["level"] != "WARN" || record["msg"] != "Lost the search" || record["sid"] != "123" {
+		t.Errorf("Unexpected record: %v", record)
+	}
+
+	if err := setLogFormat(&buf, "xml"); err == nil {
+		t.Error("Expected an error for an unknown format")
+	}
+}
+
+func TestSetDebug(t *testing.T) {
+	defer logLevel.Set(logLevel.Level())
+	defer func() { debugBody = false }()
+
+	logLevel.Set(slog.LevelInfo)
+	if tracer() != nil {
+		t.Error("Expected no tracer at info level")
+	}
+	if err := setDebug("false", &debugBody); err != nil || logLevel.Level() != slog.LevelInfo {
+		t.Errorf("Expected -debug-body=false to leave the level, got %v and: %v", logLevel.Level(), err)
+	}
+	if err := setDebug("true", &debugBody); err != nil || logLevel.Level() != slog.LevelDebug {
+		t.Errorf("Expected -debug-body to set the debug level, got %v and: %v", logLevel.Level(), err)
+	}
+	if tr := tracer(); tr == nil || !tr.Bodies {
+		t.Errorf("Expected a tracer of bodies at debug level, got: %+v", tr)
+	}
+}
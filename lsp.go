@@ -0,0 +1,298 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kitproj/splunk-cli/pkg/splunk"
+)
+
+// rpcRequest represents an incoming JSON-RPC 2.0 request or notification
+type rpcRequest struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+// rpcResponse represents an outgoing JSON-RPC 2.0 response
+type rpcResponse struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  interface{}     `json:"result,omitempty"`
+	Error   *rpcError       `json:"error,omitempty"`
+}
+
+// rpcError represents a JSON-RPC 2.0 error object
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+const (
+	rpcMethodNotFound = -32601
+	rpcInvalidParams  = -32602
+	rpcInternalError  = -32603
+)
+
+// lspServer serves editor requests over stdio using LSP-style Content-Length framing
+type lspServer struct {
+	client splunk.API
+	out    io.Writer
+	mu     sync.Mutex
+
+	// fields caches the field names of the events of each set of indexes, used for completion
+	fields map[string]cachedFields
+}
+
+// cachedFields are the field names of some indexes' events, and when they were summarized
+type cachedFields struct {
+	names   []string
+	fetched time.Time
+}
+
+// lspFieldsTTL is how long the field names of indexes are cached for, and lspFieldEvents how
+// many of their recent events the names are summarized from
+const (
+	lspFieldsTTL   = 10 * time.Minute
+	lspFieldEvents = 1000
+)
+
+// runLSPServer starts the editor integration server on stdin/stdout
+func runLSPServer(ctx context.Context) error {
+	s := &lspServer{
+		client: client,
+		out:    os.Stdout,
+		fields: map[string]cachedFields{},
+	}
+	return s.serve(ctx, os.Stdin)
+}
+
+func (s *lspServer) serve(ctx context.Context, in io.Reader) error {
+	reader := bufio.NewReader(in)
+	for {
+		if err := ctx.Err(); err != nil {
+			return nil
+		}
+
+		body, err := readMessage(reader)
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		var req rpcRequest
+		if err := json.Unmarshal(body, &req); err != nil {
+			return fmt.Errorf("failed to decode request: %w", err)
+		}
+
+		if req.Method == "exit" {
+			return nil
+		}
+
+		result, rpcErr := s.handle(ctx, req)
+
+		// Notifications have no ID and must not be answered
+		if len(req.ID) == 0 {
+			continue
+		}
+
+		if err := s.write(rpcResponse{JSONRPC: "2.0", ID: req.ID, Result: result, Error: rpcErr}); err != nil {
+			return err
+		}
+	}
+}
+
+// queryParams are the parameters accepted by the splunk/* methods
+type queryParams struct {
+	Query        string `json:"query"`
+	Prefix       string `json:"prefix"`
+	EarliestTime string `json:"earliest_time"`
+	LatestTime   string `json:"latest_time"`
+	MaxResults   int    `json:"max_results"`
+}
+
+func (s *lspServer) handle(ctx context.Context, req rpcRequest) (interface{}, *rpcError) {
+	var params queryParams
+	if len(req.Params) > 0 {
+		if err := json.Unmarshal(req.Params, &params); err != nil {
+			return nil, &rpcError{Code: rpcInvalidParams, Message: err.Error()}
+		}
+	}
+
+	switch req.Method {
+	case "initialize":
+		return map[string]interface{}{
+			"capabilities": map[string]interface{}{
+				"experimental": map[string]interface{}{
+					"splunk": []string{"splunk/validate", "splunk/expandMacros", "splunk/completeFields", "splunk/runSelection"},
+				},
+			},
+			"serverInfo": map[string]string{"name": "splunk-cli"},
+		}, nil
+	case "initialized", "shutdown":
+		return nil, nil
+	case "splunk/validate":
+		parsed, err := s.client.ParseSearch(ctx, ensureSearchCommand(params.Query))
+		if err != nil {
+			return map[string]interface{}{"valid": false, "error": err.Error()}, nil
+		}
+		return map[string]interface{}{"valid": true, "commands": parsed.Commands}, nil
+	case "splunk/expandMacros":
+		parsed, err := s.client.ParseSearch(ctx, ensureSearchCommand(params.Query))
+		if err != nil {
+			return nil, &rpcError{Code: rpcInternalError, Message: err.Error()}
+		}
+		stages := make([]string, len(parsed.Commands))
+		for i, command := range parsed.Commands {
+			stages[i] = strings.TrimSpace(command.Command + " " + command.RawArgs)
+		}
+		return map[string]string{"query": strings.Join(stages, " | ")}, nil
+	case "splunk/completeFields":
+		fields, err := s.completeFields(ctx, params.Query, params.Prefix)
+		if err != nil {
+			return nil, &rpcError{Code: rpcInternalError, Message: err.Error()}
+		}
+		return map[string][]string{"fields": fields}, nil
+	case "splunk/runSelection":
+		results, err := s.runSelection(ctx, params)
+		if err != nil {
+			return nil, &rpcError{Code: rpcInternalError, Message: err.Error()}
+		}
+		return results, nil
+	default:
+		return nil, &rpcError{Code: rpcMethodNotFound, Message: fmt.Sprintf("unknown method: %s", req.Method)}
+	}
+}
+
+// completeFields returns the field names starting with prefix of the events of the indexes the
+// query names, or of the user's default indexes if it names none
+func (s *lspServer) completeFields(ctx context.Context, query, prefix string) ([]string, error) {
+	names, err := s.indexFields(ctx, queryIndexes(query))
+	if err != nil {
+		return nil, err
+	}
+	fields := []string{}
+	for _, name := range names {
+		if strings.HasPrefix(name, prefix) {
+			fields = append(fields, name)
+		}
+	}
+	return fields, nil
+}
+
+// indexFields returns the sorted field names of the indexes' recent events, summarized with
+// fieldsummary and cached for a while, as editors ask on most key presses
+func (s *lspServer) indexFields(ctx context.Context, indexes []string) ([]string, error) {
+	key := strings.Join(indexes, ",")
+	s.mu.Lock()
+	cached, ok := s.fields[key]
+	s.mu.Unlock()
+	if ok && time.Since(cached.fetched) < lspFieldsTTL {
+		return cached.names, nil
+	}
+
+	terms := make([]string, len(indexes))
+	for i, index := range indexes {
+		terms[i] = "index=" + quoteSPL(index)
+	}
+	query := "search " + defaultString(strings.Join(terms, " OR "), "*") +
+		fmt.Sprintf(" | head %d | fieldsummary | fields field", lspFieldEvents)
+	results, err := s.client.OneshotSearch(ctx, query, splunk.SearchOptions{EarliestTime: "-24h"}, 10000)
+	if err != nil {
+		return nil, fmt.Errorf("failed to summarize fields: %w", err)
+	}
+	names := []string{}
+	for _, result := range results.Results {
+		if name, ok := result["field"].(string); ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	s.mu.Lock()
+	s.fields[key] = cachedFields{names: names, fetched: time.Now()}
+	s.mu.Unlock()
+	return names, nil
+}
+
+// runSelection runs the selected query
+func (s *lspServer) runSelection(ctx context.Context, params queryParams) (*splunk.SearchResult, error) {
+	maxResults := params.MaxResults
+	if maxResults == 0 {
+		maxResults = 100
+	}
+
+	sid, err := s.client.RunSearch(ctx, ensureSearchCommand(params.Query), splunk.SearchOptions{
+		EarliestTime: params.EarliestTime,
+		LatestTime:   params.LatestTime,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to run search: %w", err)
+	}
+
+	if _, err := splunk.NewJobWaiter(s.client).Wait(ctx, sid); err != nil {
+		return nil, err
+	}
+
+	results, err := s.client.GetResults(ctx, sid, splunk.ResultsOptions{Count: maxResults})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get search results: %w", err)
+	}
+
+	return results, nil
+}
+
+func (s *lspServer) write(resp rpcResponse) error {
+	data, err := json.Marshal(resp)
+	if err != nil {
+		return fmt.Errorf("failed to encode response: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, err = fmt.Fprintf(s.out, "Content-Length: %d\r\n\r\n%s", len(data), data)
+	return err
+}
+
+// readMessage reads a single Content-Length framed message body
+func readMessage(r *bufio.Reader) ([]byte, error) {
+	length := -1
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+		line = strings.TrimRight(line, "\r\n")
+		if line == "" {
+			break
+		}
+		name, value, ok := strings.Cut(line, ":")
+		if ok && strings.EqualFold(strings.TrimSpace(name), "Content-Length") {
+			length, err = strconv.Atoi(strings.TrimSpace(value))
+			if err != nil {
+				return nil, fmt.Errorf("invalid Content-Length header: %w", err)
+			}
+		}
+	}
+	if length < 0 {
+		return nil, fmt.Errorf("missing Content-Length header")
+	}
+
+	body := make([]byte, length)
+	if _, err := io.ReadFull(r, body); err != nil {
+		return nil, err
+	}
+	return body, nil
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/kitproj/splunk-cli/pkg/splunk"
+	"github.com/kitproj/splunk-cli/pkg/splunk/splunktest"
+)
+
+func frame(body string) string {
+	return fmt.Sprintf("Content-Length: %d\r\n\r\n%s", len(body), body)
+}
+
+func TestLSPServerCompleteFields(t *testing.T) {
+	var out bytes.Buffer
+	var queries []string
+	fake := &splunktest.Fake{
+		OneshotSearchFunc: func(ctx context.Context, query string, opts splunk.SearchOptions, count int) (*splunk.SearchResult, error) {
+			queries = append(queries, query)
+			return &splunk.SearchResult{Results: []map[string]interface{}{{"field": "status"}, {"field": "source"}, {"field": "host"}}}, nil
+		},
+	}
+	s := &lspServer{client: fake, out: &out, fields: map[string]cachedFields{}}
+
+	// The second request is answered from the cache
+	in := frame(`{"jsonrpc":"2.0","id":1,"method":"splunk/completeFields","params":{"query":"index=web | stats count by ","prefix":"s"}}`) +
+		frame(`{"jsonrpc":"2.0","id":2,"method":"splunk/completeFields","params":{"query":"index=web error","prefix":"h"}}`) +
+		frame(`{"jsonrpc":"2.0","method":"exit"}`)
+
+	if err := s.serve(context.Background(), strings.NewReader(in)); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	responses := bufio.NewReader(&out)
+	for _, want := range []string{"source,status", "host"} {
+		body, err := readMessage(responses)
+		if err != nil {
+			t.Fatalf("Expected framed response, got: %v", err)
+		}
+		var resp struct {
+			Result struct {
+				Fields []string `json:"fields"`
+			} `json:"result"`
+		}
+		if err := json.Unmarshal(body, &resp); err != nil {
+			t.Fatalf("Failed to decode response: %v", err)
+		}
+		if got := strings.Join(resp.Result.Fields, ","); got != want {
+			t.Errorf("Expected fields %s, got: %s", want, got)
+		}
+	}
+	if want := `search index="web" | head 1000 | fieldsummary | fields field`; len(queries) != 1 || queries[0] != want {
+		t.Errorf("Expected one field summary of the web index, got: %q", queries)
+	}
+}
+
+func TestLSPServerUnknownMethod(t *testing.T) {
+	var out bytes.Buffer
+	s := &lspServer{out: &out, fields: map[string]cachedFields{}}
+
+	in := frame(`{"jsonrpc":"2.0","id":"a","method":"textDocument/hover"}`)
+	if err := s.serve(context.Background(), strings.NewReader(in)); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	if !strings.Contains(out.String(), fmt.Sprintf(`"code":%d`, rpcMethodNotFound)) {
+		t.Errorf("Expected method not found error, got: %s", out.String())
+	}
+}
@@ -2,204 +2,472 @@ package main
 
 import (
 	"context"
+	"errors"
 	"flag"
 	"fmt"
+	"io"
+	"log/slog"
 	"os"
+	"os/exec"
 	"os/signal"
+	"strconv"
 	"strings"
 	"syscall"
 	"time"
 
 	"github.com/kitproj/splunk-cli/internal/config"
-	"github.com/kitproj/splunk-cli/internal/splunk"
+	"github.com/kitproj/splunk-cli/internal/output"
+	"github.com/kitproj/splunk-cli/pkg/splunk"
 	"golang.org/x/term"
 )
 
+// version is set at build time with -ldflags "-X main.version=..."
+var version = "dev"
+
 var (
-	host   string
-	token  string
-	client *splunk.Client
+	client     *splunk.Client
+	requestID  string
+	noCache    bool
+	profile    string
+	clientCert string
+	clientKey  string
+	caCert     string
+	insecure   *bool
+	debugBody  bool
+	proxyURL   string
+	appName    string
+	ownerName  string
+	themeName  string
+	tableStyle string
+	maxWidth   int
 )
 
+// commandTimeout is how long a command may take, set by -timeout, or 0 for no limit
+var commandTimeout time.Duration
+
 func main() {
 	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
 	defer cancel()
+	defer recoverCrash()
+	logCommands = true
+	_ = setLogFormat(os.Stderr, "text")
 
-	flag.Usage = func() {
-		w := flag.CommandLine.Output()
-		fmt.Fprintf(w, "Usage:")
-		fmt.Fprintln(w)
-		fmt.Fprintln(w, "  splunk configure <host> - Configure Splunk host and token (reads token from stdin)")
-		fmt.Fprintln(w, "  splunk search <query> [earliest-time] [latest-time] - Run a Splunk search query")
-		fmt.Fprintln(w, "  splunk mcp-server - Start MCP server (stdio transport)")
-		fmt.Fprintln(w)
-		fmt.Fprintln(w, "Options:")
-		flag.PrintDefaults()
+	root := rootCommand()
+	if err := root.execute(ctx, os.Args[1:]); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return
+		}
+		// A plugin has already reported its own error
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			os.Exit(exitErr.ExitCode())
+		}
+		slog.Error(redact(err.Error()))
+		root.printHelp(os.Stderr)
+		os.Exit(exitCode(err))
 	}
-	flag.Parse()
+}
 
-	if err := run(ctx, flag.Args()); err != nil {
-		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-		flag.Usage()
-		os.Exit(1)
+// rootCommand returns the tree of every splunk command
+func rootCommand() *command {
+	root := &command{
+		name:  "splunk",
+		short: "Command-line interface and MCP server for Splunk",
+		long: "Command-line interface and MCP server for Splunk.\n" +
+			"Output formats (-o): text (default, or the profile's output format), json, ndjson, csv, table\n" +
+			"Run 'splunk help <command>' for a command's flags, or 'splunk docs' for the full reference.\n" +
+			exitCodesHelp() + ".",
+		globalFlags: func(flags *flag.FlagSet) {
+			flags.StringVar(&requestID, "request-id", "", "X-Request-Id to send with every API call (default: a random ID per call)")
+			flags.BoolVar(&noCache, "no-cache", false, "always fetch saved searches, indexes and server info from the API instead of the local cache")
+			flags.StringVar(&appName, "app", "", "app whose namespace searches and saved searches are in, e.g. to use its lookups and macros (default: the profile's app)")
+			flags.StringVar(&ownerName, "owner", "", "owner whose namespace searches and saved searches are in, or nobody for objects shared in the app (default: the profile's owner)")
+			flags.StringVar(&profile, "profile", "", "named profile of the config file to use (default: $SPLUNK_PROFILE, or the top-level settings)")
+			flags.StringVar(&clientCert, "client-cert", "", "client certificate for mutual TLS, as a PEM file or a PKCS#12 bundle (.p12 or .pfx) with the password in $SPLUNK_CLIENT_CERT_PASSWORD (default: the profile's client_cert)")
+			flags.StringVar(&clientKey, "client-key", "", "PEM key of the client certificate, if not in the certificate file (default: the profile's client_key)")
+			flags.StringVar(&caCert, "ca-cert", "", "PEM bundle of CA certificates to trust as well as the system's (default: the profile's ca_cert)")
+			flags.BoolFunc("debug", "log the method, URL, status and latency of every API call to stderr (same as -log-level debug)", func(s string) error {
+				return setDebug(s, nil)
+			})
+			flags.BoolFunc("debug-body", "log the start of request and response bodies as well, with passwords, tokens and session keys redacted (implies -debug)", func(s string) error {
+				return setDebug(s, &debugBody)
+			})
+			flags.Func("log-level", "least severe diagnostics to log to stderr: debug, info, warn or error (default: info)", func(s string) error {
+				return logLevel.UnmarshalText([]byte(s))
+			})
+			flags.Func("log-format", "format of diagnostics logged to stderr: text, or json for log pipelines (default: text)", func(s string) error {
+				return setLogFormat(os.Stderr, s)
+			})
+			flags.DurationVar(&commandTimeout, "timeout", 0, "how long a command may take before it's stopped and its search jobs cancelled, e.g. 10m (default: no limit)")
+			flags.StringVar(&proxyURL, "proxy", "", "URL of an HTTP, HTTPS or SOCKS5 proxy to connect through, e.g. socks5://localhost:1080 (default: the profile's proxy, or $HTTPS_PROXY)")
+			flags.BoolFunc("insecure", "don't verify the server's TLS certificate, e.g. for self-signed lab instances, or -insecure=false to verify it whatever the profile says (default: the profile's insecure)", func(s string) error {
+				v, err := strconv.ParseBool(s)
+				if err != nil {
+					return err
+				}
+				insecure = &v
+				return nil
+			})
+			flags.StringVar(&themeName, "theme", "", "color theme of text and table output on a terminal: "+strings.Join(output.Themes, ", ")+" (default: the profile's output theme, or none)")
+			flags.StringVar(&tableStyle, "table-style", "", "style of table output: "+strings.Join(output.TableStyles, ", ")+" (default: the profile's output table_style, or plain)")
+			flags.IntVar(&maxWidth, "max-width", 0, "most characters a table column may be wide before its cells are cut short, or -1 for no limit (default: the profile's output max_width, or no limit)")
+		},
+		flagValues: map[string]func() []string{
+			"profile":     profileNames,
+			"output":      staticValues(output.Formats...),
+			"o":           staticValues(output.Formats...),
+			"theme":       staticValues(output.Themes...),
+			"table-style": staticValues(output.TableStyles...),
+			"log-level":   staticValues("debug", "info", "warn", "error"),
+			"log-format":  staticValues(logFormats...),
+		},
+		subcommands: []*command{
+			initCommand(),
+			configureCommand(),
+			credentialsCommand(),
+			searchCommand(),
+			exportCommand(),
+			runCommand(),
+			resultsCommand(),
+			followCommand(),
+			tailCommand(),
+			fieldsCommand(),
+			sampleCommand(),
+			linkCommand(),
+			quoteCommand(),
+			replCommand(),
+			browseCommand(),
+			jobsCommand(),
+			dispatchCommand(),
+			savedSearchCommand(),
+			alertsCommand(),
+			indexCommand(),
+			sendCommand(),
+			apiCommand(),
+			secretCommand(),
+			bugReportCommand(),
+			mcpServerCommand(),
+			lspCommand(),
+		},
 	}
+	root.subcommands = append(root.subcommands, generatedCommands()...)
+	root.subcommands = append(root.subcommands, docsCommand(root), helpCommand(root), completionCommand(root), completeCommand(root))
+	root.subcommands = append(root.subcommands, pluginCommands(root)...)
+	return root.link()
 }
 
-func run(ctx context.Context, args []string) error {
-	if len(args) < 1 {
-		return fmt.Errorf("usage: splunk <command> [args...]")
+func helpCommand(root *command) *command {
+	return &command{
+		name:    "help",
+		args:    "[command...]",
+		short:   "Print the help of a command",
+		maxArgs: -1,
+		run: func(ctx context.Context, args []string) error {
+			cmd := root
+			for _, name := range args {
+				if cmd = cmd.find(name); cmd == nil {
+					return fmt.Errorf("unknown sub-command: %s", name)
+				}
+			}
+			cmd.printHelp(os.Stdout)
+			return nil
+		},
 	}
+}
 
-	// First argument is the command
-	command := args[0]
+func initCommand() *command {
+	return &command{
+		name:  "init",
+		short: "Interactively set up the host, token and defaults, and register the MCP server",
+		run: func(ctx context.Context, args []string) error {
+			return runInit(ctx)
+		},
+	}
+}
 
-	switch command {
-	case "configure":
-		if len(args) < 2 {
-			return fmt.Errorf("usage: splunk configure <host>")
-		}
-		return configure(args[1])
-	case "search":
-		if len(args) < 2 {
-			return fmt.Errorf("usage: splunk search <query> [earliest-time] [latest-time]")
-		}
-		query := args[1]
-		var earliestTime, latestTime string
-		if len(args) >= 3 {
-			earliestTime = args[2]
-		}
-		if len(args) >= 4 {
-			latestTime = args[3]
-		}
-		return executeCommand(ctx, func(ctx context.Context) error {
-			return runSearch(ctx, query, earliestTime, latestTime)
-		})
-	case "mcp-server":
-		return runMCPServer(ctx)
-	default:
-		return fmt.Errorf("unknown sub-command: %s", command)
+func configureCommand() *command {
+	var auth, username, scheme, hecURL *string
+	var port *int
+	var hec *bool
+	return &command{
+		name:  "configure",
+		args:  "<host[:port]>",
+		short: "Configure Splunk host and token (reads token from stdin)",
+		long: "Save the Splunk host to the config file and the API token, read from stdin, to the system keyring.\nWith --auth basic, save a username and password instead, which are used to log in for session keys.\n" +
+			"The host may include the management port and scheme, e.g. http://localhost:8089, or set them with --port and --scheme.\n" +
+			"Any -client-cert, -client-key, -ca-cert, -insecure and -proxy global flags are saved to the profile too;\n" +
+			"-insecure=false clears a saved insecure.\n" +
+			"With --hec, save an HTTP Event Collector token for 'splunk send' instead, leaving the API token as it is.",
+		minArgs: 1,
+		maxArgs: 1,
+		flags: func(flags *flag.FlagSet) {
+			auth = flags.String("auth", "token", "how to authenticate: token, or basic for a username and password")
+			username = flags.String("username", "", "username to log in as, with basic auth")
+			port = flags.Int("port", 0, "port of the management API, if not in the host (default: 8089)")
+			scheme = flags.String("scheme", "", "scheme of the management API, http or https, if not in the host (default: https)")
+			hec = flags.Bool("hec", false, "save an HTTP Event Collector token for 'splunk send' instead of the API token")
+			hecURL = flags.String("hec-url", "", "URL of the HTTP Event Collector, with --hec (default: port 8088 of the host)")
+		},
+		flagValues: map[string]func() []string{
+			"auth":   staticValues("token", "basic"),
+			"scheme": staticValues("https", "http"),
+		},
+		run: func(ctx context.Context, args []string) error {
+			host, hostPort, hostScheme, err := config.ParseHost(args[0])
+			if err != nil {
+				return err
+			}
+			if *port != 0 {
+				hostPort = *port
+			}
+			if *scheme != "" {
+				if *scheme != "http" && *scheme != "https" {
+					return fmt.Errorf("unknown scheme %q (must be http or https)", *scheme)
+				}
+				hostScheme = *scheme
+			}
+			if *hec {
+				return configureHEC(host, hostPort, hostScheme, *hecURL)
+			}
+			return configure(host, hostPort, hostScheme, *auth, *username)
+		},
 	}
 }
 
-func executeCommand(ctx context.Context, fn func(context.Context) error) error {
-	// Load host from config file, or fall back to env var
-	if host == "" {
-		var err error
-		host, err = config.LoadConfig()
-		if err != nil {
-			// Fall back to environment variable
-			host = os.Getenv("SPLUNK_HOST")
-		}
+func mcpServerCommand() *command {
+	var opts mcpServerOptions
+	var mcpClient *string
+	return &command{
+		name:    "mcp-server",
+		aliases: []string{"mcp"},
+		short:   "Start MCP server (stdio or HTTP transport)",
+		long: "Start an MCP server on stdio, exposing Splunk search as a tool.\n" +
+			"With --transport http, serve the streamable HTTP transport at /mcp on --listen instead, so several MCP clients can share\n" +
+			"the server. Set SPLUNK_MCP_TOKEN to require clients to send it as a bearer token.\n" +
+			"With --read-only, only read-only tools are registered and searches that write, e.g. with collect or outputlookup, are refused.\n" +
+			"Searches that use commands that send data out of Splunk, run scripts or read its configuration, e.g. sendemail, script or rest,\n" +
+			"are refused unless --allow-spl allows them, as an agent may be told to run them by text it read, e.g. in events.\n" +
+			"On shutdown, in-flight requests are given the grace period to finish before their search jobs are cancelled.",
+		flags: func(flags *flag.FlagSet) {
+			flags.DurationVar(&opts.gracePeriod, "grace-period", 30*time.Second, "time to let in-flight requests finish on shutdown before cancelling their search jobs")
+			flags.StringVar(&opts.transport, "transport", "stdio", "transport to serve: stdio or http")
+			flags.StringVar(&opts.listen, "listen", "localhost:8080", "address to listen on with --transport http, e.g. :8080 for every interface")
+			flags.StringVar(&opts.tlsCert, "tls-cert", "", "PEM certificate to serve --transport http over TLS with, e.g. from a Kubernetes secret (default: plain HTTP)")
+			flags.StringVar(&opts.tlsKey, "tls-key", "", "PEM key of the --tls-cert certificate")
+			flags.StringVar(&opts.allowOrigins, "allow-origins", "", "comma-separated list of the browser origins that may call --transport http, e.g. https://app.example.com, or * for any (default: only pages on localhost)")
+			flags.DurationVar(&opts.search.timeout, "search-timeout", 60*time.Second, "how long the search tool waits for a search before returning its partial results (calls may override it)")
+			flags.DurationVar(&opts.search.pollInterval, "poll-interval", 5*time.Second, "longest delay between the search tool's polls of a job's status (calls may override it)")
+			flags.StringVar(&opts.adminListen, "admin-listen", "", "address to serve /healthz, /readyz and Prometheus /metrics on, e.g. :9090 (default: none)")
+			flags.BoolVar(&opts.readOnly, "read-only", false, "only register read-only tools, and refuse searches that write to indexes, lookups or files")
+			flags.StringVar(&opts.allowTools, "tools", "", "comma-separated list of the only tools to register, e.g. search,list_indexes,field_summary (default: all)")
+			flags.StringVar(&opts.excludeTools, "exclude-tools", "", "comma-separated list of tools not to register, e.g. run_saved_search")
+			flags.StringVar(&opts.allowSPL, "allow-spl", "", "comma-separated list of SPL commands to allow in searches that are blocked by default: "+strings.Join(sortedKeys(riskyCommands), ", "))
+		},
+		flagValues: map[string]func() []string{"transport": staticValues("stdio", "http")},
+		run: func(ctx context.Context, args []string) error {
+			return runMCPServer(ctx, opts)
+		},
+		subcommands: []*command{{
+			name:  "install",
+			short: "Register the MCP server in an MCP client's config",
+			flags: func(flags *flag.FlagSet) {
+				mcpClient = flags.String("client", "", "MCP client to configure: claude, cursor or vscode")
+			},
+			flagValues: map[string]func() []string{"client": staticValues("claude", "cursor", "vscode")},
+			run: func(ctx context.Context, args []string) error {
+				if *mcpClient == "" {
+					return fmt.Errorf("usage: splunk mcp-server install --client claude|cursor|vscode")
+				}
+				path, err := installMCPServer(*mcpClient)
+				if err != nil {
+					return err
+				}
+				slog.Info("Registered the splunk MCP server", "path", path)
+				return nil
+			},
+		}},
 	}
+}
 
-	// Load token from keyring, or fall back to env var
-	if token == "" {
-		token = os.Getenv("SPLUNK_TOKEN")
+func lspCommand() *command {
+	return &command{
+		name:  "lsp",
+		short: "Start editor integration server (JSON-RPC over stdio)",
+		run: func(ctx context.Context, args []string) error {
+			return executeCommand(ctx, runLSPServer)
+		},
 	}
-	if token == "" {
-		var err error
-		token, err = config.LoadToken(host)
-		if err != nil {
-			return err
-		}
+}
+
+func executeCommand(ctx context.Context, fn func(context.Context) error) error {
+	source, err := newClientSource()
+	if err != nil {
+		return err
 	}
 
-	if host == "" {
-		return fmt.Errorf("host is required")
+	client, err = source.Get()
+	if err != nil {
+		return err
 	}
-	if token == "" {
-		return fmt.Errorf("token is required")
+	client.OnWarning = printWarning
+	return withTimeout(ctx, fn)
+}
+
+// withTimeout runs fn with the -timeout deadline, if there is one
+func withTimeout(ctx context.Context, fn func(context.Context) error) error {
+	if commandTimeout <= 0 {
+		return fn(ctx)
 	}
+	ctx, cancel := context.WithTimeout(ctx, commandTimeout)
+	defer cancel()
+	err := fn(ctx)
+	if err != nil && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return withExitCode(exitTimeout, fmt.Errorf("timed out after %s (use -timeout to allow longer): %w", commandTimeout, err))
+	}
+	return err
+}
 
-	client = splunk.NewClient(host, token)
-	return fn(ctx)
+// printWarning prints a warning Splunk reports about a search, so users know when results are partial
+func printWarning(sid string, message splunk.Message) {
+	if sid == "" {
+		slog.Warn(redact(message.Text))
+		return
+	}
+	slog.Warn(redact(message.Text), "sid", sid)
 }
 
-func runSearch(ctx context.Context, query string, earliestTime, latestTime string) error {
-	// Ensure query starts with "search" if not already present
+// ensureSearchCommand prefixes the query with "search" unless it already starts with a command
+func ensureSearchCommand(query string) string {
 	if !strings.HasPrefix(strings.TrimSpace(query), "search") && !strings.HasPrefix(strings.TrimSpace(query), "|") {
-		query = "search " + query
+		return "search " + query
 	}
+	return query
+}
 
-	fmt.Printf("Running search: %s\n", query)
+// configure reads the token (or password, with basic auth) from stdin and saves it to the keyring
+func configure(host string, port int, scheme, auth, username string) error {
+	if host == "" {
+		return fmt.Errorf("host is required")
+	}
 
-	// Create search job
-	sid, err := client.RunSearch(ctx, query, earliestTime, latestTime)
+	// Check the config file before prompting, so a broken one isn't replaced
+	cfg, err := loadConfig()
 	if err != nil {
-		return fmt.Errorf("failed to run search: %w", err)
+		return err
 	}
 
-	fmt.Printf("Search job created: %s\n", sid)
-
-	// Poll for completion
-	for {
-		status, err := client.GetSearchStatus(ctx, sid)
-		if err != nil {
-			return fmt.Errorf("failed to get search status: %w", err)
-		}
-
-		if status.Content.IsDone {
-			fmt.Printf("Search completed. Found %d results.\n\n", status.Content.ResultCount)
-			break
+	var token string
+	switch auth {
+	case "token":
+		fmt.Fprintf(os.Stderr, "To create an authentication token in Splunk:\n")
+		fmt.Fprintf(os.Stderr, "1. Log in to your Splunk instance at https://%s:8000\n", host)
+		fmt.Fprintf(os.Stderr, "2. Go to Settings > Tokens\n")
+		fmt.Fprintf(os.Stderr, "3. Click 'New Token' and generate a token\n")
+		fmt.Fprintf(os.Stderr, "The token will be stored securely in your system's keyring.\n")
+		fmt.Fprintf(os.Stderr, "\n")
+		token, err = readSecret("API token")
+	case "basic":
+		if username == "" {
+			return fmt.Errorf("--username is required for basic auth")
 		}
-
-		fmt.Printf("Search in progress (%s)...\n", status.Content.DispatchState)
-		time.Sleep(2 * time.Second)
+		fmt.Fprintf(os.Stderr, "The password will be stored securely in your system's keyring, and used to log in for a session key.\n\n")
+		token, err = readSecret("password for " + username)
+	default:
+		return fmt.Errorf("unknown auth %q (must be token or basic)", auth)
 	}
-
-	// Get results
-	results, err := client.GetSearchResults(ctx, sid, 100)
 	if err != nil {
-		return fmt.Errorf("failed to get search results: %w", err)
+		return err
 	}
 
-	// Display results
-	for i, result := range results.Results {
-		fmt.Printf("Result %d:\n", i+1)
-		for key, value := range result {
-			fmt.Printf("  %s: %v\n", key, value)
-		}
-		fmt.Println()
+	// Save host to the selected profile of the config file, keeping any other settings
+	p := cfg.Ensure(profileName())
+	p.Host, p.Port, p.Scheme = host, port, scheme
+	p.Auth, p.Username = "", ""
+	if auth == "basic" {
+		p.Auth, p.Username = auth, username
+	}
+	if clientCert != "" {
+		p.ClientCert, p.ClientKey = clientCert, clientKey
+	}
+	if caCert != "" {
+		p.CACert = caCert
+	}
+	if insecure != nil {
+		p.Insecure = *insecure
+	}
+	if proxyURL != "" {
+		p.Proxy = proxyURL
+	}
+	if err := config.SaveConfig(cfg); err != nil {
+		return err
+	}
+
+	// Save token or password to keyring
+	if err := config.SaveToken(profileName(), host, token); err != nil {
+		return err
+	}
+	if auth == "token" {
+		// Track when the token expires, to remind users to rotate it
+		_ = config.SaveTokenInfo(profileName(), host, newTokenInfo(token, time.Now()))
 	}
 
+	slog.Info("Configuration saved successfully", "host", host, profileAttr())
 	return nil
 }
 
-// configure reads the token from stdin and saves it to the keyring
-func configure(host string) error {
-	if host == "" {
-		return fmt.Errorf("host is required")
+// configureHEC saves an HTTP Event Collector token for the host, and the host too if the profile doesn't have one
+func configureHEC(host string, port int, scheme, hecURL string) error {
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
 	}
 
-	fmt.Fprintf(os.Stderr, "To create an authentication token in Splunk:\n")
-	fmt.Fprintf(os.Stderr, "1. Log in to your Splunk instance at https://%s:8000\n", host)
-	fmt.Fprintf(os.Stderr, "2. Go to Settings > Tokens\n")
-	fmt.Fprintf(os.Stderr, "3. Click 'New Token' and generate a token\n")
-	fmt.Fprintf(os.Stderr, "The token will be stored securely in your system's keyring.\n")
-	fmt.Fprintf(os.Stderr, "\nEnter Splunk API token: ")
-
-	// Read password with hidden input
-	tokenBytes, err := term.ReadPassword(int(syscall.Stdin))
-	fmt.Fprintln(os.Stderr) // Print newline after hidden input
+	fmt.Fprintf(os.Stderr, "Create an HEC token in Splunk under Settings > Data inputs > HTTP Event Collector.\n")
+	fmt.Fprintf(os.Stderr, "The token will be stored securely in your system's keyring.\n\n")
+	token, err := readSecret("HEC token")
 	if err != nil {
-		return fmt.Errorf("failed to read token: %w", err)
+		return err
 	}
 
-	token := string(tokenBytes)
-	if token == "" {
-		return fmt.Errorf("token cannot be empty")
+	p := cfg.Ensure(profileName())
+	if p.Host == "" {
+		p.Host, p.Port, p.Scheme = host, port, scheme
+	} else if p.Host != host {
+		return fmt.Errorf("the profile's host is %s, not %s (configure the host first, or use another -profile)", p.Host, host)
 	}
-
-	// Save host to config file
-	if err := config.SaveConfig(host); err != nil {
+	if hecURL != "" {
+		p.HECURL = hecURL
+	}
+	if err := config.SaveConfig(cfg); err != nil {
 		return err
 	}
-
-	// Save token to keyring
-	if err := config.SaveToken(host, token); err != nil {
+	if err := config.SaveHECToken(profileName(), host, token); err != nil {
 		return err
 	}
 
-	fmt.Fprintf(os.Stderr, "Configuration saved successfully for host: %s\n", host)
+	slog.Info("HEC token saved successfully", "host", host, profileAttr())
 	return nil
 }
+
+// readSecret prompts for a secret, such as the API token, and reads it with hidden input, or reads
+// it from stdin if that's piped rather than a terminal
+func readSecret(name string) (string, error) {
+	var secretBytes []byte
+	var err error
+	if term.IsTerminal(int(syscall.Stdin)) {
+		fmt.Fprintf(os.Stderr, "Enter Splunk %s: ", name)
+		secretBytes, err = term.ReadPassword(int(syscall.Stdin))
+		fmt.Fprintln(os.Stderr) // Print newline after hidden input
+	} else {
+		secretBytes, err = io.ReadAll(os.Stdin)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to read %s: %w", name, err)
+	}
+
+	secret := strings.TrimSuffix(strings.TrimSuffix(string(secretBytes), "\n"), "\r")
+	if secret == "" {
+		return "", fmt.Errorf("%s cannot be empty", name)
+	}
+	return secret, nil
+}
@@ -2,9 +2,17 @@ package main
 
 import (
 	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/kitproj/splunk-cli/pkg/splunk"
+	"github.com/kitproj/splunk-cli/pkg/splunk/splunktest"
 	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
 )
 
 func TestSearchHandlerRequiresQuery(t *testing.T) {
@@ -18,7 +26,7 @@ func TestSearchHandlerRequiresQuery(t *testing.T) {
 		},
 	}
 
-	result, err := searchHandler(context.Background(), nil, request)
+	result, err := searchHandler(context.Background(), nil, request, searchToolOptions{timeout: time.Minute, pollInterval: 5 * time.Second})
 	if err != nil {
 		t.Fatalf("Expected no error, got: %v", err)
 	}
@@ -27,3 +35,389 @@ func TestSearchHandlerRequiresQuery(t *testing.T) {
 		t.Error("Expected error result when query is missing")
 	}
 }
+
+func TestSearchHandlerTimeout(t *testing.T) {
+	cancelled := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/services/search/jobs":
+			w.Write([]byte(`{"sid": "1234.5"}`))
+		case "/services/search/jobs/1234.5":
+			w.Write([]byte(`{"sid": "1234.5", "content": {"dispatchState": "RUNNING", "isDone": false}}`))
+		case "/services/search/jobs/1234.5/results_preview":
+			w.Write([]byte(`{"results": [{"host": "web-1", "count": "3"}]}`))
+		case "/services/search/jobs/1234.5/control":
+			cancelled = r.FormValue("action") == "cancel"
+			w.Write([]byte(`{}`))
+		default:
+			t.Errorf("Unexpected request: %s", r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	client := splunk.NewClient("localhost", "test-token")
+	client.BaseURL = server.URL
+
+	request := mcp.CallToolRequest{
+		Params: mcp.CallToolParams{
+			Name:      "search",
+			Arguments: map[string]interface{}{"query": "index=main | stats count by host", "timeout": "100ms"},
+		},
+	}
+	result, err := searchHandler(context.Background(), client, request, searchToolOptions{timeout: time.Minute, pollInterval: 10 * time.Millisecond})
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if result.IsError {
+		t.Fatalf("Expected the preview results, got an error: %v", result.Content)
+	}
+	structured := result.StructuredContent.(map[string]interface{})
+	if structured["timed_out"] != true {
+		t.Errorf("Expected timed_out to be set, got: %v", structured)
+	}
+	if rows := structured["results"].([]map[string]interface{}); len(rows) != 1 || rows[0]["host"] != "web-1" {
+		t.Errorf("Expected the preview result, got: %v", rows)
+	}
+	if !cancelled {
+		t.Error("Expected the timed out job to be cancelled")
+	}
+}
+
+func TestRunSavedSearchHandler(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/services/saved/searches/Daily Errors/dispatch":
+			if r.FormValue("dispatch.earliest_time") != "-7d" {
+				t.Errorf("Expected the earliest time to be overridden, got: %q", r.FormValue("dispatch.earliest_time"))
+			}
+			w.Write([]byte(`{"sid": "scheduler_1234"}`))
+		case "/services/search/jobs/scheduler_1234":
+			w.Write([]byte(`{"sid": "scheduler_1234", "content": {"isDone": true, "resultCount": 1}}`))
+		case "/services/search/jobs/scheduler_1234/results":
+			w.Write([]byte(`{"results": [{"host": "web-1", "count": "42"}]}`))
+		default:
+			t.Errorf("Unexpected request: %s", r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	client := splunk.NewClient("localhost", "test-token")
+	client.BaseURL = server.URL
+
+	request := mcp.CallToolRequest{
+		Params: mcp.CallToolParams{
+			Name:      "run_saved_search",
+			Arguments: map[string]interface{}{"name": "Daily Errors", "earliest_time": "-7d"},
+		},
+	}
+	result, err := runSavedSearchHandler(context.Background(), client, request, searchToolOptions{timeout: time.Minute, pollInterval: 10 * time.Millisecond})
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if result.IsError {
+		t.Fatalf("Expected results, got an error: %v", result.Content)
+	}
+	text := result.Content[0].(mcp.TextContent).Text
+	if !strings.Contains(text, `"host":"web-1"`) {
+		t.Errorf("Expected the results in the text, got: %s", text)
+	}
+	structured := result.StructuredContent.(map[string]interface{})
+	if sid := structured["provenance"].(provenance).SID; sid != "scheduler_1234" {
+		t.Errorf("Expected the provenance of the dispatched job, got: %s", sid)
+	}
+}
+
+func TestListSavedSearchesHandler(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"entry":[
+			{"name":"daily_errors","content":{"search":"index=main error | stats count by host","description":"Errors by host","is_scheduled":true,"cron_schedule":"0 6 * * *"}},
+			{"name":"logins","content":{"search":"index=auth action=success"}}
+		]}`))
+	}))
+	defer server.Close()
+	client := splunk.NewClient("localhost", "test-token")
+	client.BaseURL = server.URL
+
+	request := mcp.CallToolRequest{Params: mcp.CallToolParams{Arguments: map[string]interface{}{"filter": "ERROR"}}}
+	result, err := listSavedSearchesHandler(context.Background(), client, request)
+	if err != nil || result.IsError {
+		t.Fatalf("Expected no error, got: %v %+v", err, result)
+	}
+	text := result.Content[0].(mcp.TextContent).Text
+	if !strings.Contains(text, "daily_errors") || !strings.Contains(text, "schedule: 0 6 * * *") || strings.Contains(text, "logins") {
+		t.Errorf("Expected only the matching saved search, got: %s", text)
+	}
+}
+
+func TestListIndexesHandler(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"entry":[
+			{"name":"_internal","content":{"totalEventCount":99}},
+			{"name":"main","content":{"datatype":"event","totalEventCount":1234,"frozenTimePeriodInSecs":7776000}},
+			{"name":"old","content":{"disabled":true}}
+		]}`))
+	}))
+	defer server.Close()
+	client := splunk.NewClient("localhost", "test-token")
+	client.BaseURL = server.URL
+
+	result, err := listIndexesHandler(context.Background(), client, mcp.CallToolRequest{})
+	if err != nil || result.IsError {
+		t.Fatalf("Expected no error, got: %v %+v", err, result)
+	}
+	text := result.Content[0].(mcp.TextContent).Text
+	if !strings.Contains(text, "main:") || !strings.Contains(text, "events: 1234") || !strings.Contains(text, "retention: 90 days") ||
+		strings.Contains(text, "_internal") || strings.Contains(text, "old") {
+		t.Errorf("Expected only the main index, got: %s", text)
+	}
+}
+
+func TestServerInfoHandler(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/services/server/info":
+			w.Write([]byte(`{"entry":[{"content":{"serverName":"idx1","version":"9.1.2","licenseState":"OK","server_roles":["indexer","search_head"]}}]}`))
+		default:
+			w.WriteHeader(http.StatusForbidden)
+		}
+	}))
+	defer server.Close()
+	client := splunk.NewClient("localhost", "test-token")
+	client.BaseURL = server.URL
+
+	result, err := serverInfoHandler(context.Background(), client, mcp.CallToolRequest{})
+	if err != nil || result.IsError {
+		t.Fatalf("Expected no error, got: %v %+v", err, result)
+	}
+	text := result.Content[0].(mcp.TextContent).Text
+	if !strings.Contains(text, "version: 9.1.2") || !strings.Contains(text, "roles: indexer, search_head") || !strings.Contains(text, "health: unknown") {
+		t.Errorf("Expected the server info without health, got: %s", text)
+	}
+}
+
+func TestFieldSummaryHandler(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/services/search/jobs":
+			if want := `search index="web" sourcetype="access" | fieldsummary maxvals=5`; r.FormValue("search") != want {
+				t.Errorf("Expected search %s, got: %s", want, r.FormValue("search"))
+			}
+			w.Write([]byte(`{"sid": "1234.5"}`))
+		case "/services/search/jobs/1234.5":
+			w.Write([]byte(`{"sid": "1234.5", "content": {"isDone": true, "eventCount": 200}}`))
+		case "/services/search/jobs/1234.5/results":
+			w.Write([]byte(`{"results": [
+				{"field": "user", "count": "50", "distinct_count": "12", "is_exact": "1", "values": "[{\"value\":\"alice\",\"count\":30}]"},
+				{"field": "status", "count": "200", "distinct_count": "3", "is_exact": "1", "values": "[{\"value\":\"200\",\"count\":150},{\"value\":\"404\",\"count\":50}]"}
+			]}`))
+		default:
+			t.Errorf("Unexpected request: %s", r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	client := splunk.NewClient("localhost", "test-token")
+	client.BaseURL = server.URL
+
+	request := mcp.CallToolRequest{
+		Params: mcp.CallToolParams{
+			Name:      "field_summary",
+			Arguments: map[string]interface{}{"index": "web", "sourcetype": "access", "max_fields": 1},
+		},
+	}
+	result, err := fieldSummaryHandler(context.Background(), client, request, searchToolOptions{timeout: time.Minute, pollInterval: time.Second})
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	text := result.Content[0].(mcp.TextContent).Text
+	if want := "status: in 100.0% of events, 3 distinct value(s), most common: 200, 404"; !strings.Contains(text, want) {
+		t.Errorf("Expected %q in the text, got: %s", want, text)
+	}
+	if strings.Contains(text, "user:") {
+		t.Errorf("Expected only the most common field, got: %s", text)
+	}
+}
+
+func TestFiredAlertsHandler(t *testing.T) {
+	recent, old := time.Now().Add(-time.Hour).Unix(), time.Now().Add(-48*time.Hour).Unix()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"entry":[
+			{"content":{"savedsearch_name":"errors","sid":"rt_1","trigger_time":%d,"severity":5}},
+			{"content":{"savedsearch_name":"errors","sid":"rt_0","trigger_time":%d,"severity":3}}
+		]}`, recent, old)
+	}))
+	defer server.Close()
+	client := splunk.NewClient("localhost", "test-token")
+	client.BaseURL = server.URL
+
+	result, err := firedAlertsHandler(context.Background(), client, mcp.CallToolRequest{})
+	if err != nil || result.IsError {
+		t.Fatalf("Expected no error, got: %v %+v", err, result)
+	}
+	text := result.Content[0].(mcp.TextContent).Text
+	if !strings.Contains(text, "errors (severity: severe, sid: rt_1)") || strings.Contains(text, "rt_0") {
+		t.Errorf("Expected only the trigger of the last day, got: %s", text)
+	}
+}
+
+func TestOrderedJSON(t *testing.T) {
+	results := []map[string]interface{}{
+		{"status": "500", "_time": "2024-01-01T00:00:00", "_raw": "GET /"},
+		{"host": []interface{}{"web1", "web2"}, "status": "200"},
+	}
+	fields := resultFields(results)
+	if strings.Join(fields, ",") != "_time,host,status,_raw" {
+		t.Errorf("Unexpected field order: %v", fields)
+	}
+
+	data, err := orderedJSON(fields, results)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	expected := "[\n" + `{"_time":"2024-01-01T00:00:00","status":"500","_raw":"GET /"},` + "\n" + `{"host":["web1","web2"],"status":"200"}` + "\n]\n"
+	if string(data) != expected {
+		t.Errorf("Expected %s, got: %s", expected, data)
+	}
+}
+
+func TestBearerAuth(t *testing.T) {
+	handler := bearerAuth("secret", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	for header, expected := range map[string]int{
+		"":              http.StatusUnauthorized,
+		"Bearer wrong":  http.StatusUnauthorized,
+		"Basic secret":  http.StatusUnauthorized,
+		"Bearer secret": http.StatusOK,
+	} {
+		request := httptest.NewRequest("POST", "/mcp", nil)
+		if header != "" {
+			request.Header.Set("Authorization", header)
+		}
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, request)
+		if recorder.Code != expected {
+			t.Errorf("Expected %d for %q, got: %d", expected, header, recorder.Code)
+		}
+	}
+}
+
+func TestCheckOrigin(t *testing.T) {
+	for _, tt := range []struct {
+		origin  string
+		allowed []string
+		want    int
+	}{
+		{"", nil, http.StatusOK},
+		{"http://localhost:3000", nil, http.StatusOK},
+		{"http://127.0.0.1", nil, http.StatusOK},
+		{"http://evil.example.com", nil, http.StatusForbidden},
+		{"https://app.example.com", []string{"https://app.example.com/"}, http.StatusOK},
+		{"http://localhost:3000", []string{"https://app.example.com"}, http.StatusForbidden},
+		{"http://evil.example.com", []string{"*"}, http.StatusOK},
+	} {
+		handler := checkOrigin(tt.allowed, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+		request := httptest.NewRequest("POST", "/mcp", nil)
+		if tt.origin != "" {
+			request.Header.Set("Origin", tt.origin)
+		}
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, request)
+		if recorder.Code != tt.want {
+			t.Errorf("Expected %d for origin %q with %v allowed, got: %d", tt.want, tt.origin, tt.allowed, recorder.Code)
+		}
+	}
+}
+
+func TestAllowedTools(t *testing.T) {
+	tools := []server.ServerTool{
+		{Tool: mcp.NewTool("search")},
+		{Tool: mcp.NewTool("list_indexes", mcp.WithReadOnlyHintAnnotation(true))},
+		{Tool: mcp.NewTool("server_info", mcp.WithReadOnlyHintAnnotation(true))},
+	}
+	names := func(tools []server.ServerTool) string {
+		var names []string
+		for _, tool := range tools {
+			names = append(names, tool.Tool.Name)
+		}
+		return strings.Join(names, ",")
+	}
+
+	for _, tt := range []struct {
+		readOnly bool
+		allow    string
+		exclude  string
+		want     string
+	}{
+		{false, "", "", "search,list_indexes,server_info"},
+		{true, "", "", "list_indexes,server_info"},
+		{false, "search, list_indexes", "", "search,list_indexes"},
+		{true, "search,list_indexes", "", "list_indexes"},
+		{false, "", "search", "list_indexes,server_info"},
+		{false, "search,list_indexes", "list_indexes", "search"},
+	} {
+		allowed, err := allowedTools(tools, tt.readOnly, tt.allow, tt.exclude)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if got := names(allowed); got != tt.want {
+			t.Errorf("Expected %s with read-only %v, %q allowed and %q excluded, got: %s", tt.want, tt.readOnly, tt.allow, tt.exclude, got)
+		}
+	}
+
+	if _, err := allowedTools(tools, false, "search,delete_index", ""); err == nil {
+		t.Error("Expected an error for an unknown tool")
+	}
+	if _, err := allowedTools(tools, false, "", "delete_index"); err == nil {
+		t.Error("Expected an error for an unknown excluded tool")
+	}
+	if _, err := allowedTools(tools, true, "search", ""); err == nil {
+		t.Error("Expected an error when no tools are allowed")
+	}
+}
+
+func TestSearchHandlerResults(t *testing.T) {
+	s := splunktest.NewServer(t)
+	s.AddJob("1234.5", map[string]interface{}{"host": "web-1", "count": "3"}, map[string]interface{}{"host": "web-2", "count": "5"})
+
+	request := mcp.CallToolRequest{
+		Params: mcp.CallToolParams{
+			Name:      "search",
+			Arguments: map[string]interface{}{"query": "index=main | stats count by host"},
+		},
+	}
+	result, err := searchHandler(context.Background(), s.Client(), request, searchToolOptions{timeout: time.Minute, pollInterval: 10 * time.Millisecond})
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if result.IsError {
+		t.Fatalf("Expected results, got an error: %v", result.Content)
+	}
+	structured := result.StructuredContent.(map[string]interface{})
+	if rows := structured["results"].([]map[string]interface{}); len(rows) != 2 || rows[1]["host"] != "web-2" {
+		t.Errorf("Expected both results, got: %v", rows)
+	}
+	if p := structured["provenance"].(provenance); p.SID != "1234.5" || p.URL != s.URL {
+		t.Errorf("Expected the job's provenance, got: %+v", p)
+	}
+}
+
+func TestHandlersWithFake(t *testing.T) {
+	fake := &splunktest.Fake{
+		ListIndexesFunc: func(ctx context.Context) ([]splunk.Index, error) {
+			return []splunk.Index{{Name: "main", TotalEventCount: 42}, {Name: "_internal"}, {Name: "old", Disabled: true}}, nil
+		},
+	}
+	result, err := listIndexesHandler(context.Background(), fake, mcp.CallToolRequest{})
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	indexes := result.StructuredContent.(map[string]interface{})["indexes"].([]splunk.Index)
+	if len(indexes) != 1 || indexes[0].Name != "main" {
+		t.Errorf("Expected only the enabled, non-internal index, got: %v", indexes)
+	}
+
+	// Methods the fake doesn't stub fail, so a handler's errors can be tested too
+	result, err = serverInfoHandler(context.Background(), fake, mcp.CallToolRequest{})
+	if err != nil || !result.IsError {
+		t.Errorf("Expected an error result, got %v and: %v", result, err)
+	}
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+)
+
+// mcpClientConfigPath returns the path of an MCP client's config file
+func mcpClientConfigPath(mcpClient string) (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+
+	switch mcpClient {
+	case "claude":
+		switch runtime.GOOS {
+		case "darwin":
+			return filepath.Join(home, "Library", "Application Support", "Claude", "claude_desktop_config.json"), nil
+		case "windows":
+			return filepath.Join(os.Getenv("APPDATA"), "Claude", "claude_desktop_config.json"), nil
+		default:
+			return filepath.Join(home, ".config", "Claude", "claude_desktop_config.json"), nil
+		}
+	case "cursor":
+		return filepath.Join(home, ".cursor", "mcp.json"), nil
+	case "vscode":
+		switch runtime.GOOS {
+		case "darwin":
+			return filepath.Join(home, "Library", "Application Support", "Code", "User", "mcp.json"), nil
+		case "windows":
+			return filepath.Join(os.Getenv("APPDATA"), "Code", "User", "mcp.json"), nil
+		default:
+			return filepath.Join(home, ".config", "Code", "User", "mcp.json"), nil
+		}
+	default:
+		return "", fmt.Errorf("unknown MCP client: %s", mcpClient)
+	}
+}
+
+// installMCPServer registers this binary as the "splunk" server in an MCP
+// client's config file, keeping any other settings and servers, and returns the file's path.
+// A named profile is registered as its own server, e.g. "splunk-prod".
+func installMCPServer(mcpClient string) (string, error) {
+	path, err := mcpClientConfigPath(mcpClient)
+	if err != nil {
+		return "", err
+	}
+
+	executable, err := os.Executable()
+	if err != nil {
+		return "", fmt.Errorf("failed to get executable path: %w", err)
+	}
+
+	settings := map[string]interface{}{}
+	data, err := os.ReadFile(path)
+	if err == nil {
+		if err := json.Unmarshal(data, &settings); err != nil {
+			return "", fmt.Errorf("failed to parse %s: %w", path, err)
+		}
+	} else if !os.IsNotExist(err) {
+		return "", fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	// VS Code keeps its servers under a different key, and needs the transport type
+	key := "mcpServers"
+	name, args := "splunk", []string{"mcp-server"}
+	if p := profileName(); p != "" {
+		name, args = "splunk-"+p, []string{"-profile", p, "mcp-server"}
+	}
+	server := map[string]interface{}{
+		"command": executable,
+		"args":    args,
+	}
+	if mcpClient == "vscode" {
+		key = "servers"
+		server["type"] = "stdio"
+	}
+
+	servers, _ := settings[key].(map[string]interface{})
+	if servers == nil {
+		servers = map[string]interface{}{}
+	}
+	servers[name] = server
+	settings[key] = servers
+
+	data, err = json.MarshalIndent(settings, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal %s: %w", path, err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return "", fmt.Errorf("failed to create config directory: %w", err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0600); err != nil {
+		return "", fmt.Errorf("failed to write %s: %w", path, err)
+	}
+
+	return path, nil
+}
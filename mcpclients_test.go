@@ -0,0 +1,50 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func TestInstallMCPServerKeepsOtherServers(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("config paths are only redirected through HOME on Linux")
+	}
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	path := filepath.Join(home, ".cursor", "mcp.json")
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(`{"mcpServers":{"other":{"command":"other"}}}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := installMCPServer("cursor"); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var settings struct {
+		MCPServers map[string]struct {
+			Command string   `json:"command"`
+			Args    []string `json:"args"`
+		} `json:"mcpServers"`
+	}
+	if err := json.Unmarshal(data, &settings); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok := settings.MCPServers["other"]; !ok {
+		t.Error("Expected existing server to be kept")
+	}
+	if args := settings.MCPServers["splunk"].Args; len(args) != 1 || args[0] != "mcp-server" {
+		t.Errorf("Expected splunk server with mcp-server args, got: %v", args)
+	}
+}
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kitproj/splunk-cli/pkg/splunk"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+const (
+	// maxCompletions is the most values a completion may return, per the MCP spec
+	maxCompletions = 100
+	// sourcetypesTTL is how long sourcetypes are cached for, since listing them searches the
+	// metadata of every index, and clients ask for completions as the user types
+	sourcetypesTTL = 5 * time.Minute
+)
+
+// relativeTimes are the time modifiers offered for earliest_time and latest_time arguments
+var relativeTimes = map[string][]string{
+	"earliest_time": {"-15m", "-1h", "-4h", "-24h", "-7d", "-30d", "@d", "-1d@d", "@w0", "0"},
+	"latest_time":   {"now", "-15m", "-1h", "@d", "-1d@d", "@w0"},
+}
+
+// argumentCompleter completes the arguments of prompts and resource templates by their names, so
+// clients can offer valid indexes, sourcetypes, saved searches and times rather than free text
+type argumentCompleter struct {
+	clients *clientSource
+
+	mu                 sync.Mutex
+	sourcetypes        []string
+	sourcetypesFetched time.Time
+}
+
+func (c *argumentCompleter) CompletePromptArgument(ctx context.Context, promptName string, argument mcp.CompleteArgument, _ mcp.CompleteContext) (*mcp.Completion, error) {
+	return c.complete(ctx, argument)
+}
+
+func (c *argumentCompleter) CompleteResourceArgument(ctx context.Context, uri string, argument mcp.CompleteArgument, _ mcp.CompleteContext) (*mcp.Completion, error) {
+	return c.complete(ctx, argument)
+}
+
+func (c *argumentCompleter) complete(ctx context.Context, argument mcp.CompleteArgument) (*mcp.Completion, error) {
+	// Times don't depend on the server, so they're offered even without credentials
+	if times, ok := relativeTimes[argument.Name]; ok {
+		return completion(times, argument.Value), nil
+	}
+	api, err := c.clients.Get()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Splunk client: %w", err)
+	}
+	var values []string
+	if argument.Name == "sourcetype" {
+		values, err = c.cachedSourcetypes(ctx, api)
+	} else {
+		values, err = argumentValues(ctx, api, argument.Name)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return completion(values, argument.Value), nil
+}
+
+// cachedSourcetypes returns the sourcetypes, listing them at most once every sourcetypesTTL. The
+// lock is held while they're listed, so completions asked for at once share the search.
+func (c *argumentCompleter) cachedSourcetypes(ctx context.Context, api splunk.API) ([]string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.sourcetypes != nil && time.Since(c.sourcetypesFetched) < sourcetypesTTL {
+		return c.sourcetypes, nil
+	}
+	values, err := argumentValues(ctx, api, "sourcetype")
+	if err != nil {
+		return nil, err
+	}
+	if values == nil {
+		values = []string{}
+	}
+	c.sourcetypes, c.sourcetypesFetched = values, time.Now()
+	return values, nil
+}
+
+// argumentValues returns the values an argument may take, or none if they aren't known
+func argumentValues(ctx context.Context, api splunk.API, name string) ([]string, error) {
+	var values []string
+	switch name {
+	case "index":
+		indexes, err := api.ListIndexes(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list indexes: %w", err)
+		}
+		for _, index := range indexes {
+			if !index.Disabled {
+				values = append(values, index.Name)
+			}
+		}
+	case "sourcetype":
+		results, err := api.OneshotSearch(ctx, "| metadata type=sourcetypes index=* | fields sourcetype", splunk.SearchOptions{EarliestTime: "-7d"}, 10000)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list sourcetypes: %w", err)
+		}
+		for _, result := range results.Results {
+			if sourcetype, ok := result["sourcetype"].(string); ok {
+				values = append(values, sourcetype)
+			}
+		}
+	case "saved_search":
+		searches, err := api.ListSavedSearches(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list saved searches: %w", err)
+		}
+		for _, search := range searches {
+			values = append(values, search.Name)
+		}
+	default:
+		return nil, nil
+	}
+	sort.Strings(values)
+	return values, nil
+}
+
+// completion returns the values that start with the prefix, ignoring case, up to the most a
+// completion may return
+func completion(values []string, prefix string) *mcp.Completion {
+	matches := []string{}
+	for _, value := range values {
+		if strings.HasPrefix(strings.ToLower(value), strings.ToLower(prefix)) {
+			matches = append(matches, value)
+		}
+	}
+	result := &mcp.Completion{Values: matches, Total: len(matches)}
+	if len(matches) > maxCompletions {
+		result.Values, result.HasMore = matches[:maxCompletions], true
+	}
+	return result
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/kitproj/splunk-cli/pkg/splunk"
+	"github.com/kitproj/splunk-cli/pkg/splunk/splunktest"
+)
+
+func TestArgumentValues(t *testing.T) {
+	fake := &splunktest.Fake{
+		ListIndexesFunc: func(ctx context.Context) ([]splunk.Index, error) {
+			return []splunk.Index{{Name: "web"}, {Name: "main"}, {Name: "old", Disabled: true}}, nil
+		},
+		OneshotSearchFunc: func(ctx context.Context, searchQuery string, opts splunk.SearchOptions, count int) (*splunk.SearchResult, error) {
+			return &splunk.SearchResult{Results: []map[string]interface{}{{"sourcetype": "syslog"}, {"sourcetype": "access_combined"}}}, nil
+		},
+		ListSavedSearchesFunc: func(ctx context.Context) ([]splunk.SavedSearch, error) {
+			return nil, fmt.Errorf("forbidden")
+		},
+	}
+	tests := []struct {
+		name    string
+		want    []string
+		wantErr bool
+	}{
+		{"index", []string{"main", "web"}, false},
+		{"sourcetype", []string{"access_combined", "syslog"}, false},
+		{"saved_search", nil, true},
+		{"terms", nil, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := argumentValues(context.Background(), fake, tt.name)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Expected error %v, got: %v", tt.wantErr, err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestCompletion(t *testing.T) {
+	got := completion([]string{"Main", "metrics", "web"}, "m")
+	if !reflect.DeepEqual(got.Values, []string{"Main", "metrics"}) || got.Total != 2 || got.HasMore {
+		t.Errorf("Expected the values starting with m, ignoring case, got: %+v", got)
+	}
+
+	values := make([]string, maxCompletions+1)
+	for i := range values {
+		values[i] = fmt.Sprintf("index%03d", i)
+	}
+	got = completion(values, "")
+	if len(got.Values) != maxCompletions || got.Total != maxCompletions+1 || !got.HasMore {
+		t.Errorf("Expected %d of %d values, got %d of %d", maxCompletions, maxCompletions+1, len(got.Values), got.Total)
+	}
+}
+
+func TestCachedSourcetypes(t *testing.T) {
+	searches := 0
+	fake := &splunktest.Fake{
+		OneshotSearchFunc: func(ctx context.Context, searchQuery string, opts splunk.SearchOptions, count int) (*splunk.SearchResult, error) {
+			searches++
+			return &splunk.SearchResult{Results: []map[string]interface{}{{"sourcetype": "syslog"}}}, nil
+		},
+	}
+	c := &argumentCompleter{}
+	for range 2 {
+		got, err := c.cachedSourcetypes(context.Background(), fake)
+		if err != nil || !reflect.DeepEqual(got, []string{"syslog"}) {
+			t.Fatalf("Expected the sourcetypes, got: %v, %v", got, err)
+		}
+	}
+	if searches != 1 {
+		t.Errorf("Expected the sourcetypes to be listed once, got: %d", searches)
+	}
+
+	// They're listed again once they've expired
+	c.sourcetypesFetched = time.Now().Add(-sourcetypesTTL)
+	if _, err := c.cachedSourcetypes(context.Background(), fake); err != nil || searches != 2 {
+		t.Errorf("Expected the expired sourcetypes to be listed again, got: %d, %v", searches, err)
+	}
+}
@@ -0,0 +1,145 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// durationBuckets are the upper bounds, in seconds, of the tool call duration histogram
+var durationBuckets = []float64{0.1, 0.5, 1, 2.5, 5, 10, 30, 60, 120, 300}
+
+// serverMetrics counts the tool calls of the MCP server, for the admin endpoint's /metrics
+type serverMetrics struct {
+	mu       sync.Mutex
+	tools    map[string]*toolMetrics
+	inFlight int
+}
+
+// toolMetrics are the metrics of one tool
+type toolMetrics struct {
+	calls  int64
+	errors int64
+	// buckets counts the calls that took up to each of durationBuckets, and the last any longer
+	buckets []int64
+	seconds float64
+}
+
+func newServerMetrics() *serverMetrics {
+	return &serverMetrics{tools: map[string]*toolMetrics{}}
+}
+
+// toolMiddleware records the duration and outcome of each tool call. Calls that return an error
+// result, such as a failed search, count as errors as well as those that fail outright.
+func (m *serverMetrics) toolMiddleware(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		m.mu.Lock()
+		m.inFlight++
+		m.mu.Unlock()
+
+		start := time.Now()
+		result, err := next(ctx, request)
+		m.observe(request.Params.Name, time.Since(start), err != nil || (result != nil && result.IsError))
+		return result, err
+	}
+}
+
+func (m *serverMetrics) observe(tool string, d time.Duration, failed bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.inFlight--
+	t, ok := m.tools[tool]
+	if !ok {
+		t = &toolMetrics{buckets: make([]int64, len(durationBuckets)+1)}
+		m.tools[tool] = t
+	}
+	t.calls++
+	if failed {
+		t.errors++
+	}
+	t.seconds += d.Seconds()
+	t.buckets[sort.SearchFloat64s(durationBuckets, d.Seconds())]++
+}
+
+// write writes the metrics in the Prometheus text format
+func (m *serverMetrics) write(w io.Writer) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	names := make([]string, 0, len(m.tools))
+	for name := range m.tools {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	fmt.Fprintf(w, "# HELP splunk_mcp_tool_calls_total Tool calls of the MCP server.\n# TYPE splunk_mcp_tool_calls_total counter\n")
+	for _, name := range names {
+		fmt.Fprintf(w, "splunk_mcp_tool_calls_total{tool=%q} %d\n", name, m.tools[name].calls)
+	}
+	fmt.Fprintf(w, "# HELP splunk_mcp_tool_errors_total Tool calls that failed, including searches that did.\n# TYPE splunk_mcp_tool_errors_total counter\n")
+	for _, name := range names {
+		fmt.Fprintf(w, "splunk_mcp_tool_errors_total{tool=%q} %d\n", name, m.tools[name].errors)
+	}
+	fmt.Fprintf(w, "# HELP splunk_mcp_tool_duration_seconds Duration of tool calls, including the searches they run.\n# TYPE splunk_mcp_tool_duration_seconds histogram\n")
+	for _, name := range names {
+		t := m.tools[name]
+		var cumulative int64
+		for i, le := range durationBuckets {
+			cumulative += t.buckets[i]
+			fmt.Fprintf(w, "splunk_mcp_tool_duration_seconds_bucket{tool=%q,le=\"%g\"} %d\n", name, le, cumulative)
+		}
+		fmt.Fprintf(w, "splunk_mcp_tool_duration_seconds_bucket{tool=%q,le=\"+Inf\"} %d\n", name, t.calls)
+		fmt.Fprintf(w, "splunk_mcp_tool_duration_seconds_sum{tool=%q} %g\n", name, t.seconds)
+		fmt.Fprintf(w, "splunk_mcp_tool_duration_seconds_count{tool=%q} %d\n", name, t.calls)
+	}
+	fmt.Fprintf(w, "# HELP splunk_mcp_tool_calls_in_flight Tool calls in progress.\n# TYPE splunk_mcp_tool_calls_in_flight gauge\n")
+	fmt.Fprintf(w, "splunk_mcp_tool_calls_in_flight %d\n", m.inFlight)
+}
+
+// adminHandler serves /healthz, which succeeds while the process is up, /readyz, which succeeds
+// while ready returns no error, and /metrics
+func adminHandler(m *serverMetrics, ready func() error) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintln(w, "ok")
+	})
+	mux.HandleFunc("/readyz", func(w http.ResponseWriter, r *http.Request) {
+		if err := ready(); err != nil {
+			http.Error(w, err.Error(), http.StatusServiceUnavailable)
+			return
+		}
+		fmt.Fprintln(w, "ok")
+	})
+	mux.HandleFunc("/metrics", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+		m.write(w)
+	})
+	return mux
+}
+
+// serveAdmin serves the admin endpoints on addr until stop is called. It returns once it's listening,
+// so a port that's in use fails the server's startup.
+func serveAdmin(addr string, handler http.Handler) (stop func(), err error) {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen on %s: %w", addr, err)
+	}
+	srv := &http.Server{Handler: handler, ReadHeaderTimeout: 10 * time.Second}
+	slog.Info("Admin endpoints listening", "url", fmt.Sprintf("http://%s", listener.Addr()), "paths", "/healthz,/readyz,/metrics")
+
+	go func() {
+		if err := srv.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			slog.Warn("Admin endpoints stopped", "err", err)
+		}
+	}()
+	return func() { srv.Close() }, nil
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestAdminHandler(t *testing.T) {
+	m := newServerMetrics()
+	handler := m.toolMiddleware(func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		if request.GetString("query", "") == "" {
+			return mcp.NewToolResultError("Missing query"), nil
+		}
+		return mcp.NewToolResultText("ok"), nil
+	})
+	for _, query := range []string{"error", ""} {
+		request := mcp.CallToolRequest{Params: mcp.CallToolParams{Name: "search", Arguments: map[string]interface{}{"query": query}}}
+		if _, err := handler(context.Background(), request); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+
+	var notReady error
+	admin := adminHandler(m, func() error { return notReady })
+	get := func(path string) (int, string) {
+		recorder := httptest.NewRecorder()
+		admin.ServeHTTP(recorder, httptest.NewRequest("GET", path, nil))
+		body, _ := io.ReadAll(recorder.Body)
+		return recorder.Code, string(body)
+	}
+
+	_, metrics := get("/metrics")
+	for _, want := range []string{
+		`splunk_mcp_tool_calls_total{tool="search"} 2`,
+		`splunk_mcp_tool_errors_total{tool="search"} 1`,
+		`splunk_mcp_tool_duration_seconds_bucket{tool="search",le="0.1"} 2`,
+		`splunk_mcp_tool_calls_in_flight 0`,
+	} {
+		if !strings.Contains(metrics, want) {
+			t.Errorf("Expected %s in the metrics, got:\n%s", want, metrics)
+		}
+	}
+
+	if code, _ := get("/healthz"); code != 200 {
+		t.Errorf("Expected /healthz to succeed, got: %d", code)
+	}
+	if code, _ := get("/readyz"); code != 200 {
+		t.Errorf("Expected /readyz to succeed, got: %d", code)
+	}
+	notReady = fmt.Errorf("shutting down")
+	if code, body := get("/readyz"); code != 503 || !strings.Contains(body, "shutting down") {
+		t.Errorf("Expected /readyz to fail while shutting down, got: %d %s", code, body)
+	}
+}
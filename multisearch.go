@@ -0,0 +1,110 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/kitproj/splunk-cli/pkg/splunk"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// maxMultiSearches is the most searches a multi_search call may run, so one call can't use up the
+// user's search job quota
+const maxMultiSearches = 5
+
+// labeledQuery is a search of a multi_search call
+type labeledQuery struct {
+	Label string `json:"label"`
+	Query string `json:"query"`
+}
+
+// multiSearchHandler runs a call's searches at once over the same time range, and returns each
+// one's results under its label. The searches are checked against the server's policy by refuse
+// before any is run.
+func multiSearchHandler(ctx context.Context, client splunk.API, request mcp.CallToolRequest, defaults searchToolOptions, refuse func(query string) *mcp.CallToolResult) (*mcp.CallToolResult, error) {
+	var args struct {
+		Queries []labeledQuery `json:"queries"`
+	}
+	if err := request.BindArguments(&args); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Invalid 'queries' argument: %v", err)), nil
+	}
+	if len(args.Queries) == 0 || len(args.Queries) > maxMultiSearches {
+		return mcp.NewToolResultError(fmt.Sprintf("'queries' must have between 1 and %d searches, got %d", maxMultiSearches, len(args.Queries))), nil
+	}
+	labels := map[string]bool{}
+	for i := range args.Queries {
+		q := &args.Queries[i]
+		if strings.TrimSpace(q.Query) == "" {
+			return mcp.NewToolResultError(fmt.Sprintf("Search %d has no query", i+1)), nil
+		}
+		q.Label = defaultString(strings.TrimSpace(q.Label), fmt.Sprintf("search %d", i+1))
+		if labels[q.Label] {
+			return mcp.NewToolResultError(fmt.Sprintf("More than one search is labeled %q", q.Label)), nil
+		}
+		labels[q.Label] = true
+		if refused := refuse(q.Query); refused != nil {
+			refused.Content = append([]mcp.Content{mcp.NewTextContent(fmt.Sprintf("Search %q was refused, so none were run:", q.Label))}, refused.Content...)
+			return refused, nil
+		}
+	}
+
+	// Each search is run as if by the search tool, with the call's time range and options
+	results := make([]*mcp.CallToolResult, len(args.Queries))
+	var wg sync.WaitGroup
+	for i, q := range args.Queries {
+		arguments := map[string]interface{}{"query": q.Query}
+		for _, name := range []string{"earliest_time", "latest_time", "max_results", "summary_only", "timeout", "poll_interval"} {
+			if value, ok := request.GetArguments()[name]; ok {
+				arguments[name] = value
+			}
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			// A search that panics fails, rather than the server
+			defer func() {
+				if r := recover(); r != nil {
+					results[i] = mcp.NewToolResultError(toolCrashed(request.Params.Name, r).Error())
+				}
+			}()
+			results[i], _ = searchHandler(ctx, client, mcp.CallToolRequest{Params: mcp.CallToolParams{Name: "search", Arguments: arguments}}, defaults)
+		}()
+	}
+	wg.Wait()
+
+	var output strings.Builder
+	searches := make([]map[string]interface{}, len(args.Queries))
+	failed := 0
+	for i, q := range args.Queries {
+		searches[i] = map[string]interface{}{"label": q.Label, "query": q.Query}
+		output.WriteString(fmt.Sprintf("## %s\n", q.Label))
+		if results[i].IsError {
+			failed++
+			searches[i]["error"] = resultText(results[i])
+			output.WriteString(fmt.Sprintf("Failed: %s\n\n", resultText(results[i])))
+			continue
+		}
+		for name, value := range results[i].StructuredContent.(map[string]interface{}) {
+			searches[i][name] = value
+		}
+		output.WriteString(strings.TrimRight(resultText(results[i]), "\n") + "\n\n")
+	}
+
+	result := mcp.NewToolResultText(strings.TrimRight(output.String(), "\n"))
+	result.StructuredContent = map[string]interface{}{"searches": searches}
+	result.IsError = failed == len(args.Queries)
+	return result, nil
+}
+
+// resultText returns the text content of a tool result
+func resultText(result *mcp.CallToolResult) string {
+	var texts []string
+	for _, content := range result.Content {
+		if text, ok := content.(mcp.TextContent); ok {
+			texts = append(texts, text.Text)
+		}
+	}
+	return strings.Join(texts, "\n")
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kitproj/splunk-cli/pkg/splunk/splunktest"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestMultiSearchHandler(t *testing.T) {
+	s := splunktest.NewServer(t)
+	s.AddJob("1.1", map[string]interface{}{"count": "3"})
+	s.AddJob("1.2", map[string]interface{}{"count": "3"})
+	defaults := searchToolOptions{timeout: time.Minute, pollInterval: 10 * time.Millisecond}
+	allow := func(query string) *mcp.CallToolResult { return nil }
+
+	request := mcp.CallToolRequest{Params: mcp.CallToolParams{Name: "multi_search", Arguments: map[string]interface{}{
+		"queries": []interface{}{
+			map[string]interface{}{"label": "errors", "query": "index=main error | stats count"},
+			map[string]interface{}{"query": "index=main timeout | stats count"},
+		},
+		"earliest_time": "-1h",
+	}}}
+	result, err := multiSearchHandler(context.Background(), s.Client(), request, defaults, allow)
+	if err != nil || result.IsError {
+		t.Fatalf("Expected results, got %v and: %v", result, err)
+	}
+	searches := result.StructuredContent.(map[string]interface{})["searches"].([]map[string]interface{})
+	if len(searches) != 2 || searches[0]["label"] != "errors" || searches[1]["label"] != "search 2" {
+		t.Fatalf("Expected the labeled searches, got: %v", searches)
+	}
+	for _, search := range searches {
+		if rows, ok := search["results"].([]map[string]interface{}); !ok || len(rows) != 1 {
+			t.Errorf("Expected the results of %s, got: %v", search["label"], search)
+		}
+	}
+	if text := resultText(result); !strings.Contains(text, "## errors\n") || !strings.Contains(text, "## search 2\n") {
+		t.Errorf("Expected the results under their labels, got: %s", text)
+	}
+
+	// No search is run if any is refused, or if there are too many
+	refuse := func(query string) *mcp.CallToolResult {
+		if strings.Contains(query, "sendemail") {
+			return mcp.NewToolResultError("blocked")
+		}
+		return nil
+	}
+	request.Params.Arguments = map[string]interface{}{"queries": []interface{}{
+		map[string]interface{}{"query": "index=main"},
+		map[string]interface{}{"label": "mail", "query": "index=main | sendemail to=x@example.com"},
+	}}
+	if result, _ := multiSearchHandler(context.Background(), s.Client(), request, defaults, refuse); !result.IsError || !strings.Contains(resultText(result), `"mail" was refused`) {
+		t.Errorf("Expected the refused search to be named, got: %s", resultText(result))
+	}
+	queries := make([]interface{}, maxMultiSearches+1)
+	for i := range queries {
+		queries[i] = map[string]interface{}{"query": "index=main"}
+	}
+	request.Params.Arguments = map[string]interface{}{"queries": queries}
+	if result, _ := multiSearchHandler(context.Background(), s.Client(), request, defaults, allow); !result.IsError {
+		t.Errorf("Expected an error for more than %d searches", maxMultiSearches)
+	}
+}
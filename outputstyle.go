@@ -0,0 +1,61 @@
+package main
+
+import (
+	"io"
+	"os"
+
+	"github.com/kitproj/splunk-cli/internal/output"
+)
+
+// outputFormat returns the format, or if it's unset the profile's output format, or text
+func outputFormat(format string) string {
+	if format == "" && settings.Output != nil {
+		format = settings.Output.Format
+	}
+	return defaultString(format, "text")
+}
+
+// outputStyle returns the profile's output style, with any set by the global flags instead
+func outputStyle() output.Style {
+	var style output.Style
+	if o := settings.Output; o != nil {
+		style = output.Style{Theme: o.Theme, Table: o.TableStyle, MaxWidth: o.MaxWidth}
+	}
+	style.Theme = defaultString(themeName, style.Theme)
+	style.Table = defaultString(tableStyle, style.Table)
+	if maxWidth != 0 {
+		style.MaxWidth = maxWidth
+	}
+	return style
+}
+
+// newWriter creates a Writer for the format, or the profile's if it's unset, in the output style.
+// Output is only colored on a terminal, and never if NO_COLOR is set.
+func newWriter(w io.Writer, format string) (output.Writer, error) {
+	style := outputStyle()
+	if f, ok := w.(*os.File); !ok || !isTerminal(f) || os.Getenv("NO_COLOR") != "" {
+		style.Theme = ""
+	}
+	return output.NewStyledWriter(w, outputFormat(format), style)
+}
+
+// checkOutput checks the format, or the profile's if it's unset, and the output style, so a bad one
+// fails before a search is dispatched rather than once its results are in
+func checkOutput(format string) error {
+	_, err := output.NewStyledWriter(io.Discard, outputFormat(format), outputStyle())
+	return err
+}
+
+// writeAll writes rows like output.WriteAll, in the format or the profile's and the output style
+func writeAll(w io.Writer, format string, rows []map[string]interface{}) error {
+	writer, err := newWriter(w, format)
+	if err != nil {
+		return err
+	}
+	for _, row := range rows {
+		if err := writer.Write(row); err != nil {
+			return err
+		}
+	}
+	return writer.Close()
+}
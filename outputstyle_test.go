@@ -0,0 +1,60 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/kitproj/splunk-cli/internal/config"
+	"github.com/kitproj/splunk-cli/internal/output"
+)
+
+func TestOutputStyle(t *testing.T) {
+	saved := settings
+	defer func() { settings, themeName, tableStyle, maxWidth = saved, "", "", 0 }()
+
+	settings = &config.Profile{}
+	if got := outputFormat(""); got != "text" {
+		t.Errorf("Expected text without a profile format, got %q", got)
+	}
+	if got := outputStyle(); got != (output.Style{}) {
+		t.Errorf("Expected no style without output settings, got %+v", got)
+	}
+
+	settings = &config.Profile{Output: &config.OutputSettings{Format: "json", Theme: "dark", TableStyle: "boxed", MaxWidth: 40}}
+	if got := outputFormat(""); got != "json" {
+		t.Errorf("Expected the profile's format, got %q", got)
+	}
+	if got := outputFormat("csv"); got != "csv" {
+		t.Errorf("Expected the flag's format, got %q", got)
+	}
+	if got, want := outputStyle(), (output.Style{Theme: "dark", Table: "boxed", MaxWidth: 40}); got != want {
+		t.Errorf("Expected the profile's style %+v, got %+v", want, got)
+	}
+
+	// Flags override the profile
+	themeName, tableStyle, maxWidth = "none", "markdown", -1
+	if got, want := outputStyle(), (output.Style{Theme: "none", Table: "markdown", MaxWidth: -1}); got != want {
+		t.Errorf("Expected the flags' style %+v, got %+v", want, got)
+	}
+}
+
+func TestCheckOutput(t *testing.T) {
+	saved := settings
+	defer func() { settings, themeName = saved, "" }()
+
+	settings = &config.Profile{Output: &config.OutputSettings{Format: "json", Theme: "dark"}}
+	if err := checkOutput(""); err != nil {
+		t.Errorf("Expected no error, got: %v", err)
+	}
+	if err := checkOutput("yaml"); err == nil {
+		t.Error("Expected an error for an unknown format")
+	}
+	settings.Output.Format = "yaml"
+	if err := checkOutput(""); err == nil {
+		t.Error("Expected an error for the profile's unknown format")
+	}
+	// The theme is checked even though the output isn't a terminal
+	themeName = "neon"
+	if err := checkOutput("json"); err == nil {
+		t.Error("Expected an error for an unknown theme")
+	}
+}
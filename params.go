@@ -0,0 +1,268 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/kitproj/splunk-cli/internal/config"
+)
+
+// paramTypes are the types a search parameter may have, and how a value of each is written in SPL
+var paramTypes = map[string]func(value string) (string, error){
+	// Strings are quoted, so a value can't change the meaning of the query around it
+	"string": func(value string) (string, error) {
+		return quoteSPL(value), nil
+	},
+	"int": func(value string) (string, error) {
+		n, err := strconv.ParseInt(value, 10, 64)
+		return strconv.FormatInt(n, 10), err
+	},
+	"float": func(value string) (string, error) {
+		f, err := strconv.ParseFloat(value, 64)
+		return strconv.FormatFloat(f, 'f', -1, 64), err
+	},
+	"bool": func(value string) (string, error) {
+		b, err := strconv.ParseBool(value)
+		return strconv.FormatBool(b), err
+	},
+}
+
+const (
+	paramFlagUsage    = "value of a $name$ placeholder in the query, as name=value or name:type=value (repeatable)"
+	paramEnvUsage     = "secret parameter whose value is read from the environment variable of the same name, and redacted from local output only (repeatable)"
+	paramKeyringUsage = "secret parameter whose value is read from the keyring, saved with 'splunk secret set', and redacted from local output only (repeatable)"
+	paramsHelp        = "With --param, $name$ placeholders in the query are replaced by values, e.g. --param threshold:int=500.\n" +
+		"Types are string (the default, which is quoted), int, float and bool. Every placeholder needs a value, and $$ is a literal $.\n" +
+		"The values of --param-env and --param-keyring parameters are read when the search is dispatched, and redacted from local output only.\n" +
+		"They're sent to Splunk in the query, so Splunk's search history, Job Inspector and _audit index record them."
+)
+
+var (
+	// placeholder matches a $name$ placeholder in a query, or $$, an escaped dollar sign
+	placeholder = regexp.MustCompile(`\$\$|\$[A-Za-z_][A-Za-z0-9_]*\$`)
+	paramName   = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+)
+
+// searchParamsFlag collects repeated name[:type]=value flags, the values of a query's placeholders
+type searchParamsFlag struct {
+	// values are the parameters' values as SPL, keyed by name
+	values map[string]string
+	// secrets are where the values of secret parameters are read from, env or keyring, keyed by name
+	secrets map[string]string
+}
+
+// secretParamFlag collects repeated flags naming secret parameters, whose values are read from the
+// environment variable or keyring entry of the same name when the search is dispatched
+type secretParamFlag struct {
+	params *searchParamsFlag
+	source string
+}
+
+func (f *secretParamFlag) String() string {
+	return ""
+}
+
+func (f *secretParamFlag) Set(name string) error {
+	if !paramName.MatchString(name) {
+		return fmt.Errorf("expected the name of a parameter, got %q", name)
+	}
+	if err := f.params.add(name); err != nil {
+		return err
+	}
+	if f.params.secrets == nil {
+		f.params.secrets = map[string]string{}
+	}
+	f.params.secrets[name] = f.source
+	return nil
+}
+
+// secretValues are the values of the secret parameters in the query being run, as SPL and as they
+// are, each followed by its placeholder, for redact
+var secretValues []string
+
+func (p *searchParamsFlag) String() string {
+	if p == nil {
+		return ""
+	}
+	return strings.Join(sortedKeys(p.names()), ",")
+}
+
+func (p *searchParamsFlag) Set(s string) error {
+	spec, value, ok := strings.Cut(s, "=")
+	name, typ, typed := strings.Cut(spec, ":")
+	if !ok || !paramName.MatchString(name) {
+		return fmt.Errorf("expected name=value or name:type=value, got %q", s)
+	}
+	if !typed {
+		typ = "string"
+	}
+	format, ok := paramTypes[typ]
+	if !ok {
+		return fmt.Errorf("unknown type %q of parameter %s (must be one of %s)", typ, name, strings.Join(paramTypeNames(), ", "))
+	}
+	spl, err := format(value)
+	if err != nil {
+		return fmt.Errorf("parameter %s must be a %s, got %q", name, typ, value)
+	}
+	if err := p.add(name); err != nil {
+		return err
+	}
+	if p.values == nil {
+		p.values = map[string]string{}
+	}
+	p.values[name] = spl
+	return nil
+}
+
+// add checks a parameter isn't given more than once
+func (p *searchParamsFlag) add(name string) error {
+	if _, ok := p.values[name]; ok {
+		return fmt.Errorf("parameter %s is given more than once", name)
+	}
+	if _, ok := p.secrets[name]; ok {
+		return fmt.Errorf("parameter %s is given more than once", name)
+	}
+	return nil
+}
+
+func (p *searchParamsFlag) names() map[string]bool {
+	names := map[string]bool{}
+	for name := range p.values {
+		names[name] = true
+	}
+	for name := range p.secrets {
+		names[name] = true
+	}
+	return names
+}
+
+func paramTypeNames() []string {
+	names := map[string]bool{}
+	for typ := range paramTypes {
+		names[typ] = true
+	}
+	return sortedKeys(names)
+}
+
+// substitute replaces the $name$ placeholders of a query with the parameters' values and $$ with $.
+// Without parameters the query is returned as it is, as $name$ is also the syntax of the map command.
+// Every placeholder must have a value, and every parameter must have a placeholder. The values of
+// secret parameters are read now, and redacted from then on.
+func (p *searchParamsFlag) substitute(query string) (string, error) {
+	if len(p.values) == 0 && len(p.secrets) == 0 {
+		return query, nil
+	}
+	values := map[string]string{}
+	for name, value := range p.values {
+		values[name] = value
+	}
+	for name, source := range p.secrets {
+		value, err := secretParam(name, source)
+		if err != nil {
+			return "", err
+		}
+		values[name] = quoteSPL(value)
+		secretValues = append(secretValues, values[name], "$"+name+"$", value, "$"+name+"$")
+	}
+
+	missing := map[string]bool{}
+	unused := p.names()
+	query = placeholder.ReplaceAllStringFunc(query, func(match string) string {
+		if match == "$$" {
+			return "$"
+		}
+		name := match[1 : len(match)-1]
+		value, ok := values[name]
+		if !ok {
+			missing[name] = true
+			return match
+		}
+		delete(unused, name)
+		return value
+	})
+	if len(missing) > 0 {
+		return "", fmt.Errorf("missing value of parameter(s) %s, e.g. --param %s=value", strings.Join(sortedKeys(missing), ", "), sortedKeys(missing)[0])
+	}
+	if len(unused) > 0 {
+		return "", fmt.Errorf("the query has no placeholder for parameter(s) %s", strings.Join(sortedKeys(unused), ", "))
+	}
+	return query, nil
+}
+
+// secretParam reads the value of a secret parameter from the environment or the keyring
+func secretParam(name, source string) (string, error) {
+	if source == "keyring" {
+		value, err := config.LoadParamSecret(profileName(), name)
+		if err != nil {
+			return "", fmt.Errorf("failed to load parameter %s from the keyring (save it with 'splunk secret set %s'): %w", name, name, err)
+		}
+		return value, nil
+	}
+	value := os.Getenv(name)
+	if value == "" {
+		return "", fmt.Errorf("parameter %s needs the %s environment variable to be set", name, name)
+	}
+	return value, nil
+}
+
+// redact replaces the values of secret parameters in s with their placeholders, so they don't
+// appear in output, errors or the files and hooks that searches are recorded in
+func redact(s string) string {
+	if len(secretValues) == 0 {
+		return s
+	}
+	return strings.NewReplacer(secretValues...).Replace(s)
+}
+
+func secretCommand() *command {
+	return &command{
+		name:  "secret",
+		short: "Manage the values of secret search parameters in the keyring",
+		long: "Save the values of parameters such as API keys and account IDs to the system keyring, for searches to use with\n" +
+			"--param-keyring <name>. Values are kept per profile, and are never shown in output or command history, but are\n" +
+			"sent to Splunk in the query, so Splunk's search history and _audit index record them.",
+		subcommands: []*command{
+			{
+				name:    "set",
+				args:    "<name>",
+				short:   "Save the value of a secret parameter, prompted for or piped to stdin",
+				minArgs: 1,
+				maxArgs: 1,
+				run: func(ctx context.Context, args []string) error {
+					if !paramName.MatchString(args[0]) {
+						return fmt.Errorf("expected the name of a parameter, got %q", args[0])
+					}
+					value, err := readSecret("secret " + args[0])
+					if err != nil {
+						return err
+					}
+					if err := config.SaveParamSecret(profileName(), args[0], value); err != nil {
+						return fmt.Errorf("failed to save secret: %w", err)
+					}
+					slog.Info("Saved secret", "name", args[0], profileAttr())
+					return nil
+				},
+			},
+			{
+				name:    "delete",
+				aliases: []string{"rm"},
+				args:    "<name>",
+				short:   "Delete the value of a secret parameter",
+				minArgs: 1,
+				maxArgs: 1,
+				run: func(ctx context.Context, args []string) error {
+					if err := config.DeleteParamSecret(profileName(), args[0]); err != nil {
+						return fmt.Errorf("failed to delete secret: %w", err)
+					}
+					slog.Info("Deleted secret", "name", args[0], profileAttr())
+					return nil
+				},
+			},
+		},
+	}
+}
@@ -0,0 +1,76 @@
+package main
+
+import "testing"
+
+func TestSubstitute(t *testing.T) {
+	tests := []struct {
+		params  []string
+		query   string
+		want    string
+		wantErr bool
+	}{
+		{nil, `| map search="search host=$host$"`, `| map search="search host=$host$"`, false},
+		{[]string{"env=prod", "threshold:int=500"}, `index=app env=$env$ | where count > $threshold$`, `index=app env="prod" | where count > 500`, false},
+		{[]string{`env=prod" OR 1=1`}, `env=$env$`, `env="prod\" OR 1=1"`, false},
+		{[]string{"ratio:float=0.5", "on:bool=1"}, `$ratio$ $on$ $$ratio$$`, `0.5 true $ratio$`, false},
+		{[]string{"env=prod"}, `env=$env$ threshold=$threshold$`, ``, true},
+		{[]string{"env=prod", "region=eu"}, `env=$env$`, ``, true},
+	}
+
+	for _, tt := range tests {
+		var params searchParamsFlag
+		for _, param := range tt.params {
+			if err := params.Set(param); err != nil {
+				t.Fatalf("%s: unexpected error: %v", param, err)
+			}
+		}
+		got, err := params.substitute(tt.query)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: expected error %v, got: %v", tt.query, tt.wantErr, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("%s: expected %s, got: %s", tt.query, tt.want, got)
+		}
+	}
+}
+
+func TestSearchParamsFlagSet(t *testing.T) {
+	for _, param := range []string{"env", "=prod", "1env=prod", "threshold:int=lots", "threshold:number=1"} {
+		var params searchParamsFlag
+		if err := params.Set(param); err == nil {
+			t.Errorf("%s: expected an error", param)
+		}
+	}
+	var params searchParamsFlag
+	params.Set("env=prod")
+	if err := params.Set("env=dev"); err == nil {
+		t.Errorf("expected an error for a repeated parameter")
+	}
+}
+
+func TestSecretParams(t *testing.T) {
+	t.Setenv("API_KEY", `s3cr"et`)
+	defer func() { secretValues = nil }()
+
+	var params searchParamsFlag
+	if err := (&secretParamFlag{&params, "env"}).Set("API_KEY"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := params.Set("API_KEY=other"); err == nil {
+		t.Error("Expected an error for a parameter given as a secret and a value")
+	}
+	query, err := params.substitute(`index=main key=$API_KEY$`)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if want := `index=main key="s3cr\"et"`; query != want {
+		t.Errorf("Expected %s, got: %s", want, query)
+	}
+	if got := redact("Running search: " + query); got != "Running search: index=main key=$API_KEY$" {
+		t.Errorf("Expected the secret to be redacted, got: %s", got)
+	}
+	if got := redact(`Unknown key s3cr"et`); got != `Unknown key $API_KEY$` {
+		t.Errorf("Expected the secret to be redacted, got: %s", got)
+	}
+}
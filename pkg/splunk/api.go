@@ -0,0 +1,66 @@
+package splunk
+
+import (
+	"context"
+	"io"
+	"time"
+)
+
+// API is the part of the REST API that Client wraps for searches, search jobs, saved searches,
+// alerts and indexes. Code that depends on API rather than *Client can be tested with a fake,
+// such as splunktest.Fake, or a Client of a splunktest.Server.
+type API interface {
+	// Searches and search jobs
+	RunSearch(ctx context.Context, searchQuery string, opts SearchOptions) (string, error)
+	OneshotSearch(ctx context.Context, searchQuery string, opts SearchOptions, count int) (*SearchResult, error)
+	Export(ctx context.Context, searchQuery string, opts SearchOptions, fn func(map[string]interface{}) error) error
+	ParseSearch(ctx context.Context, searchQuery string) (*ParsedSearch, error)
+	ResolveTime(ctx context.Context, modifier string) (time.Time, error)
+	GetSearchStatus(ctx context.Context, sid string) (*Search, error)
+	GetResults(ctx context.Context, sid string, opts ResultsOptions) (*SearchResult, error)
+	StreamResults(ctx context.Context, sid string, opts ResultsOptions, fn func(map[string]interface{}) error) (Messages, error)
+	ListJobs(ctx context.Context, count int) ([]Job, error)
+	InspectJob(ctx context.Context, sid string) (map[string]interface{}, error)
+	CancelSearch(ctx context.Context, sid string) error
+	FinalizeSearch(ctx context.Context, sid string) error
+
+	// Saved searches
+	ListSavedSearches(ctx context.Context) ([]SavedSearch, error)
+	GetSavedSearch(ctx context.Context, name string) (*SavedSearch, error)
+	CreateSavedSearch(ctx context.Context, search SavedSearch) error
+	UpdateSavedSearch(ctx context.Context, search SavedSearch) error
+	DeleteSavedSearch(ctx context.Context, name string) error
+	DispatchSavedSearch(ctx context.Context, name string, opts SearchOptions) (string, error)
+
+	// Alerts
+	ListAlerts(ctx context.Context) ([]Alert, error)
+	ListFiredAlerts(ctx context.Context, opts FiredAlertsOptions) ([]FiredAlert, error)
+
+	// Indexes and the server
+	ListIndexes(ctx context.Context) ([]Index, error)
+	GetServerInfo(ctx context.Context) (map[string]interface{}, error)
+	GetServerHealth(ctx context.Context) (*HealthFeature, error)
+}
+
+// Connection is implemented by the APIs that call a Splunk server, such as Client, unlike fakes.
+// Code given an API can use it to describe the server, e.g. to a command it runs.
+type Connection interface {
+	// ServerURL returns the base URL of the server's REST API
+	ServerURL() string
+	// AuthToken returns the token requests are authenticated with, or "" with basic auth
+	AuthToken() string
+}
+
+// EventCollector is the part of the HTTP Event Collector that HECClient wraps to ingest events
+type EventCollector interface {
+	Send(ctx context.Context, events ...HECEvent) (*HECResponse, error)
+	SendRaw(ctx context.Context, data io.Reader, meta HECMetadata) (*HECResponse, error)
+	WaitForAcks(ctx context.Context, interval time.Duration, ackIDs ...int64) error
+}
+
+var (
+	_ API            = (*Client)(nil)
+	_ JobQuota       = (*Client)(nil)
+	_ Connection     = (*Client)(nil)
+	_ EventCollector = (*HECClient)(nil)
+)
@@ -0,0 +1,55 @@
+package splunk
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// BucketStats summarizes an index's buckets in one state: hot, warm, cold or thawed
+type BucketStats struct {
+	Index   string
+	State   string
+	Buckets int64
+	SizeMB  float64
+	// Earliest is the time of the oldest event in the buckets
+	Earliest time.Time
+	// OldestEnd is the time of the newest event of the oldest bucket, which is the first to be frozen by age
+	OldestEnd time.Time
+}
+
+// bucketStatsSearch summarizes every bucket of every index, including internal ones, by index and state
+const bucketStatsSearch = "| dbinspect index=* index=_* " +
+	"| stats count as buckets, sum(sizeOnDiskMB) as sizeMB, min(startEpoch) as earliest, min(endEpoch) as oldestEnd by index, state"
+
+// ListBucketStats summarizes the buckets of each index by state, from the dbinspect command. With
+// indexer clustering, the sizes are of every copy of each bucket.
+func (c *Client) ListBucketStats(ctx context.Context) ([]BucketStats, error) {
+	// There is a row per index and state, which may be more than the default of 100
+	result, err := c.OneshotSearch(ctx, bucketStatsSearch, SearchOptions{}, 10000)
+	if err != nil {
+		return nil, err
+	}
+	stats := make([]BucketStats, 0, len(result.Results))
+	for _, row := range result.Results {
+		s := BucketStats{Index: fmt.Sprint(row["index"]), State: fmt.Sprint(row["state"])}
+		s.Buckets = int64(resultFloat(row["buckets"]))
+		s.SizeMB = resultFloat(row["sizeMB"])
+		if t := resultFloat(row["earliest"]); t > 0 {
+			s.Earliest = time.Unix(int64(t), 0)
+		}
+		if t := resultFloat(row["oldestEnd"]); t > 0 {
+			s.OldestEnd = time.Unix(int64(t), 0)
+		}
+		stats = append(stats, s)
+	}
+	return stats, nil
+}
+
+// resultFloat returns a numeric field of a search result, which Splunk returns as a string, or 0
+func resultFloat(v interface{}) float64 {
+	s, _ := v.(string)
+	f, _ := strconv.ParseFloat(s, 64)
+	return f
+}
@@ -0,0 +1,27 @@
+package splunk
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestListBucketStats(t *testing.T) {
+	c := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasPrefix(r.FormValue("search"), "| dbinspect") || r.FormValue("exec_mode") != "oneshot" {
+			t.Errorf("Unexpected search: %v", r.Form)
+		}
+		w.Write([]byte(`{"results":[{"index":"main","state":"warm","buckets":"10","sizeMB":"412.5","earliest":"1700000000","oldestEnd":"1700086400"}]}`))
+	}))
+
+	stats, err := c.ListBucketStats(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := BucketStats{Index: "main", State: "warm", Buckets: 10, SizeMB: 412.5, Earliest: time.Unix(1700000000, 0), OldestEnd: time.Unix(1700086400, 0)}
+	if len(stats) != 1 || stats[0] != want {
+		t.Errorf("Expected %+v, got %+v", want, stats)
+	}
+}
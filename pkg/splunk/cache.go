@@ -0,0 +1,206 @@
+package splunk
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// MetadataPaths are the API paths whose responses are worth caching: they change rarely but are fetched often
+var MetadataPaths = []string{"/saved/searches", "/data/indexes", "/server/info"}
+
+// ResponseCache caches GET responses on disk and revalidates them with
+// conditional requests (If-None-Match / If-Modified-Since)
+type ResponseCache struct {
+	// Dir is the directory the cache entries are stored in
+	Dir string
+	// Paths are the URL path fragments of the requests to cache
+	Paths []string
+	// MaxAge is how long an entry is served without revalidating it. Older entries are pruned
+	// whenever another is stored.
+	MaxAge time.Duration
+	// Identity is who the responses are for, such as a profile and username, so users never share
+	// entries. If empty, entries are keyed by the Authorization header, which changes with every
+	// session key.
+	Identity string
+}
+
+// NewResponseCache creates a ResponseCache for the metadata endpoints
+func NewResponseCache(dir string, maxAge time.Duration) *ResponseCache {
+	return &ResponseCache{Dir: dir, Paths: MetadataPaths, MaxAge: maxAge}
+}
+
+// cacheEntry is a cached response body with its validators
+type cacheEntry struct {
+	ETag         string    `json:"etag,omitempty"`
+	LastModified string    `json:"last_modified,omitempty"`
+	StoredAt     time.Time `json:"stored_at"`
+	Body         []byte    `json:"body"`
+}
+
+// Middleware serves cacheable requests from the cache when possible
+func (c *ResponseCache) Middleware(next RequestFunc) RequestFunc {
+	return func(req *http.Request) (*http.Response, error) {
+		if req.Method != "GET" && c.matches(req) {
+			return c.invalidate(next(req))
+		}
+		if !c.cacheable(req) {
+			return next(req)
+		}
+
+		path := c.entryPath(req)
+		entry := c.load(path)
+		if entry != nil {
+			if time.Since(entry.StoredAt) < c.MaxAge {
+				return entry.response(req), nil
+			}
+			if entry.ETag != "" {
+				req.Header.Set("If-None-Match", entry.ETag)
+			}
+			if entry.LastModified != "" {
+				req.Header.Set("If-Modified-Since", entry.LastModified)
+			}
+		}
+
+		resp, err := next(req)
+		if err != nil {
+			return nil, err
+		}
+
+		if resp.StatusCode == http.StatusNotModified && entry != nil {
+			resp.Body.Close()
+			entry.StoredAt = time.Now()
+			c.store(path, entry)
+			return entry.response(req), nil
+		}
+
+		if resp.StatusCode != http.StatusOK {
+			return resp, nil
+		}
+
+		body, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		c.store(path, &cacheEntry{
+			ETag:         resp.Header.Get("ETag"),
+			LastModified: resp.Header.Get("Last-Modified"),
+			StoredAt:     time.Now(),
+			Body:         body,
+		})
+		resp.Body = io.NopCloser(bytes.NewReader(body))
+		return resp, nil
+	}
+}
+
+func (c *ResponseCache) cacheable(req *http.Request) bool {
+	return req.Method == "GET" && c.matches(req)
+}
+
+func (c *ResponseCache) matches(req *http.Request) bool {
+	for _, p := range c.Paths {
+		if strings.Contains(req.URL.Path, p) {
+			return true
+		}
+	}
+	return false
+}
+
+// invalidate drops every entry once a write to a cached endpoint succeeds, e.g. creating a
+// saved search, so the next list isn't served stale. Entries are keyed by a hash, so they can't
+// be dropped selectively.
+func (c *ResponseCache) invalidate(resp *http.Response, err error) (*http.Response, error) {
+	if err == nil && resp.StatusCode < 300 {
+		_ = os.RemoveAll(c.Dir)
+	}
+	return resp, err
+}
+
+// entryPath returns the file for a request, keyed by URL, which includes the host, and identity
+func (c *ResponseCache) entryPath(req *http.Request) string {
+	identity := c.Identity
+	if identity == "" {
+		identity = req.Header.Get("Authorization")
+	}
+	sum := sha256.Sum256([]byte(req.URL.String() + "\n" + identity))
+	return filepath.Join(c.Dir, hex.EncodeToString(sum[:])+".json")
+}
+
+func (c *ResponseCache) load(path string) *cacheEntry {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	var entry cacheEntry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return nil
+	}
+	return &entry
+}
+
+// store writes an entry atomically; failures are ignored as the cache is only an optimisation
+func (c *ResponseCache) store(path string, entry *cacheEntry) {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return
+	}
+	if err := os.MkdirAll(c.Dir, 0700); err != nil {
+		return
+	}
+	tmp, err := os.CreateTemp(c.Dir, ".entry-*")
+	if err != nil {
+		return
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return
+	}
+	if err := tmp.Close(); err != nil {
+		return
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return
+	}
+	c.prune(path)
+}
+
+// prune removes entries, other than the one at path, that were stored longer than MaxAge ago, so
+// entries of old credentials and requests that aren't made again don't pile up
+func (c *ResponseCache) prune(path string) {
+	files, err := os.ReadDir(c.Dir)
+	if err != nil {
+		return
+	}
+	for _, f := range files {
+		name := filepath.Join(c.Dir, f.Name())
+		if name == path {
+			continue
+		}
+		if info, err := f.Info(); err == nil && time.Since(info.ModTime()) > c.MaxAge {
+			_ = os.Remove(name)
+		}
+	}
+}
+
+func (e *cacheEntry) response(req *http.Request) *http.Response {
+	return &http.Response{
+		Status:        "200 OK",
+		StatusCode:    http.StatusOK,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        http.Header{"Content-Type": {"application/json"}},
+		Body:          io.NopCloser(bytes.NewReader(e.Body)),
+		ContentLength: int64(len(e.Body)),
+		Request:       req,
+	}
+}
@@ -0,0 +1,119 @@
+package splunk
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestResponseCacheRevalidatesWithETag(t *testing.T) {
+	var requests, notModified int
+	c := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			notModified++
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		fmt.Fprint(w, `{"entry":[{"content":{"version":"9.1.0"}}]}`)
+	}))
+	c.Use(NewResponseCache(t.TempDir(), 0).Middleware)
+
+	for i := 0; i < 2; i++ {
+		info, err := c.GetServerInfo(context.Background())
+		if err != nil {
+			t.Fatalf("Expected no error, got: %v", err)
+		}
+		if info["version"] != "9.1.0" {
+			t.Errorf("Expected version 9.1.0, got: %v", info["version"])
+		}
+	}
+
+	if requests != 2 || notModified != 1 {
+		t.Errorf("Expected 2 requests with 1 revalidated, got: %d requests, %d not modified", requests, notModified)
+	}
+}
+
+func TestResponseCacheInvalidatedByWrites(t *testing.T) {
+	var requests int
+	c := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "GET" {
+			requests++
+		}
+		fmt.Fprint(w, `{"entry":[]}`)
+	}))
+	c.Use(NewResponseCache(t.TempDir(), time.Hour).Middleware)
+
+	for i := 0; i < 2; i++ {
+		if _, err := c.ListSavedSearches(context.Background()); err != nil {
+			t.Fatalf("Expected no error, got: %v", err)
+		}
+	}
+	if requests != 1 {
+		t.Fatalf("Expected the second list to be served from the cache, got: %d requests", requests)
+	}
+
+	if err := c.DeleteSavedSearch(context.Background(), "errors"); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if _, err := c.ListSavedSearches(context.Background()); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if requests != 2 {
+		t.Errorf("Expected the list to be fetched again after a delete, got: %d requests", requests)
+	}
+}
+
+func TestResponseCacheIdentity(t *testing.T) {
+	var requests int
+	c := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		fmt.Fprint(w, `{"entry":[]}`)
+	}))
+	dir := t.TempDir()
+	cache := NewResponseCache(dir, time.Hour)
+	cache.Identity = "prod\nadmin"
+	c.Use(cache.Middleware)
+
+	// A new session key is the same user, so it's still served from the cache
+	for _, token := range []string{"key-1", "key-2"} {
+		c.Token = token
+		if _, err := c.ListSavedSearches(context.Background()); err != nil {
+			t.Fatalf("Expected no error, got: %v", err)
+		}
+	}
+	if requests != 1 {
+		t.Errorf("Expected the second list to be served from the cache, got: %d requests", requests)
+	}
+}
+
+func TestResponseCachePrunesOldEntries(t *testing.T) {
+	c := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"entry":[]}`)
+	}))
+	dir := t.TempDir()
+	c.Use(NewResponseCache(dir, time.Hour).Middleware)
+
+	old := filepath.Join(dir, "old.json")
+	if err := os.WriteFile(old, []byte(`{}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(old, time.Now().Add(-2*time.Hour), time.Now().Add(-2*time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.ListSavedSearches(context.Background()); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	if _, err := os.Stat(old); !os.IsNotExist(err) {
+		t.Errorf("Expected the old entry to be pruned, got: %v", err)
+	}
+	if files, _ := os.ReadDir(dir); len(files) != 1 {
+		t.Errorf("Expected only the new entry to be kept, got: %d entries", len(files))
+	}
+}
@@ -0,0 +1,827 @@
+package splunk
+
+import (
+	"compress/gzip"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Client represents a Splunk API client
+type Client struct {
+	BaseURL    string
+	HTTPClient *http.Client
+	Token      string
+	// Namespace is the owner and app context of knowledge objects, such as saved searches and alerts.
+	// The zero value is the global /services namespace. Searches run in their SearchOptions' namespace.
+	Namespace Namespace
+	// Middleware wraps every request sent to the API, the first being outermost
+	Middleware []Middleware
+	// OnWarning, if set, is called with each warning Splunk reports about a search, e.g. that a peer
+	// was unavailable so the results may be incomplete
+	OnWarning func(sid string, message Message)
+
+	// warned are the warnings OnWarning was called with about each job, the oldest job first in warnedJobs
+	warnedMu   sync.Mutex
+	warned     map[string]map[string]bool
+	warnedJobs []string
+}
+
+// RequestFunc sends an HTTP request and returns its response
+type RequestFunc func(req *http.Request) (*http.Response, error)
+
+// Middleware wraps a RequestFunc, e.g. to add logging, metrics, retries or custom headers
+type Middleware func(next RequestFunc) RequestFunc
+
+// ServerURL returns the base URL of the server's REST API
+func (c *Client) ServerURL() string {
+	return c.BaseURL
+}
+
+// AuthToken returns the token requests are authenticated with, or "" with basic auth
+func (c *Client) AuthToken() string {
+	return c.Token
+}
+
+// Use appends middleware to the client
+func (c *Client) Use(middleware ...Middleware) {
+	c.Middleware = append(c.Middleware, middleware...)
+}
+
+// WithHeader returns middleware that sets a header on every request
+func WithHeader(key, value string) Middleware {
+	return func(next RequestFunc) RequestFunc {
+		return func(req *http.Request) (*http.Response, error) {
+			req.Header.Set(key, value)
+			return next(req)
+		}
+	}
+}
+
+// NewClient creates a new Splunk API client
+func NewClient(host, token string) *Client {
+	return &Client{
+		BaseURL: fmt.Sprintf("https://%s:8089", host),
+		// No timeout, which would cut off long exports and streamed results, so deadlines come from contexts
+		HTTPClient: &http.Client{},
+		Token:      token,
+	}
+}
+
+// WithRequestID returns middleware that sets an X-Request-Id header on every
+// request, generating a random ID per request when id is empty
+func WithRequestID(id string) Middleware {
+	return func(next RequestFunc) RequestFunc {
+		return func(req *http.Request) (*http.Response, error) {
+			requestID := id
+			if requestID == "" {
+				b := make([]byte, 8)
+				_, _ = rand.Read(b)
+				requestID = hex.EncodeToString(b)
+			}
+			req.Header.Set("X-Request-Id", requestID)
+			return next(req)
+		}
+	}
+}
+
+// decompress asks for gzip-compressed responses and decompresses them, so large results transfer
+// quickly whatever transport the client has, and middleware such as the cache sees plain bodies
+func decompress(next RequestFunc) RequestFunc {
+	return func(req *http.Request) (*http.Response, error) {
+		if req.Header.Get("Accept-Encoding") == "" && req.Header.Get("Range") == "" {
+			req.Header.Set("Accept-Encoding", "gzip")
+		}
+		resp, err := next(req)
+		if err != nil || !strings.EqualFold(resp.Header.Get("Content-Encoding"), "gzip") {
+			return resp, err
+		}
+		body, err := gzip.NewReader(resp.Body)
+		switch {
+		case err == io.EOF:
+			// An empty body, e.g. of a 204 response, has no gzip header to read
+			resp.Body.Close()
+			resp.Body = http.NoBody
+		case err != nil:
+			resp.Body.Close()
+			return nil, fmt.Errorf("failed to decompress response: %w", err)
+		default:
+			resp.Body = gzipBody{Reader: body, body: resp.Body}
+		}
+		resp.Header.Del("Content-Encoding")
+		resp.Header.Del("Content-Length")
+		resp.ContentLength = -1
+		resp.Uncompressed = true
+		return resp, nil
+	}
+}
+
+// gzipBody is a decompressed response body, which closes the compressed body
+type gzipBody struct {
+	*gzip.Reader
+	body io.ReadCloser
+}
+
+func (b gzipBody) Close() error {
+	return b.body.Close()
+}
+
+// Search represents a Splunk search job
+type Search struct {
+	SID     string `json:"sid"`
+	Content struct {
+		IsDone        Flag   `json:"isDone"`
+		DoneProgress  Float  `json:"doneProgress"`
+		ResultCount   Number `json:"resultCount"`
+		EventCount    Number `json:"eventCount"`
+		DispatchState string `json:"dispatchState"`
+		// IsFinalized is whether the job was stopped before it finished, keeping the results it had
+		IsFinalized Flag `json:"isFinalized"`
+		// RunDuration is how long the job ran, in seconds, and ScanCount how many events it scanned
+		RunDuration Float  `json:"runDuration"`
+		ScanCount   Number `json:"scanCount"`
+		// ReportSearch is the transforming part of the search, such as "stats count by host", if it has one
+		ReportSearch string `json:"reportSearch"`
+		// EarliestTime and LatestTime are the job's time range, resolved to ISO 8601 times
+		EarliestTime string   `json:"earliestTime"`
+		LatestTime   string   `json:"latestTime"`
+		Messages     Messages `json:"messages"`
+	} `json:"content"`
+}
+
+// SearchResult represents a search result
+type SearchResult struct {
+	Results  []map[string]interface{} `json:"results"`
+	Messages Messages                 `json:"messages"`
+}
+
+// SavedSearch represents a saved search
+type SavedSearch struct {
+	Name         string `json:"name"`
+	Search       string `json:"search"`
+	Description  string `json:"description"`
+	CronSchedule string `json:"cron_schedule"`
+	IsScheduled  Flag   `json:"is_scheduled"`
+	Disabled     Flag   `json:"disabled"`
+	EarliestTime string `json:"dispatch.earliest_time"`
+	LatestTime   string `json:"dispatch.latest_time"`
+	// Clear names the fields UpdateSavedSearch sets to empty, by their JSON names, e.g. "description".
+	// Clearing cron_schedule unschedules the search.
+	Clear []string `json:"-"`
+}
+
+// Alert represents a Splunk alert
+type Alert struct {
+	Name         string `json:"name"`
+	Search       string `json:"search"`
+	Description  string `json:"description"`
+	CronSchedule string `json:"cron_schedule"`
+	Actions      string `json:"actions"`
+}
+
+// FiredAlert is a single trigger of an alert
+type FiredAlert struct {
+	Name        string `json:"savedsearch_name"`
+	SID         string `json:"sid"`
+	TriggerTime Number `json:"trigger_time"`
+	Severity    Number `json:"severity"`
+	AlertType   string `json:"alert_type"`
+	Actions     string `json:"actions"`
+}
+
+// SeverityName returns the name of the alert's severity level
+func (a FiredAlert) SeverityName() string {
+	names := []string{"", "debug", "info", "warn", "error", "severe", "fatal"}
+	if a.Severity < 1 || int(a.Severity) >= len(names) {
+		return fmt.Sprint(a.Severity)
+	}
+	return names[a.Severity]
+}
+
+// Index is an index with its size and retention
+type Index struct {
+	Name            string `json:"name"`
+	DataType        string `json:"datatype"`
+	TotalEventCount int64  `json:"total_event_count"`
+	CurrentSizeMB   int64  `json:"current_size_mb"`
+	MaxSizeMB       int64  `json:"max_size_mb"`
+	// RetentionSecs is how long events are kept before they're frozen (archived or deleted)
+	RetentionSecs int64  `json:"retention_secs"`
+	MinTime       string `json:"min_time,omitempty"`
+	MaxTime       string `json:"max_time,omitempty"`
+	Disabled      bool   `json:"disabled"`
+}
+
+// HealthFeature is the health of splunkd or one of its features, e.g. green, yellow or red
+type HealthFeature struct {
+	Health   string                   `json:"health"`
+	Features map[string]HealthFeature `json:"features,omitempty"`
+}
+
+// Unhealthy returns the paths of the features that aren't green, e.g. "File Monitor Input > Forwarder Ingestion Latency: red"
+func (f HealthFeature) Unhealthy() []string {
+	var paths []string
+	names := make([]string, 0, len(f.Features))
+	for name := range f.Features {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		feature := f.Features[name]
+		if nested := feature.Unhealthy(); len(nested) > 0 {
+			for _, path := range nested {
+				paths = append(paths, name+" > "+path)
+			}
+		} else if feature.Health != "" && feature.Health != "green" {
+			paths = append(paths, name+": "+feature.Health)
+		}
+	}
+	return paths
+}
+
+// ParsedSearch represents the result of parsing a search with the search parser
+type ParsedSearch struct {
+	RemoteSearch string          `json:"remoteSearch"`
+	Commands     []ParsedCommand `json:"commands"`
+}
+
+// ParsedCommand represents a single command in a parsed search pipeline
+type ParsedCommand struct {
+	Command string `json:"command"`
+	RawArgs string `json:"rawargs"`
+}
+
+// APIError is a request the API responded to with an error status, e.g. 401 for a bad token
+// or 400 for a search with a syntax error
+type APIError struct {
+	StatusCode int
+	// RequestID is the X-Request-Id the request was sent with, if any, to match it with splunkd's access logs
+	RequestID string
+	Body      string
+}
+
+func (e *APIError) Error() string {
+	var requestID string
+	if e.RequestID != "" {
+		requestID = fmt.Sprintf(" (request ID %s)", e.RequestID)
+	}
+	return fmt.Sprintf("API request failed with status %d%s: %s", e.StatusCode, requestID, e.Body)
+}
+
+// doRequest performs an HTTP request to the Splunk API
+func (c *Client) doRequest(ctx context.Context, method, path string, body io.Reader, contentType string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, c.BaseURL+path, body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", c.Token))
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+
+	do := decompress(c.HTTPClient.Do)
+	for i := len(c.Middleware) - 1; i >= 0; i-- {
+		do = c.Middleware[i](do)
+	}
+
+	resp, err := do(req)
+
+	// Echo the request ID set by middleware, so errors can be matched with splunkd's access logs
+	var requestID string
+	if id := req.Header.Get("X-Request-Id"); id != "" {
+		requestID = fmt.Sprintf(" (request ID %s)", id)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute request%s: %w", requestID, err)
+	}
+
+	if resp.StatusCode >= 400 {
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		return nil, &APIError{StatusCode: resp.StatusCode, RequestID: req.Header.Get("X-Request-Id"), Body: string(body)}
+	}
+
+	return resp, nil
+}
+
+// RunSearch creates and runs a search job
+func (c *Client) RunSearch(ctx context.Context, searchQuery string, opts SearchOptions) (string, error) {
+	data := opts.values(searchQuery)
+
+	resp, err := c.doRequest(ctx, "POST", c.namespace(opts.Namespace).Path("/search/jobs"), strings.NewReader(data.Encode()), "application/x-www-form-urlencoded")
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	var result struct {
+		SID string `json:"sid"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return "", fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	return result.SID, nil
+}
+
+// Export runs a search with the export endpoint, which streams results as
+// they are produced instead of creating a job to poll. fn is called with each result in turn.
+func (c *Client) Export(ctx context.Context, searchQuery string, opts SearchOptions, fn func(map[string]interface{}) error) error {
+	data := opts.values(searchQuery)
+	// Only stream final results, not the previews of transforming searches
+	data.Set("preview", "false")
+
+	resp, err := c.doRequest(ctx, "POST", c.namespace(opts.Namespace).Path("/search/jobs/export"), strings.NewReader(data.Encode()), "application/x-www-form-urlencoded")
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	decoder := json.NewDecoder(resp.Body)
+	for {
+		var row struct {
+			Preview bool                   `json:"preview"`
+			Result  map[string]interface{} `json:"result"`
+		}
+		if err := decoder.Decode(&row); err == io.EOF {
+			return nil
+		} else if err != nil {
+			return fmt.Errorf("failed to decode response: %w", err)
+		}
+
+		if row.Preview || row.Result == nil {
+			continue
+		}
+		if err := fn(row.Result); err != nil {
+			return err
+		}
+	}
+}
+
+// OneshotSearch runs a search and returns its results in a single request, without a job to
+// poll. It suits small, fast searches; a count of 0 returns Splunk's default of 100 results.
+func (c *Client) OneshotSearch(ctx context.Context, searchQuery string, opts SearchOptions, count int) (*SearchResult, error) {
+	opts.ExecMode = "oneshot"
+	data := opts.values(searchQuery)
+	if count > 0 {
+		data.Set("count", fmt.Sprint(count))
+	}
+
+	resp, err := c.doRequest(ctx, "POST", c.namespace(opts.Namespace).Path("/search/jobs"), strings.NewReader(data.Encode()), "application/x-www-form-urlencoded")
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var result SearchResult
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+	c.warn("", result.Messages)
+
+	return &result, nil
+}
+
+// ResolveTime resolves a time modifier, such as "-24h@h", "now" or an ISO 8601 time, to the
+// time it means on the server, so it can be split into ranges
+func (c *Client) ResolveTime(ctx context.Context, modifier string) (time.Time, error) {
+	params := url.Values{}
+	params.Set("time", modifier)
+	params.Set("output_time_format", "%s")
+	params.Set("output_mode", "json")
+	resp, err := c.doRequest(ctx, "GET", c.Namespace.Path("/search/timeparser")+"?"+params.Encode(), nil, "")
+	if err != nil {
+		return time.Time{}, err
+	}
+	defer resp.Body.Close()
+
+	var result map[string]string
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return time.Time{}, fmt.Errorf("failed to decode response: %w", err)
+	}
+	epoch, err := strconv.ParseFloat(result[modifier], 64)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to resolve time %q: %w", modifier, err)
+	}
+	return time.Unix(int64(epoch), 0), nil
+}
+
+// GetSearchStatus gets the status of a search job
+func (c *Client) GetSearchStatus(ctx context.Context, sid string) (*Search, error) {
+	resp, err := c.doRequest(ctx, "GET", c.Namespace.Path("/search/jobs/"+url.PathEscape(sid))+"?output_mode=json", nil, "")
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var search Search
+	if err := json.NewDecoder(resp.Body).Decode(&search); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	return &search, nil
+}
+
+// CancelSearch cancels a search job and deletes its results
+func (c *Client) CancelSearch(ctx context.Context, sid string) error {
+	// A cancelled job's results are deleted, so there'll be no more warnings about them
+	c.forgetWarnings(sid)
+	return c.controlJob(ctx, sid, "cancel")
+}
+
+// ParseSearch validates a search with the search parser, expanding any macros it references
+func (c *Client) ParseSearch(ctx context.Context, searchQuery string) (*ParsedSearch, error) {
+	params := url.Values{}
+	params.Set("q", searchQuery)
+	params.Set("output_mode", "json")
+	params.Set("parse_only", "true")
+
+	resp, err := c.doRequest(ctx, "GET", c.Namespace.Path("/search/parser")+"?"+params.Encode(), nil, "")
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var parsed ParsedSearch
+	if err := json.NewDecoder(resp.Body).Decode(&parsed); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	return &parsed, nil
+}
+
+// ListSavedSearches lists all saved searches
+func (c *Client) ListSavedSearches(ctx context.Context) ([]SavedSearch, error) {
+	return c.getSavedSearches(ctx, c.Namespace.Path("/saved/searches")+"?output_mode=json&count=0")
+}
+
+// GetSavedSearch gets a saved search by name
+func (c *Client) GetSavedSearch(ctx context.Context, name string) (*SavedSearch, error) {
+	searches, err := c.getSavedSearches(ctx, c.savedSearchPath(name)+"?output_mode=json")
+	if err != nil {
+		return nil, err
+	}
+	if len(searches) == 0 {
+		return nil, fmt.Errorf("saved search %q not found", name)
+	}
+	return &searches[0], nil
+}
+
+// getSavedSearches gets the saved searches in a feed of saved search entries
+func (c *Client) getSavedSearches(ctx context.Context, path string) ([]SavedSearch, error) {
+	resp, err := c.doRequest(ctx, "GET", path, nil, "")
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var result struct {
+		Entry []struct {
+			Name    string      `json:"name"`
+			Content SavedSearch `json:"content"`
+		} `json:"entry"`
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	searches := make([]SavedSearch, len(result.Entry))
+	for i, entry := range result.Entry {
+		searches[i] = entry.Content
+		searches[i].Name = entry.Name
+	}
+
+	return searches, nil
+}
+
+// CreateSavedSearch creates a new saved search. If it has a cron schedule, it is also scheduled.
+func (c *Client) CreateSavedSearch(ctx context.Context, search SavedSearch) error {
+	data := search.values()
+	data.Set("name", search.Name)
+
+	resp, err := c.doRequest(ctx, "POST", c.Namespace.Path("/saved/searches"), strings.NewReader(data.Encode()), "application/x-www-form-urlencoded")
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	return nil
+}
+
+// UpdateSavedSearch updates the saved search named search.Name. Empty fields are left unchanged,
+// unless they're in search.Clear.
+func (c *Client) UpdateSavedSearch(ctx context.Context, search SavedSearch) error {
+	data := search.values()
+
+	resp, err := c.doRequest(ctx, "POST", c.savedSearchPath(search.Name), strings.NewReader(data.Encode()), "application/x-www-form-urlencoded")
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	return nil
+}
+
+// DeleteSavedSearch deletes a saved search
+func (c *Client) DeleteSavedSearch(ctx context.Context, name string) error {
+	resp, err := c.doRequest(ctx, "DELETE", c.savedSearchPath(name)+"?output_mode=json", nil, "")
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	return nil
+}
+
+// DispatchSavedSearch starts a job for a saved search and returns its SID. The
+// time range in opts, if set, overrides the saved search's own.
+func (c *Client) DispatchSavedSearch(ctx context.Context, name string, opts SearchOptions) (string, error) {
+	data := url.Values{}
+	data.Set("output_mode", "json")
+	if opts.EarliestTime != "" {
+		data.Set("dispatch.earliest_time", opts.EarliestTime)
+	}
+	if opts.LatestTime != "" {
+		data.Set("dispatch.latest_time", opts.LatestTime)
+	}
+
+	resp, err := c.doRequest(ctx, "POST", c.savedSearchPath(name)+"/dispatch", strings.NewReader(data.Encode()), "application/x-www-form-urlencoded")
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	var result struct {
+		SID string `json:"sid"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return "", fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	return result.SID, nil
+}
+
+// values returns the form values for creating or updating a saved search, omitting empty fields
+func (s SavedSearch) values() url.Values {
+	data := url.Values{}
+	data.Set("output_mode", "json")
+	if s.Search != "" {
+		data.Set("search", s.Search)
+	}
+	if s.Description != "" {
+		data.Set("description", s.Description)
+	}
+	if s.CronSchedule != "" {
+		data.Set("cron_schedule", s.CronSchedule)
+		data.Set("is_scheduled", "1")
+	}
+	if s.EarliestTime != "" {
+		data.Set("dispatch.earliest_time", s.EarliestTime)
+	}
+	if s.LatestTime != "" {
+		data.Set("dispatch.latest_time", s.LatestTime)
+	}
+	for _, field := range s.Clear {
+		data.Set(field, "")
+		if field == "cron_schedule" {
+			data.Set("is_scheduled", "0")
+		}
+	}
+	return data
+}
+
+// savedSearchPath returns the API path of a saved search in the client's namespace
+func (c *Client) savedSearchPath(name string) string {
+	return c.Namespace.Path("/saved/searches/" + url.PathEscape(name))
+}
+
+// ListAlerts lists scheduled searches, which trigger alerts when their conditions are met.
+// Use ListFiredAlerts for the alerts that have actually triggered.
+func (c *Client) ListAlerts(ctx context.Context) ([]Alert, error) {
+	resp, err := c.doRequest(ctx, "GET", c.Namespace.Path("/saved/searches")+"?output_mode=json&count=0&search=is_scheduled%3D1", nil, "")
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var result struct {
+		Entry []struct {
+			Name    string `json:"name"`
+			Content struct {
+				Search       string `json:"search"`
+				Description  string `json:"description"`
+				CronSchedule string `json:"cron_schedule"`
+				Actions      string `json:"actions"`
+			} `json:"content"`
+		} `json:"entry"`
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	alerts := make([]Alert, len(result.Entry))
+	for i, entry := range result.Entry {
+		alerts[i] = Alert{
+			Name:         entry.Name,
+			Search:       entry.Content.Search,
+			Description:  entry.Content.Description,
+			CronSchedule: entry.Content.CronSchedule,
+			Actions:      entry.Content.Actions,
+		}
+	}
+
+	return alerts, nil
+}
+
+// FiredAlertsOptions select the alert triggers to list
+type FiredAlertsOptions struct {
+	// Alert is the name of the alert to list the triggers of, or every alert if unset
+	Alert string
+	// Count is the most triggers to list, 0 for all of them
+	Count int
+}
+
+// ListFiredAlerts lists the alerts that have triggered, most recent first
+func (c *Client) ListFiredAlerts(ctx context.Context, opts FiredAlertsOptions) ([]FiredAlert, error) {
+	// "-" lists the triggers of every alert
+	name := opts.Alert
+	if name == "" {
+		name = "-"
+	}
+	params := url.Values{}
+	params.Set("output_mode", "json")
+	params.Set("count", fmt.Sprint(opts.Count))
+	params.Set("sort_key", "trigger_time")
+	params.Set("sort_dir", "desc")
+
+	resp, err := c.doRequest(ctx, "GET", c.Namespace.Path("/alerts/fired_alerts/"+url.PathEscape(name))+"?"+params.Encode(), nil, "")
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var result struct {
+		Entry []struct {
+			Content FiredAlert `json:"content"`
+		} `json:"entry"`
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	alerts := make([]FiredAlert, len(result.Entry))
+	for i, entry := range result.Entry {
+		alerts[i] = entry.Content
+	}
+
+	return alerts, nil
+}
+
+// ListIndexes lists the event and metrics indexes
+func (c *Client) ListIndexes(ctx context.Context) ([]Index, error) {
+	resp, err := c.doRequest(ctx, "GET", "/services/data/indexes?output_mode=json&count=0&datatype=all", nil, "")
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var result struct {
+		Entry []struct {
+			Name    string `json:"name"`
+			Content struct {
+				DataType               string `json:"datatype"`
+				TotalEventCount        Number `json:"totalEventCount"`
+				CurrentDBSizeMB        Number `json:"currentDBSizeMB"`
+				MaxTotalDataSizeMB     Number `json:"maxTotalDataSizeMB"`
+				FrozenTimePeriodInSecs Number `json:"frozenTimePeriodInSecs"`
+				MinTime                string `json:"minTime"`
+				MaxTime                string `json:"maxTime"`
+				Disabled               Flag   `json:"disabled"`
+			} `json:"content"`
+		} `json:"entry"`
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	indexes := make([]Index, len(result.Entry))
+	for i, entry := range result.Entry {
+		content := entry.Content
+		indexes[i] = Index{
+			Name:            entry.Name,
+			DataType:        content.DataType,
+			TotalEventCount: int64(content.TotalEventCount),
+			CurrentSizeMB:   int64(content.CurrentDBSizeMB),
+			MaxSizeMB:       int64(content.MaxTotalDataSizeMB),
+			RetentionSecs:   int64(content.FrozenTimePeriodInSecs),
+			MinTime:         content.MinTime,
+			MaxTime:         content.MaxTime,
+			Disabled:        bool(content.Disabled),
+		}
+	}
+
+	return indexes, nil
+}
+
+// Request makes an arbitrary REST call, for endpoints the client doesn't wrap, and returns the
+// response body. params go in the query string of GET and DELETE requests, and the form body of
+// others, with output_mode=json unless params set an output mode.
+func (c *Client) Request(ctx context.Context, method, path string, params url.Values) ([]byte, error) {
+	values := url.Values{}
+	for key, v := range params {
+		values[key] = v
+	}
+	if values.Get("output_mode") == "" {
+		values.Set("output_mode", "json")
+	}
+
+	var body io.Reader
+	contentType := ""
+	if method == "GET" || method == "DELETE" || method == "HEAD" {
+		sep := "?"
+		if strings.Contains(path, "?") {
+			sep = "&"
+		}
+		path += sep + values.Encode()
+	} else {
+		body = strings.NewReader(values.Encode())
+		contentType = "application/x-www-form-urlencoded"
+	}
+
+	resp, err := c.doRequest(ctx, method, path, body, contentType)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	return data, nil
+}
+
+// GetServerHealth gets the health of splunkd and its features
+func (c *Client) GetServerHealth(ctx context.Context) (*HealthFeature, error) {
+	resp, err := c.doRequest(ctx, "GET", "/services/server/health/splunkd/details?output_mode=json", nil, "")
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var result struct {
+		Entry []struct {
+			Content HealthFeature `json:"content"`
+		} `json:"entry"`
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	if len(result.Entry) == 0 {
+		return nil, fmt.Errorf("no server health found")
+	}
+	return &result.Entry[0].Content, nil
+}
+
+// GetServerInfo gets Splunk server information
+func (c *Client) GetServerInfo(ctx context.Context) (map[string]interface{}, error) {
+	resp, err := c.doRequest(ctx, "GET", "/services/server/info?output_mode=json", nil, "")
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var result struct {
+		Entry []struct {
+			Content map[string]interface{} `json:"content"`
+		} `json:"entry"`
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	if len(result.Entry) > 0 {
+		return result.Entry[0].Content, nil
+	}
+
+	return nil, fmt.Errorf("no server info found")
+}
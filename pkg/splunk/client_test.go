@@ -0,0 +1,219 @@
+package splunk
+
+import (
+	"compress/gzip"
+	"context"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestClientMiddlewareOrder(t *testing.T) {
+	var header string
+	c := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header = r.Header.Get("X-Test")
+	}))
+
+	var calls []string
+	record := func(name string) Middleware {
+		return func(next RequestFunc) RequestFunc {
+			return func(req *http.Request) (*http.Response, error) {
+				calls = append(calls, name)
+				return next(req)
+			}
+		}
+	}
+	c.Use(record("outer"), WithHeader("X-Test", "value"), record("inner"))
+
+	resp, err := c.doRequest(context.Background(), "GET", "/services/server/info", nil, "")
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	resp.Body.Close()
+
+	if got := strings.Join(calls, ","); got != "outer,inner" {
+		t.Errorf("Expected outer,inner, got: %s", got)
+	}
+	if header != "value" {
+		t.Errorf("Expected header to be set, got: %q", header)
+	}
+}
+
+func TestExportSkipsPreviewRows(t *testing.T) {
+	c := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/services/search/jobs/export" {
+			t.Errorf("Unexpected path: %s", r.URL.Path)
+		}
+		w.Write([]byte(`{"preview":true,"result":{"count":"1"}}
+{"preview":false,"offset":0,"result":{"count":"2"}}
+{"preview":false,"offset":1,"lastrow":true,"result":{"count":"3"}}
+`))
+	}))
+
+	var counts []string
+	err := c.Export(context.Background(), "search *", SearchOptions{}, func(result map[string]interface{}) error {
+		counts = append(counts, result["count"].(string))
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if got := strings.Join(counts, ","); got != "2,3" {
+		t.Errorf("Expected 2,3, got: %s", got)
+	}
+}
+
+func TestCompressedResponses(t *testing.T) {
+	c := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Accept-Encoding") != "gzip" {
+			t.Errorf("Expected gzip to be accepted, got: %q", r.Header.Get("Accept-Encoding"))
+		}
+		w.Header().Set("Content-Encoding", "gzip")
+		z := gzip.NewWriter(w)
+		z.Write([]byte(`{"results":[{"count":"42"}]}`))
+		z.Close()
+	}))
+	// Without compression in the transport, so the client decompresses the response itself
+	c.HTTPClient.Transport = &http.Transport{DisableCompression: true}
+
+	var encoding string
+	c.Use(func(next RequestFunc) RequestFunc {
+		return func(req *http.Request) (*http.Response, error) {
+			resp, err := next(req)
+			encoding = resp.Header.Get("Content-Encoding")
+			return resp, err
+		}
+	})
+
+	results, err := c.OneshotSearch(context.Background(), "search * | stats count", SearchOptions{}, 5)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if len(results.Results) != 1 || results.Results[0]["count"] != "42" || encoding != "" {
+		t.Errorf("Expected decompressed results, got: %+v (Content-Encoding %q)", results.Results, encoding)
+	}
+}
+
+func TestCompressedEmptyResponse(t *testing.T) {
+	c := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Encoding", "gzip")
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	c.HTTPClient.Transport = &http.Transport{DisableCompression: true}
+
+	// An empty body isn't gzipped, so it's an empty body rather than a failure to decompress
+	if err := c.CancelSearch(context.Background(), "123"); err != nil {
+		t.Errorf("Expected no error, got: %v", err)
+	}
+}
+
+func TestOneshotSearch(t *testing.T) {
+	c := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/services/search/jobs" || r.FormValue("exec_mode") != "oneshot" || r.FormValue("count") != "5" {
+			t.Errorf("Unexpected request: %s %v", r.URL.Path, r.Form)
+		}
+		w.Write([]byte(`{"results":[{"count":"42"}]}`))
+	}))
+
+	results, err := c.OneshotSearch(context.Background(), "search * | stats count", SearchOptions{}, 5)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if len(results.Results) != 1 || results.Results[0]["count"] != "42" {
+		t.Errorf("Unexpected results: %+v", results.Results)
+	}
+}
+
+func TestListFiredAlerts(t *testing.T) {
+	c := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/services/alerts/fired_alerts/-" {
+			t.Errorf("Unexpected path: %s", r.URL.Path)
+		}
+		w.Write([]byte(`{"entry":[{"name":"rt_123","content":{"savedsearch_name":"errors","sid":"rt_123","trigger_time":1700000000,"severity":4}}]}`))
+	}))
+
+	alerts, err := c.ListFiredAlerts(context.Background(), FiredAlertsOptions{Count: 10})
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if len(alerts) != 1 || alerts[0].Name != "errors" || alerts[0].TriggerTime != 1700000000 || alerts[0].SeverityName() != "error" {
+		t.Errorf("Unexpected alerts: %+v", alerts)
+	}
+}
+
+func TestListIndexes(t *testing.T) {
+	c := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/services/data/indexes" || r.URL.Query().Get("datatype") != "all" {
+			t.Errorf("Unexpected request: %s %s", r.URL.Path, r.URL.RawQuery)
+		}
+		w.Write([]byte(`{"entry":[
+			{"name":"main","content":{"datatype":"event","totalEventCount":1234,"currentDBSizeMB":56,"maxTotalDataSizeMB":500000,"frozenTimePeriodInSecs":"188697600","minTime":"2024-01-01T00:00:00+00:00","disabled":false}}
+		]}`))
+	}))
+
+	indexes, err := c.ListIndexes(context.Background())
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if len(indexes) != 1 || indexes[0].Name != "main" || indexes[0].TotalEventCount != 1234 || indexes[0].RetentionSecs != 188697600 {
+		t.Errorf("Unexpected indexes: %+v", indexes)
+	}
+}
+
+func TestGetServerHealth(t *testing.T) {
+	c := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/services/server/health/splunkd/details" {
+			t.Errorf("Unexpected path: %s", r.URL.Path)
+		}
+		w.Write([]byte(`{"entry":[{"content":{"health":"yellow","features":{
+			"File Monitor Input":{"health":"yellow","features":{"Forwarder Ingestion Latency":{"health":"yellow"},"Tailreader-0":{"health":"green"}}},
+			"Search Scheduler":{"health":"green"}
+		}}}]}`))
+	}))
+
+	health, err := c.GetServerHealth(context.Background())
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	unhealthy := health.Unhealthy()
+	if health.Health != "yellow" || len(unhealthy) != 1 || unhealthy[0] != "File Monitor Input > Forwarder Ingestion Latency: yellow" {
+		t.Errorf("Unexpected health: %+v %v", health, unhealthy)
+	}
+}
+
+func TestRequest(t *testing.T) {
+	c := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case "GET":
+			if r.URL.Path != "/services/server/info" || r.URL.Query().Get("output_mode") != "json" || r.URL.Query().Get("count") != "1" {
+				t.Errorf("Unexpected request: %s %s", r.URL.Path, r.URL.RawQuery)
+			}
+		case "POST":
+			if r.URL.RawQuery != "" || r.FormValue("output_mode") != "xml" || r.FormValue("disabled") != "1" {
+				t.Errorf("Unexpected request: %s %v", r.URL.RawQuery, r.Form)
+			}
+		}
+		w.Write([]byte(`{"entry":[]}`))
+	}))
+
+	data, err := c.Request(context.Background(), "GET", "/services/server/info", url.Values{"count": {"1"}})
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if string(data) != `{"entry":[]}` {
+		t.Errorf("Unexpected response: %s", data)
+	}
+	if _, err := c.Request(context.Background(), "POST", "/services/saved/searches/errors", url.Values{"output_mode": {"xml"}, "disabled": {"1"}}); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+}
+
+func TestSavedSearchValues(t *testing.T) {
+	search := SavedSearch{Name: "errors", Description: "Errors", Clear: []string{"cron_schedule", "dispatch.earliest_time"}}
+	got := search.values()
+	want := "cron_schedule=&description=Errors&dispatch.earliest_time=&is_scheduled=0&output_mode=json"
+	if got.Encode() != want {
+		t.Errorf("Expected %s, got: %s", want, got.Encode())
+	}
+}
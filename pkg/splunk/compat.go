@@ -0,0 +1,73 @@
+package splunk
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+)
+
+// Splunk versions differ in how they send some values in JSON: newer ones send booleans and
+// numbers as JSON types, while older ones, and some endpoints of every version, send them as
+// strings. Fields that differ decode with these types, which accept either, so responses decode
+// the same whatever the server's version and the client needn't get it with GetServerInfo first.
+// They convert to int64, float64 and bool, e.g. bool(status.Content.IsDone).
+
+// Number is an integer that Splunk sends as a JSON number or a string
+type Number int64
+
+func (n *Number) UnmarshalJSON(data []byte) error {
+	data = bytes.Trim(data, `"`)
+	if len(data) == 0 || string(data) == "null" {
+		*n = 0
+		return nil
+	}
+	var v json.Number
+	if err := json.Unmarshal(data, &v); err != nil {
+		return fmt.Errorf("invalid number %s: %w", data, err)
+	}
+	if i, err := v.Int64(); err == nil {
+		*n = Number(i)
+		return nil
+	}
+	f, err := v.Float64()
+	if err != nil {
+		return fmt.Errorf("invalid number %s: %w", data, err)
+	}
+	*n = Number(f)
+	return nil
+}
+
+// Float is a number with a fraction that Splunk sends as a JSON number or a string
+type Float float64
+
+func (f *Float) UnmarshalJSON(data []byte) error {
+	data = bytes.Trim(data, `"`)
+	if len(data) == 0 || string(data) == "null" {
+		*f = 0
+		return nil
+	}
+	v, err := strconv.ParseFloat(string(data), 64)
+	if err != nil {
+		return fmt.Errorf("invalid number %s", data)
+	}
+	*f = Float(v)
+	return nil
+}
+
+// Flag is a boolean that Splunk sends as a JSON boolean, a number (0 or 1) or a string of either
+type Flag bool
+
+func (f *Flag) UnmarshalJSON(data []byte) error {
+	data = bytes.Trim(data, `"`)
+	if len(data) == 0 || string(data) == "null" {
+		*f = false
+		return nil
+	}
+	b, err := strconv.ParseBool(string(data))
+	if err != nil {
+		return fmt.Errorf("invalid boolean %s", data)
+	}
+	*f = Flag(b)
+	return nil
+}
@@ -0,0 +1,65 @@
+package splunk
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// The fixtures under testdata/compat are the same job, saved search and fired alerts in the shapes Splunk
+// versions send: typed, with JSON booleans and numbers, and strings, with those as strings
+func TestDecodesEveryVersionsShape(t *testing.T) {
+	for _, shape := range []string{"typed", "strings"} {
+		t.Run(shape, func(t *testing.T) {
+			fixtures := map[string]string{
+				"/services/search/jobs/1700000000.1":    "job.json",
+				"/services/search/jobs":                 "jobs.json",
+				"/services/saved/searches/daily_errors": "saved_search.json",
+				"/services/alerts/fired_alerts/-":       "fired_alerts.json",
+			}
+			c := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				data, err := os.ReadFile(filepath.Join("testdata", "compat", shape, fixtures[r.URL.Path]))
+				if err != nil {
+					t.Errorf("No fixture for %s: %v", r.URL.Path, err)
+					w.WriteHeader(http.StatusNotFound)
+					return
+				}
+				w.Write(data)
+			}))
+
+			status, err := c.GetSearchStatus(context.Background(), "1700000000.1")
+			if err != nil {
+				t.Fatalf("Expected no error, got: %v", err)
+			}
+			if !status.Content.IsDone || status.Content.DoneProgress != 1 || status.Content.RunDuration != 0.512 || status.Content.ResultCount != 7 || status.Content.EventCount != 42 {
+				t.Errorf("Unexpected job status: %+v", status.Content)
+			}
+
+			jobs, err := c.ListJobs(context.Background(), 10)
+			if err != nil {
+				t.Fatalf("Expected no error, got: %v", err)
+			}
+			if len(jobs) != 1 || !jobs[0].IsDone || jobs[0].DoneProgress != 1 || jobs[0].RunDuration != 0.512 || jobs[0].ResultCount != 7 {
+				t.Errorf("Unexpected jobs: %+v", jobs)
+			}
+
+			search, err := c.GetSavedSearch(context.Background(), "daily_errors")
+			if err != nil {
+				t.Fatalf("Expected no error, got: %v", err)
+			}
+			if !search.IsScheduled || search.Disabled || search.EarliestTime != "-24h@h" {
+				t.Errorf("Unexpected saved search: %+v", search)
+			}
+
+			alerts, err := c.ListFiredAlerts(context.Background(), FiredAlertsOptions{Count: 10})
+			if err != nil {
+				t.Fatalf("Expected no error, got: %v", err)
+			}
+			if len(alerts) != 1 || alerts[0].TriggerTime != 1700000000 || alerts[0].SeverityName() != "error" {
+				t.Errorf("Unexpected fired alerts: %+v", alerts)
+			}
+		})
+	}
+}
@@ -0,0 +1,26 @@
+// Package splunk is a client for the Splunk REST API: running searches and getting their results,
+// managing search jobs, saved searches and alerts, and sending events to the HTTP Event Collector.
+//
+// Create a client with NewClient, or a Client literal for a server that isn't on port 8089 over
+// https, and add behaviour such as retries, caching and session auth with Use:
+//
+//	client := splunk.NewClient("splunk.example.com", os.Getenv("SPLUNK_TOKEN"))
+//	client.Use(splunk.DefaultRetryPolicy().Middleware)
+//
+//	sid, err := client.RunSearch(ctx, "search index=main error | stats count by host", splunk.SearchOptions{EarliestTime: "-24h"})
+//	if err != nil {
+//		return err
+//	}
+//	if _, err := splunk.NewJobWaiter(client).Wait(ctx, sid); err != nil {
+//		return err
+//	}
+//	results, err := client.GetResults(ctx, sid, splunk.ResultsOptions{})
+//
+// Optional parameters are fields of option types, such as SearchOptions and ResultsOptions, whose
+// zero values leave them to Splunk's defaults. Errors from the API are *APIError, with the status
+// code and the request ID to find the request in splunkd's logs. Fields that Splunk versions send as
+// different JSON types, such as a job's IsDone, DoneProgress and ResultCount, have the types Flag,
+// Float and Number rather than bool, float64 and int64.
+//
+// Code that depends on the API interface rather than *Client can be tested with the splunktest package.
+package splunk
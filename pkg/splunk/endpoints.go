@@ -0,0 +1,33 @@
+package splunk
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// The typed methods of the endpoints in endpoints.json are generated into endpoints_gen.go. To
+// cover another endpoint, describe it in endpoints.json and run go generate from the repository root.
+
+// entry is an entry of an Atom feed, e.g. a saved search or an index
+type entry[T any] struct {
+	Name    string `json:"name"`
+	Content T      `json:"content"`
+}
+
+// getEntries gets the entries of a feed, decoding the content of each as a T
+func getEntries[T any](ctx context.Context, c *Client, path string) ([]entry[T], error) {
+	resp, err := c.doRequest(ctx, "GET", path, nil, "")
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var result struct {
+		Entry []entry[T] `json:"entry"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+	return result.Entry, nil
+}
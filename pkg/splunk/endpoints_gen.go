@@ -0,0 +1,142 @@
+// Code generated by go run ./internal/codegen; DO NOT EDIT.
+
+package splunk
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+)
+
+// App is one of the installed apps, from /apps/local
+type App struct {
+	Name string `json:"name"`
+	// Label is the name shown in Splunk Web
+	Label       string `json:"label"`
+	Version     string `json:"version"`
+	Description string `json:"description"`
+	Author      string `json:"author"`
+	// Visible is whether the app is listed in Splunk Web
+	Visible  bool `json:"visible"`
+	Disabled bool `json:"disabled"`
+}
+
+// ListApps lists the installed apps
+func (c *Client) ListApps(ctx context.Context) ([]App, error) {
+	return getApps(ctx, c, c.Namespace.Path("/apps/local")+"?output_mode=json&count=0")
+}
+
+// GetApp gets the app with the given name
+func (c *Client) GetApp(ctx context.Context, name string) (*App, error) {
+	items, err := getApps(ctx, c, c.Namespace.Path("/apps/local/"+url.PathEscape(name))+"?output_mode=json")
+	if err != nil {
+		return nil, err
+	}
+	if len(items) == 0 {
+		return nil, fmt.Errorf("app %q not found", name)
+	}
+	return &items[0], nil
+}
+
+// getApps gets the installed apps in a feed of app entries
+func getApps(ctx context.Context, c *Client, path string) ([]App, error) {
+	entries, err := getEntries[App](ctx, c, path)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]App, len(entries))
+	for i, entry := range entries {
+		items[i] = entry.Content
+		items[i].Name = entry.Name
+	}
+	return items, nil
+}
+
+// User is one of the users, from /authentication/users
+type User struct {
+	Name       string   `json:"name"`
+	RealName   string   `json:"realname"`
+	Email      string   `json:"email"`
+	Roles      []string `json:"roles"`
+	DefaultApp string   `json:"defaultApp"`
+	// Type is how the user authenticates, e.g. Splunk or SAML
+	Type string `json:"type"`
+}
+
+// ListUsers lists the users
+func (c *Client) ListUsers(ctx context.Context) ([]User, error) {
+	return getUsers(ctx, c, c.Namespace.Path("/authentication/users")+"?output_mode=json&count=0")
+}
+
+// GetUser gets the user with the given name
+func (c *Client) GetUser(ctx context.Context, name string) (*User, error) {
+	items, err := getUsers(ctx, c, c.Namespace.Path("/authentication/users/"+url.PathEscape(name))+"?output_mode=json")
+	if err != nil {
+		return nil, err
+	}
+	if len(items) == 0 {
+		return nil, fmt.Errorf("user %q not found", name)
+	}
+	return &items[0], nil
+}
+
+// getUsers gets the users in a feed of user entries
+func getUsers(ctx context.Context, c *Client, path string) ([]User, error) {
+	entries, err := getEntries[User](ctx, c, path)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]User, len(entries))
+	for i, entry := range entries {
+		items[i] = entry.Content
+		items[i].Name = entry.Name
+	}
+	return items, nil
+}
+
+// Role is one of the roles, from /authorization/roles
+type Role struct {
+	Name string `json:"name"`
+	// ImportedRoles are the roles whose capabilities and indexes the role inherits
+	ImportedRoles []string `json:"imported_roles"`
+	Capabilities  []string `json:"capabilities"`
+	// SearchIndexesAllowed are the indexes the role can search
+	SearchIndexesAllowed []string `json:"srchIndexesAllowed"`
+	// SearchIndexesDefault are the indexes searched when a search doesn't name one
+	SearchIndexesDefault []string `json:"srchIndexesDefault"`
+	// SearchJobsQuota is the number of searches a user with the role can run at once
+	SearchJobsQuota Number `json:"srchJobsQuota"`
+	// SearchDiskQuota is the disk space in MB the role's search results can use
+	SearchDiskQuota Number `json:"srchDiskQuota"`
+}
+
+// ListRoles lists the roles
+func (c *Client) ListRoles(ctx context.Context) ([]Role, error) {
+	return getRoles(ctx, c, c.Namespace.Path("/authorization/roles")+"?output_mode=json&count=0")
+}
+
+// GetRole gets the role with the given name
+func (c *Client) GetRole(ctx context.Context, name string) (*Role, error) {
+	items, err := getRoles(ctx, c, c.Namespace.Path("/authorization/roles/"+url.PathEscape(name))+"?output_mode=json")
+	if err != nil {
+		return nil, err
+	}
+	if len(items) == 0 {
+		return nil, fmt.Errorf("role %q not found", name)
+	}
+	return &items[0], nil
+}
+
+// getRoles gets the roles in a feed of role entries
+func getRoles(ctx context.Context, c *Client, path string) ([]Role, error) {
+	entries, err := getEntries[Role](ctx, c, path)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]Role, len(entries))
+	for i, entry := range entries {
+		items[i] = entry.Content
+		items[i].Name = entry.Name
+	}
+	return items, nil
+}
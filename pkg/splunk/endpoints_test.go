@@ -0,0 +1,25 @@
+package splunk
+
+import (
+	"context"
+	"net/http"
+	"testing"
+)
+
+func TestGeneratedEndpoint(t *testing.T) {
+	c := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/services/authorization/roles/power" {
+			t.Errorf("Unexpected path: %s", r.URL.Path)
+		}
+		// Some versions of Splunk send numbers as strings
+		w.Write([]byte(`{"entry":[{"name":"power","content":{"imported_roles":["user"],"srchJobsQuota":"10","srchDiskQuota":500}}]}`))
+	}))
+
+	role, err := c.GetRole(context.Background(), "power")
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if role.Name != "power" || len(role.ImportedRoles) != 1 || role.SearchJobsQuota != 10 || role.SearchDiskQuota != 500 {
+		t.Errorf("Unexpected role: %+v", role)
+	}
+}
@@ -0,0 +1,58 @@
+package splunk_test
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/kitproj/splunk-cli/pkg/splunk"
+)
+
+func Example() {
+	ctx := context.Background()
+	client := splunk.NewClient("splunk.example.com", os.Getenv("SPLUNK_TOKEN"))
+	client.Use(splunk.DefaultRetryPolicy().Middleware)
+
+	sid, err := client.RunSearch(ctx, "search index=main error | stats count by host", splunk.SearchOptions{EarliestTime: "-24h"})
+	if err != nil {
+		log.Fatal(err)
+	}
+	if _, err := splunk.NewJobWaiter(client).Wait(ctx, sid); err != nil {
+		log.Fatal(err)
+	}
+
+	// Stream the results rather than holding them all in memory
+	_, err = client.StreamResults(ctx, sid, splunk.ResultsOptions{PageSize: 10000}, func(result map[string]interface{}) error {
+		fmt.Println(result["host"], result["count"])
+		return nil
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+func ExampleClient_Export() {
+	client := splunk.NewClient("splunk.example.com", os.Getenv("SPLUNK_TOKEN"))
+	opts := splunk.SearchOptions{EarliestTime: "-1h", Namespace: splunk.Namespace{App: "search"}}
+	err := client.Export(context.Background(), "search index=main", opts, func(event map[string]interface{}) error {
+		fmt.Println(event["_raw"])
+		return nil
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+func ExampleSessionAuth() {
+	client := splunk.NewClient("splunk.example.com", "")
+	auth := &splunk.SessionAuth{Username: "admin", Password: os.Getenv("SPLUNK_PASSWORD")}
+	// Session auth must be the outermost middleware
+	client.Use(auth.Middleware)
+
+	info, err := client.GetServerInfo(context.Background())
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println(info["version"])
+}
@@ -0,0 +1,91 @@
+package splunk
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newTestHECClient(t *testing.T, handler http.Handler) *HECClient {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	c := NewHECClient("localhost", "hec-token")
+	c.BaseURL = server.URL
+	return c
+}
+
+func TestHECSend(t *testing.T) {
+	var lines []string
+	c := newTestHECClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/services/collector/event" {
+			t.Errorf("Unexpected path: %s", r.URL.Path)
+		}
+		if r.Header.Get("Authorization") != "Splunk hec-token" || r.Header.Get("X-Splunk-Request-Channel") == "" {
+			t.Errorf("Expected the HEC token and a channel, got: %v", r.Header)
+		}
+		body, _ := io.ReadAll(r.Body)
+		lines = strings.Split(strings.TrimSpace(string(body)), "\n")
+		fmt.Fprint(w, `{"text":"Success","code":0,"ackId":7}`)
+	}))
+
+	resp, err := c.Send(context.Background(),
+		HECEvent{Time: time.UnixMilli(1700000000500), Index: "main", Event: map[string]interface{}{"status": 500}},
+		HECEvent{Event: "plain text"},
+	)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if resp.AckID == nil || *resp.AckID != 7 {
+		t.Errorf("Expected ack ID 7, got: %+v", resp)
+	}
+	if len(lines) != 2 || lines[0] != `{"time":1700000000.5,"index":"main","event":{"status":500}}` || lines[1] != `{"event":"plain text"}` {
+		t.Errorf("Unexpected events: %q", lines)
+	}
+}
+
+func TestHECSendRawError(t *testing.T) {
+	c := newTestHECClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/services/collector/raw" || r.URL.Query().Get("sourcetype") != "syslog" {
+			t.Errorf("Unexpected request: %s", r.URL)
+		}
+		w.WriteHeader(http.StatusForbidden)
+		fmt.Fprint(w, `{"text":"Invalid token","code":4}`)
+	}))
+
+	_, err := c.SendRaw(context.Background(), strings.NewReader("line 1\nline 2\n"), HECMetadata{Sourcetype: "syslog"})
+	if err == nil || !strings.Contains(err.Error(), "Invalid token (code 4)") {
+		t.Errorf("Expected the collector's error, got: %v", err)
+	}
+}
+
+func TestHECWaitForAcks(t *testing.T) {
+	var polls int
+	c := newTestHECClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Acks []int64 `json:"acks"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+		polls++
+		// Batch 1 is indexed straight away, and batch 2 on the second poll
+		acks := map[string]bool{}
+		for _, id := range req.Acks {
+			acks[fmt.Sprint(id)] = id == 1 || polls > 1
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"acks": acks})
+	}))
+
+	if err := c.WaitForAcks(context.Background(), time.Millisecond, 1, 2); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if polls != 2 {
+		t.Errorf("Expected 2 polls, got: %d", polls)
+	}
+}
@@ -0,0 +1,165 @@
+package splunk
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Job summarises a search job
+type Job struct {
+	SID           string `json:"sid"`
+	Search        string `json:"search"`
+	Owner         string `json:"owner"`
+	Label         string `json:"label"`
+	DispatchState string `json:"dispatchState"`
+	IsDone        Flag   `json:"isDone"`
+	DoneProgress  Float  `json:"doneProgress"`
+	EventCount    Number `json:"eventCount"`
+	ResultCount   Number `json:"resultCount"`
+	RunDuration   Float  `json:"runDuration"`
+	TTL           Number `json:"ttl"`
+	Published     string `json:"published"`
+	// DiskUsage is the size in bytes of the job's artifacts in the search head's dispatch directory
+	DiskUsage Number `json:"diskUsage"`
+	// IsSaved is whether the job was saved, which keeps its artifacts for days rather than minutes
+	IsSaved Flag `json:"isSaved"`
+}
+
+// jobEntry is a search job in the Atom-style feed of /services/search/jobs
+type jobEntry struct {
+	Name      string                 `json:"name"`
+	Author    string                 `json:"author"`
+	Published string                 `json:"published"`
+	Content   map[string]interface{} `json:"content"`
+}
+
+// ListJobs lists the search jobs visible to the user, most recent first. A count of 0 lists every job.
+func (c *Client) ListJobs(ctx context.Context, count int) ([]Job, error) {
+	params := url.Values{}
+	params.Set("output_mode", "json")
+	params.Set("count", fmt.Sprint(count))
+	params.Set("sort_key", "published")
+	params.Set("sort_dir", "desc")
+
+	resp, err := c.doRequest(ctx, "GET", c.Namespace.Path("/search/jobs")+"?"+params.Encode(), nil, "")
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var result struct {
+		Entry []jobEntry `json:"entry"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	jobs := make([]Job, len(result.Entry))
+	for i, entry := range result.Entry {
+		// Round-trip the content so its fields decode with their JSON types
+		data, err := json.Marshal(entry.Content)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode job: %w", err)
+		}
+		if err := json.Unmarshal(data, &jobs[i]); err != nil {
+			return nil, fmt.Errorf("failed to decode job: %w", err)
+		}
+		jobs[i].Search = entry.Name
+		jobs[i].Owner = entry.Author
+		jobs[i].Published = entry.Published
+	}
+
+	return jobs, nil
+}
+
+// InspectJob gets every property of a search job, as reported by Splunk
+func (c *Client) InspectJob(ctx context.Context, sid string) (map[string]interface{}, error) {
+	resp, err := c.doRequest(ctx, "GET", c.Namespace.Path("/search/jobs/"+url.PathEscape(sid))+"?output_mode=json", nil, "")
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var result struct {
+		Entry []jobEntry `json:"entry"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+	if len(result.Entry) == 0 {
+		return nil, fmt.Errorf("search job %s not found", sid)
+	}
+
+	entry := result.Entry[0]
+	properties := entry.Content
+	if properties == nil {
+		properties = map[string]interface{}{}
+	}
+	properties["search"] = entry.Name
+	properties["owner"] = entry.Author
+	properties["published"] = entry.Published
+	return properties, nil
+}
+
+// FinalizeSearch stops a search job, keeping the results it has produced so far
+func (c *Client) FinalizeSearch(ctx context.Context, sid string) error {
+	return c.controlJob(ctx, sid, "finalize")
+}
+
+// controlJob runs an action, such as cancel or finalize, on a search job
+func (c *Client) controlJob(ctx context.Context, sid, action string) error {
+	data := url.Values{}
+	data.Set("action", action)
+	data.Set("output_mode", "json")
+
+	resp, err := c.doRequest(ctx, "POST", c.Namespace.Path("/search/jobs/"+url.PathEscape(sid)+"/control"), strings.NewReader(data.Encode()), "application/x-www-form-urlencoded")
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	return nil
+}
+
+// TimelineBucket is a span of a job's timeline, with the number of events in it
+type TimelineBucket struct {
+	Earliest time.Time     `json:"earliest"`
+	Duration time.Duration `json:"duration"`
+	Count    int64         `json:"count"`
+}
+
+// GetTimeline gets the number of events over time of a job dispatched with StatusBuckets, oldest first
+func (c *Client) GetTimeline(ctx context.Context, sid string) ([]TimelineBucket, error) {
+	resp, err := c.doRequest(ctx, "GET", c.Namespace.Path("/search/jobs/"+url.PathEscape(sid)+"/timeline")+"?output_mode=json", nil, "")
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var result struct {
+		Buckets []struct {
+			EarliestTime Float  `json:"earliest_time"`
+			Duration     Float  `json:"duration"`
+			TotalCount   Number `json:"total_count"`
+		} `json:"buckets"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	buckets := make([]TimelineBucket, len(result.Buckets))
+	for i, b := range result.Buckets {
+		buckets[i] = TimelineBucket{
+			Earliest: time.UnixMilli(int64(b.EarliestTime * 1000)),
+			Duration: time.Duration(float64(b.Duration) * float64(time.Second)),
+			Count:    int64(b.TotalCount),
+		}
+	}
+	sort.Slice(buckets, func(i, j int) bool { return buckets[i].Earliest.Before(buckets[j].Earliest) })
+	return buckets, nil
+}
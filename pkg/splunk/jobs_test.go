@@ -0,0 +1,65 @@
+package splunk
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestListJobs(t *testing.T) {
+	c := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/services/search/jobs" || r.URL.Query().Get("count") != "10" {
+			t.Errorf("Unexpected request: %s", r.URL)
+		}
+		fmt.Fprint(w, `{"entry":[{"name":"search error","author":"admin","content":{"sid":"123","dispatchState":"RUNNING","doneProgress":0.5,"resultCount":7}}]}`)
+	}))
+
+	jobs, err := c.ListJobs(context.Background(), 10)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if len(jobs) != 1 {
+		t.Fatalf("Expected 1 job, got: %d", len(jobs))
+	}
+	job := jobs[0]
+	if job.SID != "123" || job.Search != "search error" || job.Owner != "admin" || job.DoneProgress != 0.5 || job.ResultCount != 7 {
+		t.Errorf("Unexpected job: %+v", job)
+	}
+}
+
+func TestFinalizeSearch(t *testing.T) {
+	c := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/services/search/jobs/123/control" {
+			t.Errorf("Unexpected path: %s", r.URL.Path)
+		}
+		if err := r.ParseForm(); err != nil || r.PostForm.Get("action") != "finalize" {
+			t.Errorf("Expected action=finalize, got: %v", r.PostForm)
+		}
+	}))
+
+	if err := c.FinalizeSearch(context.Background(), "123"); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+}
+
+func TestGetTimeline(t *testing.T) {
+	c := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/services/search/jobs/123/timeline" {
+			t.Errorf("Unexpected path: %s", r.URL.Path)
+		}
+		fmt.Fprint(w, `{"event_count":12,"buckets":[
+			{"earliest_time":"1700003600.000","duration":"3600.000","total_count":"5"},
+			{"earliest_time":1700000000,"duration":3600,"total_count":7}
+		]}`)
+	}))
+
+	buckets, err := c.GetTimeline(context.Background(), "123")
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if len(buckets) != 2 || buckets[0].Earliest.Unix() != 1700000000 || buckets[0].Count != 7 || buckets[1].Count != 5 || buckets[1].Duration != time.Hour {
+		t.Errorf("Expected the buckets oldest first, got: %+v", buckets)
+	}
+}
@@ -0,0 +1,146 @@
+package splunk
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// Message is a message Splunk reports about a search, e.g. a warning that a peer was unavailable
+// so its results may be incomplete
+type Message struct {
+	// Type is Splunk's level of the message, e.g. INFO, WARN or ERROR
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+// Messages are the messages of a search job or of its results
+type Messages []Message
+
+// UnmarshalJSON decodes both shapes Splunk sends messages in: a list of messages in results,
+// e.g. [{"type":"WARN","text":"..."}], and a map of type to text(s) in a job's status, e.g. {"warn":["..."]}
+func (m *Messages) UnmarshalJSON(data []byte) error {
+	var list []Message
+	if err := json.Unmarshal(data, &list); err == nil {
+		*m = list
+		return nil
+	}
+
+	var byType map[string]json.RawMessage
+	if err := json.Unmarshal(data, &byType); err != nil {
+		return fmt.Errorf("invalid messages: %w", err)
+	}
+	types := make([]string, 0, len(byType))
+	for t := range byType {
+		types = append(types, t)
+	}
+	sort.Strings(types)
+
+	*m = nil
+	for _, t := range types {
+		var texts []string
+		if err := json.Unmarshal(byType[t], &texts); err != nil {
+			var text string
+			if err := json.Unmarshal(byType[t], &text); err != nil {
+				return fmt.Errorf("invalid %s messages: %w", t, err)
+			}
+			texts = []string{text}
+		}
+		for _, text := range texts {
+			*m = append(*m, Message{Type: strings.ToUpper(t), Text: text})
+		}
+	}
+	return nil
+}
+
+// Warnings returns the warnings and errors, which mean the results may be partial or wrong
+func (m Messages) Warnings() Messages {
+	var warnings Messages
+	for _, message := range m {
+		switch strings.ToUpper(message.Type) {
+		case "WARN", "WARNING", "ERROR", "FATAL":
+			warnings = append(warnings, message)
+		}
+	}
+	return warnings
+}
+
+// missingResults matches the warnings that mean results are missing, e.g. "The following peer(s)
+// are down", "Search results might be incomplete" or "Subsearch produced 10001 results, truncating"
+var missingResults = regexp.MustCompile(`(?i)unavailable|\bdown\b|unable to distribute|did not respond|timed out|incomplete|truncat|ended prematurely|auto-finalized|end-of-stream`)
+
+// Incomplete returns the errors, and the warnings that mean results are missing. Other warnings,
+// e.g. that a command is deprecated, don't mean the results are partial.
+func (m Messages) Incomplete() Messages {
+	var incomplete Messages
+	for _, message := range m {
+		switch strings.ToUpper(message.Type) {
+		case "ERROR", "FATAL":
+			incomplete = append(incomplete, message)
+		case "WARN", "WARNING":
+			if missingResults.MatchString(message.Text) {
+				incomplete = append(incomplete, message)
+			}
+		}
+	}
+	return incomplete
+}
+
+// maxWarnedJobs is how many jobs' warnings are remembered, so each is reported once, without a
+// long-running process, such as the MCP server, remembering those of every job it has run
+const maxWarnedJobs = 100
+
+// warn calls OnWarning with each warning about the search with the sid that it hasn't been called with yet
+func (c *Client) warn(sid string, messages Messages) {
+	if c.OnWarning == nil {
+		return
+	}
+	c.warnedMu.Lock()
+	var unseen Messages
+	for _, message := range messages.Warnings() {
+		// A oneshot search has no SID, and its results are only got once
+		if sid != "" {
+			if c.warned[sid][message.Text] {
+				continue
+			}
+			c.rememberWarning(sid, message.Text)
+		}
+		unseen = append(unseen, message)
+	}
+	c.warnedMu.Unlock()
+
+	for _, message := range unseen {
+		c.OnWarning(sid, message)
+	}
+}
+
+// rememberWarning records a warning about the job, forgetting those of the oldest job if there
+// are more than maxWarnedJobs. It's called with warnedMu held.
+func (c *Client) rememberWarning(sid, text string) {
+	if c.warned[sid] == nil {
+		if c.warned == nil {
+			c.warned = map[string]map[string]bool{}
+		}
+		c.warned[sid] = map[string]bool{}
+		c.warnedJobs = append(c.warnedJobs, sid)
+		if len(c.warnedJobs) > maxWarnedJobs {
+			delete(c.warned, c.warnedJobs[0])
+			c.warnedJobs = c.warnedJobs[1:]
+		}
+	}
+	c.warned[sid][text] = true
+}
+
+// forgetWarnings forgets the warnings about a job that won't be reported on again
+func (c *Client) forgetWarnings(sid string) {
+	c.warnedMu.Lock()
+	defer c.warnedMu.Unlock()
+	if _, ok := c.warned[sid]; !ok {
+		return
+	}
+	delete(c.warned, sid)
+	c.warnedJobs = slices.DeleteFunc(c.warnedJobs, func(job string) bool { return job == sid })
+}
@@ -0,0 +1,89 @@
+package splunk
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestOnWarning(t *testing.T) {
+	c := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/services/search/jobs/123":
+			w.Write([]byte(`{"sid":"123","content":{"isDone":true,"messages":{"info":["Search took 1s"],"warn":"Peer idx2 was unavailable; results may be incomplete"}}}`))
+		case "/services/search/jobs/123/results":
+			w.Write([]byte(`{"messages":[{"type":"WARN","text":"Peer idx2 was unavailable; results may be incomplete"},{"type":"ERROR","text":"Results truncated"}],"results":[]}`))
+		}
+	}))
+	var warnings []string
+	c.OnWarning = func(sid string, message Message) {
+		warnings = append(warnings, sid+": "+message.Text)
+	}
+
+	waiter := NewJobWaiter(c)
+	waiter.MinInterval = time.Millisecond
+	status, err := waiter.Wait(context.Background(), "123")
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if len(status.Content.Messages) != 2 || status.Content.Messages[0].Type != "INFO" {
+		t.Errorf("Unexpected messages: %+v", status.Content.Messages)
+	}
+	if _, err := c.GetResults(context.Background(), "123", ResultsOptions{}); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	// The peer warning is in both the status and the results, but is reported once
+	if len(warnings) != 2 || warnings[0] != "123: Peer idx2 was unavailable; results may be incomplete" || warnings[1] != "123: Results truncated" {
+		t.Errorf("Unexpected warnings: %q", warnings)
+	}
+}
+
+func TestIncomplete(t *testing.T) {
+	messages := Messages{
+		{Type: "INFO", Text: "Search took 1s"},
+		{Type: "WARN", Text: "The following peer(s) are down: idx2"},
+		{Type: "WARN", Text: "The 'head' command's 'keeplast' argument is deprecated"},
+		{Type: "WARN", Text: "Subsearch produced 10001 results, truncating to maxout 10000"},
+		{Type: "ERROR", Text: "Error in 'lookup' command"},
+	}
+	incomplete := messages.Incomplete()
+	if len(incomplete) != 3 || incomplete[0].Text != messages[1].Text || incomplete[1].Text != messages[3].Text || incomplete[2].Type != "ERROR" {
+		t.Errorf("Expected the peer and truncation warnings and the error, got: %+v", incomplete)
+	}
+}
+
+func TestWarnedJobs(t *testing.T) {
+	c := &Client{}
+	warnings := 0
+	c.OnWarning = func(sid string, message Message) {
+		warnings++
+	}
+	peerDown := Messages{{Type: "WARN", Text: "Peer idx2 was unavailable"}}
+
+	// Only the warnings of the latest jobs are remembered
+	for i := range maxWarnedJobs + 1 {
+		c.warn(fmt.Sprint(i), peerDown)
+	}
+	if len(c.warned) != maxWarnedJobs || len(c.warnedJobs) != maxWarnedJobs || c.warned["0"] != nil {
+		t.Errorf("Expected the oldest job to be forgotten, got %d jobs", len(c.warned))
+	}
+	c.warn("1", peerDown)
+	if warnings != maxWarnedJobs+1 {
+		t.Errorf("Expected a remembered warning not to be reported again, got %d warnings", warnings)
+	}
+
+	// A oneshot search's warnings are always reported, and not remembered
+	c.warn("", peerDown)
+	c.warn("", peerDown)
+	if warnings != maxWarnedJobs+3 || c.warned[""] != nil {
+		t.Errorf("Expected each oneshot search's warnings to be reported, got %d warnings", warnings)
+	}
+
+	c.forgetWarnings("1")
+	if c.warned["1"] != nil || len(c.warnedJobs) != maxWarnedJobs-1 {
+		t.Errorf("Expected the job's warnings to be forgotten, got %d jobs", len(c.warnedJobs))
+	}
+}
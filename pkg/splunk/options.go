@@ -0,0 +1,100 @@
+package splunk
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// Namespace is the owner and app context a request runs in. The zero value is the global /services namespace.
+type Namespace struct {
+	Owner string
+	App   string
+}
+
+// Path returns the API path of an endpoint (e.g. "/search/jobs") in the namespace
+func (n Namespace) Path(endpoint string) string {
+	if n.Owner == "" && n.App == "" {
+		return "/services" + endpoint
+	}
+
+	// "-" is Splunk's wildcard for any owner or app
+	owner, app := n.Owner, n.App
+	if owner == "" {
+		owner = "-"
+	}
+	if app == "" {
+		app = "-"
+	}
+	return fmt.Sprintf("/servicesNS/%s/%s%s", url.PathEscape(owner), url.PathEscape(app), endpoint)
+}
+
+// SearchOptions are the dispatch options of a search job. Zero values are left to Splunk's defaults.
+type SearchOptions struct {
+	EarliestTime string
+	LatestTime   string
+	// ExecMode is "normal", "blocking" or "oneshot"
+	ExecMode string
+	// MaxCount is the maximum number of results the job keeps
+	MaxCount int
+	// StatusBuckets is the number of timeline buckets to generate
+	StatusBuckets int
+	// RequiredFields are fields to extract even if the search doesn't reference them
+	RequiredFields []string
+	// AdhocSearchLevel is "fast", "smart" or "verbose"
+	AdhocSearchLevel string
+	// SampleRatio runs the search over 1 in every SampleRatio events
+	SampleRatio int
+	// Timeout is the number of seconds to keep the job after processing stops
+	Timeout int
+	// SearchMode is "normal" or "realtime", which needs real-time times such as "rt-5m" and "rt"
+	SearchMode string
+	// Namespace is the owner and app context the job runs in, the client's if unset
+	Namespace Namespace
+}
+
+// namespace returns n, or the client's namespace if n is the zero value
+func (c *Client) namespace(n Namespace) Namespace {
+	if n == (Namespace{}) {
+		return c.Namespace
+	}
+	return n
+}
+
+// values returns the form values for dispatching a search
+func (o SearchOptions) values(searchQuery string) url.Values {
+	data := url.Values{}
+	data.Set("search", searchQuery)
+	data.Set("output_mode", "json")
+	if o.EarliestTime != "" {
+		data.Set("earliest_time", o.EarliestTime)
+	}
+	if o.LatestTime != "" {
+		data.Set("latest_time", o.LatestTime)
+	}
+	if o.ExecMode != "" {
+		data.Set("exec_mode", o.ExecMode)
+	}
+	if o.MaxCount > 0 {
+		data.Set("max_count", fmt.Sprint(o.MaxCount))
+	}
+	if o.StatusBuckets > 0 {
+		data.Set("status_buckets", fmt.Sprint(o.StatusBuckets))
+	}
+	if len(o.RequiredFields) > 0 {
+		data.Set("rf", strings.Join(o.RequiredFields, ","))
+	}
+	if o.AdhocSearchLevel != "" {
+		data.Set("adhoc_search_level", o.AdhocSearchLevel)
+	}
+	if o.SampleRatio > 0 {
+		data.Set("sample_ratio", fmt.Sprint(o.SampleRatio))
+	}
+	if o.Timeout > 0 {
+		data.Set("timeout", fmt.Sprint(o.Timeout))
+	}
+	if o.SearchMode != "" {
+		data.Set("search_mode", o.SearchMode)
+	}
+	return data
+}
@@ -0,0 +1,92 @@
+package splunk
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestNamespacePath(t *testing.T) {
+	tests := []struct {
+		namespace Namespace
+		want      string
+	}{
+		{Namespace{}, "/services/search/jobs"},
+		{Namespace{App: "search"}, "/servicesNS/-/search/search/jobs"},
+		{Namespace{Owner: "admin", App: "my app"}, "/servicesNS/admin/my%20app/search/jobs"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.namespace.Path("/search/jobs"); got != tt.want {
+			t.Errorf("%+v: expected %s, got: %s", tt.namespace, tt.want, got)
+		}
+	}
+}
+
+func TestSearchOptionsValues(t *testing.T) {
+	opts := SearchOptions{
+		EarliestTime:     "-1h",
+		ExecMode:         "blocking",
+		RequiredFields:   []string{"host", "status"},
+		AdhocSearchLevel: "fast",
+	}
+
+	got := opts.values("search error").Encode()
+	want := "adhoc_search_level=fast&earliest_time=-1h&exec_mode=blocking&output_mode=json&rf=host%2Cstatus&search=search+error"
+	if got != want {
+		t.Errorf("Expected %s, got: %s", want, got)
+	}
+}
+
+func TestClientNamespace(t *testing.T) {
+	var paths []string
+	c := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.Method+" "+r.URL.EscapedPath())
+		w.Write([]byte(`{"entry":[{"name":"errors","content":{"search":"index=main error"}}],"sid":"123"}`))
+	}))
+	c.Namespace = Namespace{Owner: "nobody", App: "security"}
+
+	ctx := context.Background()
+	if _, err := c.GetSavedSearch(ctx, "errors"); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.CreateSavedSearch(ctx, SavedSearch{Name: "errors", Search: "index=main error"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.DispatchSavedSearch(ctx, "errors", SearchOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.RunSearch(ctx, "search error", SearchOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.RunSearch(ctx, "search error", SearchOptions{Namespace: Namespace{App: "search"}}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.GetSearchStatus(ctx, "123"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.GetResults(ctx, "123", ResultsOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.ParseSearch(ctx, "search error"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.ListApps(ctx); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"GET /servicesNS/nobody/security/saved/searches/errors",
+		"POST /servicesNS/nobody/security/saved/searches",
+		"POST /servicesNS/nobody/security/saved/searches/errors/dispatch",
+		"POST /servicesNS/nobody/security/search/jobs",
+		"POST /servicesNS/-/search/search/jobs",
+		"GET /servicesNS/nobody/security/search/jobs/123",
+		"GET /servicesNS/nobody/security/search/jobs/123/results",
+		"GET /servicesNS/nobody/security/search/parser",
+		"GET /servicesNS/nobody/security/apps/local",
+	}
+	if !reflect.DeepEqual(paths, want) {
+		t.Errorf("Expected %v, got %v", want, paths)
+	}
+}
@@ -0,0 +1,48 @@
+package splunk
+
+import (
+	"crypto"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+
+	"software.sslmate.com/src/go-pkcs12"
+)
+
+// decodePKCS12 decodes a PKCS#12 bundle of a private key and its certificate chain, as
+// exported by openssl pkcs12 -export or a browser, e.g. encrypted with AES (the default of
+// OpenSSL 3), triple DES or the legacy RC2 of older tools.
+func decodePKCS12(data []byte, password string) (tls.Certificate, error) {
+	key, leaf, chain, err := pkcs12.DecodeChain(data, password)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+	signer, ok := key.(crypto.Signer)
+	if !ok {
+		return tls.Certificate{}, fmt.Errorf("PKCS#12 bundle's private key is a %T, which can't sign", key)
+	}
+	public, ok := signer.Public().(interface{ Equal(crypto.PublicKey) bool })
+	if !ok {
+		return tls.Certificate{}, fmt.Errorf("PKCS#12 bundle's private key has an unsupported public key %T", signer.Public())
+	}
+
+	// The leaf is the certificate of the private key, which bundles needn't list first, and the
+	// rest of the bundle is its chain
+	certs := append([]*x509.Certificate{leaf}, chain...)
+	cert := tls.Certificate{PrivateKey: key}
+	for i, c := range certs {
+		if public.Equal(c.PublicKey) {
+			cert.Leaf = c
+			certs = append(certs[:i], certs[i+1:]...)
+			break
+		}
+	}
+	if cert.Leaf == nil {
+		return tls.Certificate{}, fmt.Errorf("PKCS#12 bundle has no certificate for its private key")
+	}
+	cert.Certificate = append(cert.Certificate, cert.Leaf.Raw)
+	for _, c := range certs {
+		cert.Certificate = append(cert.Certificate, c.Raw)
+	}
+	return cert, nil
+}
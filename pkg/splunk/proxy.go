@@ -0,0 +1,66 @@
+package splunk
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// ParseProxy parses the URL of an HTTP, HTTPS or SOCKS5 proxy, e.g. socks5://localhost:1080.
+// A proxy without a scheme is an HTTP proxy, e.g. proxy.example.com:3128.
+func ParseProxy(proxy string) (*url.URL, error) {
+	if !strings.Contains(proxy, "://") {
+		proxy = "http://" + proxy
+	}
+	u, err := url.Parse(proxy)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse proxy: %w", err)
+	}
+	switch u.Scheme {
+	case "http", "https", "socks5", "socks5h":
+	default:
+		return nil, fmt.Errorf("unknown proxy scheme %q (must be http, https, socks5 or socks5h)", u.Scheme)
+	}
+	if u.Host == "" {
+		return nil, fmt.Errorf("proxy %q has no host", proxy)
+	}
+	return u, nil
+}
+
+// ConfigureProxy makes the client connect through a proxy, instead of those in the HTTP_PROXY,
+// HTTPS_PROXY and NO_PROXY environment variables. Call it after ConfigureTLS, which replaces the transport.
+func (c *Client) ConfigureProxy(proxy string) error {
+	transport, err := proxyTransport(c.HTTPClient.Transport, proxy)
+	if err != nil {
+		return err
+	}
+	c.HTTPClient.Transport = transport
+	return nil
+}
+
+// ConfigureProxy makes the client connect through a proxy, instead of those in the HTTP_PROXY,
+// HTTPS_PROXY and NO_PROXY environment variables. Call it after ConfigureTLS, which replaces the transport.
+func (c *HECClient) ConfigureProxy(proxy string) error {
+	transport, err := proxyTransport(c.HTTPClient.Transport, proxy)
+	if err != nil {
+		return err
+	}
+	c.HTTPClient.Transport = transport
+	return nil
+}
+
+// proxyTransport returns a copy of a transport, or the default one if it's nil, that uses a proxy
+func proxyTransport(rt http.RoundTripper, proxy string) (*http.Transport, error) {
+	u, err := ParseProxy(proxy)
+	if err != nil {
+		return nil, err
+	}
+	transport, ok := rt.(*http.Transport)
+	if !ok || transport == nil {
+		transport = http.DefaultTransport.(*http.Transport)
+	}
+	transport = transport.Clone()
+	transport.Proxy = http.ProxyURL(u)
+	return transport, nil
+}
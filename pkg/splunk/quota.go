@@ -0,0 +1,154 @@
+package splunk
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"time"
+)
+
+// CurrentUser gets the name and roles of the authenticated user
+func (c *Client) CurrentUser(ctx context.Context) (string, []string, error) {
+	resp, err := c.doRequest(ctx, "GET", "/services/authentication/current-context?output_mode=json", nil, "")
+	if err != nil {
+		return "", nil, err
+	}
+	defer resp.Body.Close()
+
+	var result struct {
+		Entry []struct {
+			Content struct {
+				Username string   `json:"username"`
+				Roles    []string `json:"roles"`
+			} `json:"content"`
+		} `json:"entry"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return "", nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+	if len(result.Entry) == 0 {
+		return "", nil, fmt.Errorf("no current user")
+	}
+	return result.Entry[0].Content.Username, result.Entry[0].Content.Roles, nil
+}
+
+// SearchJobQuota gets the number of concurrent search jobs the roles allow, which is the largest of their quotas
+func (c *Client) SearchJobQuota(ctx context.Context, roles []string) (int, error) {
+	quota := 0
+	for _, role := range roles {
+		resp, err := c.doRequest(ctx, "GET", "/services/authorization/roles/"+url.PathEscape(role)+"?output_mode=json", nil, "")
+		if err != nil {
+			return 0, err
+		}
+
+		var result struct {
+			Entry []struct {
+				Content struct {
+					SrchJobsQuota int `json:"srchJobsQuota"`
+				} `json:"content"`
+			} `json:"entry"`
+		}
+		err = json.NewDecoder(resp.Body).Decode(&result)
+		resp.Body.Close()
+		if err != nil {
+			return 0, fmt.Errorf("failed to decode response: %w", err)
+		}
+		for _, entry := range result.Entry {
+			if entry.Content.SrchJobsQuota > quota {
+				quota = entry.Content.SrchJobsQuota
+			}
+		}
+	}
+	return quota, nil
+}
+
+// RunningJobs counts the user's search jobs that are still running, queued or finalizing. Splunk
+// filters them and only the feed's total is read, so it's cheap however many jobs there are.
+func (c *Client) RunningJobs(ctx context.Context, user string) (int, error) {
+	params := url.Values{}
+	params.Set("output_mode", "json")
+	params.Set("count", "1")
+	params.Set("search", fmt.Sprintf("isDone=0 eai:acl.owner=%q", user))
+
+	// Jobs in every app count towards the quota, whatever the client's namespace
+	resp, err := c.doRequest(ctx, "GET", "/services/search/jobs?"+params.Encode(), nil, "")
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	var result struct {
+		Paging struct {
+			Total Number `json:"total"`
+		} `json:"paging"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return 0, fmt.Errorf("failed to decode response: %w", err)
+	}
+	return int(result.Paging.Total), nil
+}
+
+// JobQuota is the part of the REST API that a JobThrottle uses to count the user's search jobs
+// against their quota
+type JobQuota interface {
+	CurrentUser(ctx context.Context) (string, []string, error)
+	SearchJobQuota(ctx context.Context, roles []string) (int, error)
+	RunningJobs(ctx context.Context, user string) (int, error)
+}
+
+// JobThrottle holds back dispatching search jobs while the user is using more
+// than a share of their concurrent search job quota, so batches of searches
+// don't fail with quota errors or starve the user's other work
+type JobThrottle struct {
+	Client JobQuota
+	// Share is the fraction of the quota to stay under, e.g. 0.5
+	Share float64
+	// Interval is the delay between checks of the running jobs while throttled
+	Interval time.Duration
+	// OnWait, if set, is called with the running jobs and the limit each time the throttle waits
+	OnWait func(running, limit int)
+
+	user  string
+	limit int
+}
+
+// NewJobThrottle creates a JobThrottle that keeps the user's running jobs under share of their quota
+func NewJobThrottle(client JobQuota, share float64) *JobThrottle {
+	return &JobThrottle{Client: client, Share: share, Interval: 2 * time.Second}
+}
+
+// Wait blocks until the user has fewer running jobs than their share of the quota, or ctx is done
+func (t *JobThrottle) Wait(ctx context.Context) error {
+	if t.limit == 0 {
+		user, roles, err := t.Client.CurrentUser(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to get current user: %w", err)
+		}
+		quota, err := t.Client.SearchJobQuota(ctx, roles)
+		if err != nil {
+			return fmt.Errorf("failed to get search job quota: %w", err)
+		}
+		t.user = user
+		// Always allow at least one job, or nothing would ever run
+		t.limit = max(int(float64(quota)*t.Share), 1)
+	}
+
+	for {
+		running, err := t.Client.RunningJobs(ctx, t.user)
+		if err != nil {
+			return fmt.Errorf("failed to count running jobs: %w", err)
+		}
+		if running < t.limit {
+			return nil
+		}
+		if t.OnWait != nil {
+			t.OnWait(running, t.limit)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(t.Interval):
+		}
+	}
+}
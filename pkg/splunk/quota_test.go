@@ -0,0 +1,53 @@
+package splunk
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestJobThrottleWaitsForQuota(t *testing.T) {
+	polls := 0
+	c := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/services/authentication/current-context":
+			fmt.Fprint(w, `{"entry":[{"content":{"username":"alice","roles":["user","power"]}}]}`)
+		case "/services/authorization/roles/user":
+			fmt.Fprint(w, `{"entry":[{"content":{"srchJobsQuota":3}}]}`)
+		case "/services/authorization/roles/power":
+			fmt.Fprint(w, `{"entry":[{"content":{"srchJobsQuota":4}}]}`)
+		case "/services/search/jobs":
+			// alice has 2 running jobs, then 1, of which only the first page is sent
+			if search := r.URL.Query().Get("search"); search != `isDone=0 eai:acl.owner="alice"` {
+				t.Errorf("Expected alice's unfinished jobs to be filtered on the server, got: %s", search)
+			}
+			polls++
+			total := 2
+			if polls > 1 {
+				total = 1
+			}
+			fmt.Fprintf(w, `{"paging":{"total":%d,"perPage":1,"offset":0},"entry":[{"author":"alice","content":{"isDone":false}}]}`, total)
+		default:
+			t.Errorf("Unexpected path: %s", r.URL.Path)
+		}
+	}))
+
+	throttle := NewJobThrottle(c, 0.5)
+	throttle.Interval = time.Millisecond
+	var waits int
+	throttle.OnWait = func(running, limit int) {
+		waits++
+		if running != 2 || limit != 2 {
+			t.Errorf("Expected 2 running jobs with a limit of 2, got: %d, %d", running, limit)
+		}
+	}
+
+	if err := throttle.Wait(context.Background()); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if waits != 1 || polls != 2 {
+		t.Errorf("Expected to wait once, got: %d waits, %d polls", waits, polls)
+	}
+}
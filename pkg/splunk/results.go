@@ -0,0 +1,161 @@
+package splunk
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+)
+
+// ResultsOptions select the results of a search job to get
+type ResultsOptions struct {
+	// Offset is the index of the first result, and Count the most to get, 0 for all of them
+	Offset int
+	Count  int
+	// PostProcess is a search to run over the results server-side, e.g. "| stats count by status"
+	PostProcess string
+	// Fields, if set, are the only fields of each result to get
+	Fields []string
+	// Preview gets the results the job has produced so far, rather than those of a completed job
+	Preview bool
+	// PageSize, if set, gets the results in requests of at most this many until Count are got or
+	// they run out, as one request returns at most the server's maxresultrows, 50,000 by default
+	PageSize int
+}
+
+// StreamResults gets the results of a search job, calling fn with each as it's decoded rather than
+// holding them all in memory, and returns the messages Splunk sent with them
+func (c *Client) StreamResults(ctx context.Context, sid string, opts ResultsOptions, fn func(map[string]interface{}) error) (Messages, error) {
+	if opts.PageSize <= 0 {
+		return c.streamPage(ctx, sid, opts, fn)
+	}
+
+	var messages Messages
+	for got := 0; opts.Count == 0 || got < opts.Count; {
+		page := opts
+		page.Offset, page.Count = opts.Offset+got, opts.PageSize
+		if opts.Count > 0 {
+			page.Count = min(opts.PageSize, opts.Count-got)
+		}
+		n := 0
+		pageMessages, err := c.streamPage(ctx, sid, page, func(row map[string]interface{}) error {
+			n++
+			return fn(row)
+		})
+		if err != nil {
+			return nil, err
+		}
+		messages = append(messages, pageMessages...)
+		got += n
+		// A page may be short of the count if the server's maxresultrows is lower, so only an empty
+		// one means the results have run out
+		if n == 0 {
+			break
+		}
+	}
+	return messages, nil
+}
+
+// streamPage gets the results of a search job in one request
+func (c *Client) streamPage(ctx context.Context, sid string, opts ResultsOptions, fn func(map[string]interface{}) error) (Messages, error) {
+	params := url.Values{}
+	params.Set("output_mode", "json")
+	params.Set("count", fmt.Sprint(opts.Count))
+	if opts.Offset > 0 {
+		params.Set("offset", fmt.Sprint(opts.Offset))
+	}
+	if opts.PostProcess != "" {
+		params.Set("search", opts.PostProcess)
+	}
+	for _, field := range opts.Fields {
+		params.Add("f", field)
+	}
+	endpoint := "results"
+	if opts.Preview {
+		endpoint = "results_preview"
+	}
+
+	resp, err := c.doRequest(ctx, "GET", c.Namespace.Path("/search/jobs/"+url.PathEscape(sid)+"/"+endpoint)+"?"+params.Encode(), nil, "")
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	messages, err := decodeResults(resp.Body, fn)
+	if err != nil {
+		return nil, err
+	}
+	if !opts.Preview {
+		c.warn(sid, messages)
+	}
+	return messages, nil
+}
+
+// GetResults gets the results of a search job into memory
+func (c *Client) GetResults(ctx context.Context, sid string, opts ResultsOptions) (*SearchResult, error) {
+	result := &SearchResult{Results: []map[string]interface{}{}}
+	messages, err := c.StreamResults(ctx, sid, opts, func(row map[string]interface{}) error {
+		result.Results = append(result.Results, row)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	result.Messages = messages
+	return result, nil
+}
+
+// decodeResults decodes a results response a result at a time, skipping all but the results and
+// messages. A job without results may have an empty response.
+func decodeResults(r io.Reader, fn func(map[string]interface{}) error) (Messages, error) {
+	decoder := json.NewDecoder(r)
+	if tok, err := decoder.Token(); err == io.EOF {
+		return nil, nil
+	} else if err != nil || tok != json.Delim('{') {
+		return nil, fmt.Errorf("failed to decode response: expected an object, got %v", orError(tok, err))
+	}
+
+	var messages Messages
+	for decoder.More() {
+		key, err := decoder.Token()
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode response: %w", err)
+		}
+		switch key {
+		case "results":
+			if tok, err := decoder.Token(); err != nil || tok != json.Delim('[') {
+				return nil, fmt.Errorf("failed to decode response: expected a list of results, got %v", orError(tok, err))
+			}
+			for decoder.More() {
+				var row map[string]interface{}
+				if err := decoder.Decode(&row); err != nil {
+					return nil, fmt.Errorf("failed to decode response: %w", err)
+				}
+				if err := fn(row); err != nil {
+					return nil, err
+				}
+			}
+			if _, err := decoder.Token(); err != nil {
+				return nil, fmt.Errorf("failed to decode response: %w", err)
+			}
+		case "messages":
+			if err := decoder.Decode(&messages); err != nil {
+				return nil, fmt.Errorf("failed to decode response: %w", err)
+			}
+		default:
+			var skip json.RawMessage
+			if err := decoder.Decode(&skip); err != nil {
+				return nil, fmt.Errorf("failed to decode response: %w", err)
+			}
+		}
+	}
+	return messages, nil
+}
+
+func orError(tok json.Token, err error) interface{} {
+	if err != nil {
+		return err
+	}
+	return tok
+}
@@ -0,0 +1,96 @@
+package splunk
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestStreamResults(t *testing.T) {
+	c := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/services/search/jobs/123/results_preview" || r.FormValue("offset") != "2" || r.FormValue("count") != "0" || strings.Join(r.Form["f"], ",") != "n,host" {
+			t.Errorf("Unexpected request: %s", r.URL)
+		}
+		fmt.Fprint(w, `{"preview":true,"init_offset":2,"fields":[{"name":"n"}],"results":[{"n":"1"},{"n":"2"},{"n":"3"}],"highlighted":{},"messages":[{"type":"WARN","text":"peer down"}]}`)
+	}))
+
+	var rows []string
+	messages, err := c.StreamResults(context.Background(), "123", ResultsOptions{Offset: 2, Preview: true, Fields: []string{"n", "host"}}, func(row map[string]interface{}) error {
+		rows = append(rows, row["n"].(string))
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if strings.Join(rows, ",") != "1,2,3" || len(messages) != 1 || messages[0].Text != "peer down" {
+		t.Errorf("Unexpected results %v and messages %v", rows, messages)
+	}
+}
+
+func TestStreamResultsPages(t *testing.T) {
+	var pages []string
+	maxResultRows := 0
+	c := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		offset, _ := strconv.Atoi(r.FormValue("offset"))
+		count, _ := strconv.Atoi(r.FormValue("count"))
+		pages = append(pages, fmt.Sprintf("%d+%d", offset, count))
+		if maxResultRows > 0 {
+			count = min(count, maxResultRows)
+		}
+		var rows []string
+		// The job has 25 results
+		for i := offset; i < min(offset+count, 25); i++ {
+			rows = append(rows, fmt.Sprintf(`{"n":"%d"}`, i))
+		}
+		fmt.Fprintf(w, `{"results":[%s]}`, strings.Join(rows, ","))
+	}))
+
+	tests := []struct {
+		opts          ResultsOptions
+		maxResultRows int
+		rows          int
+		pages         string
+	}{
+		{ResultsOptions{PageSize: 10}, 0, 25, "0+10,10+10,20+10,25+10"},
+		{ResultsOptions{Offset: 5, Count: 12, PageSize: 10}, 0, 12, "5+10,15+2"},
+		{ResultsOptions{Count: 20, PageSize: 10}, 0, 20, "0+10,10+10"},
+		// Servers with a lower maxresultrows return short pages
+		{ResultsOptions{PageSize: 10}, 7, 25, "0+10,7+10,14+10,21+10,25+10"},
+	}
+	for _, tt := range tests {
+		pages, maxResultRows = nil, tt.maxResultRows
+		result, err := c.GetResults(context.Background(), "123", tt.opts)
+		if err != nil {
+			t.Fatalf("Expected no error, got: %v", err)
+		}
+		if len(result.Results) != tt.rows || strings.Join(pages, ",") != tt.pages {
+			t.Errorf("Expected %d results in pages %s for %+v, got %d in %s", tt.rows, tt.pages, tt.opts, len(result.Results), strings.Join(pages, ","))
+		}
+	}
+}
+
+func TestDecodeResults(t *testing.T) {
+	// A job without results may have an empty response
+	if messages, err := decodeResults(strings.NewReader(""), nil); err != nil || messages != nil {
+		t.Errorf("Expected no results, got: %v, %v", messages, err)
+	}
+
+	// Decoding stops at the first row fn fails on
+	stop := errors.New("stop")
+	var decoded int
+	_, err := decodeResults(strings.NewReader(`{"results":[{"n":"1"},{"n":"2"},not json`), func(row map[string]interface{}) error {
+		decoded++
+		return stop
+	})
+	if !errors.Is(err, stop) || decoded != 1 {
+		t.Errorf("Expected to stop after the first row, got %d rows and: %v", decoded, err)
+	}
+
+	if _, err := decodeResults(strings.NewReader(`["not", "results"]`), nil); err == nil {
+		t.Error("Expected an error for a response that isn't an object")
+	}
+}
@@ -0,0 +1,127 @@
+package splunk
+
+import (
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// RetryPolicy retries requests that fail transiently, such as when splunkd is restarting or
+// rate limits the client, with an exponential backoff
+type RetryPolicy struct {
+	// MaxRetries is how many times a request is retried after the first attempt; 0 disables retries
+	MaxRetries int
+	// Backoff is the delay before the first retry, which doubles with each retry up to MaxBackoff.
+	// A delay is randomly shortened by up to Jitter of itself, e.g. 0.5, so clients don't retry in step.
+	Backoff    time.Duration
+	MaxBackoff time.Duration
+	Jitter     float64
+	// OnRetry, if set, is called before each retry with the reason and the delay before it
+	OnRetry func(req *http.Request, attempt int, delay time.Duration, reason string)
+}
+
+// DefaultRetryPolicy returns the policy of clients that aren't configured otherwise
+func DefaultRetryPolicy() *RetryPolicy {
+	return &RetryPolicy{MaxRetries: 2, Backoff: time.Second, MaxBackoff: 30 * time.Second, Jitter: 0.5}
+}
+
+// Middleware retries requests that fail with a network error or a 500, 502, 503 or 504 if they are
+// idempotent (GET, HEAD, OPTIONS, PUT and DELETE), and any request that is rate limited with a 429, as
+// splunkd didn't act on it. A Retry-After header is honored, unless it asks for longer than MaxBackoff.
+// Requests with bodies are only retried if the body can be sent again.
+func (p *RetryPolicy) Middleware(next RequestFunc) RequestFunc {
+	return func(req *http.Request) (*http.Response, error) {
+		for attempt := 1; ; attempt++ {
+			resp, err := next(req)
+			if attempt > p.MaxRetries || (req.Body != nil && req.Body != http.NoBody && req.GetBody == nil) {
+				return resp, err
+			}
+
+			reason, delay := "", p.backoff(attempt)
+			switch {
+			case err != nil:
+				if !idempotent(req.Method) || req.Context().Err() != nil {
+					return resp, err
+				}
+				reason = err.Error()
+			case resp.StatusCode == http.StatusTooManyRequests || (retryableStatus(resp.StatusCode) && idempotent(req.Method)):
+				reason = resp.Status
+				if after, ok := retryAfter(resp.Header.Get("Retry-After")); ok {
+					if after > p.MaxBackoff {
+						return resp, nil
+					}
+					delay = after
+				}
+				_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+				resp.Body.Close()
+			default:
+				return resp, nil
+			}
+			if p.OnRetry != nil {
+				p.OnRetry(req, attempt, delay, reason)
+			}
+
+			timer := time.NewTimer(delay)
+			select {
+			case <-req.Context().Done():
+				timer.Stop()
+				return nil, req.Context().Err()
+			case <-timer.C:
+			}
+
+			if req.GetBody != nil {
+				retry := req.Clone(req.Context())
+				if retry.Body, err = req.GetBody(); err != nil {
+					return nil, fmt.Errorf("failed to rewind request body: %w", err)
+				}
+				req = retry
+			}
+		}
+	}
+}
+
+// backoff returns the delay before a retry, with jitter
+func (p *RetryPolicy) backoff(attempt int) time.Duration {
+	delay := p.Backoff
+	for i := 1; i < attempt && delay < p.MaxBackoff; i++ {
+		delay *= 2
+	}
+	delay = min(delay, p.MaxBackoff)
+	if p.Jitter > 0 {
+		delay -= time.Duration(rand.Float64() * p.Jitter * float64(delay))
+	}
+	return delay
+}
+
+func idempotent(method string) bool {
+	switch method {
+	case "GET", "HEAD", "OPTIONS", "PUT", "DELETE":
+		return true
+	}
+	return false
+}
+
+func retryableStatus(code int) bool {
+	switch code {
+	case http.StatusInternalServerError, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// retryAfter parses a Retry-After header, which is either a number of seconds or an HTTP date
+func retryAfter(value string) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if t, err := http.ParseTime(value); err == nil {
+		return max(time.Until(t), 0), true
+	}
+	return 0, false
+}
@@ -0,0 +1,84 @@
+package splunk
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestRetryPolicy(t *testing.T) {
+	tests := []struct {
+		name     string
+		status   []int
+		post     bool
+		wantErr  bool
+		attempts int
+	}{
+		{name: "succeeds after transient errors", status: []int{503, 502}, attempts: 3},
+		{name: "gives up after max retries", status: []int{503, 503, 503}, wantErr: true, attempts: 3},
+		{name: "rate limited POST is retried", status: []int{429}, post: true, attempts: 2},
+		{name: "failed POST isn't retried", status: []int{503}, post: true, wantErr: true, attempts: 1},
+		{name: "client errors aren't retried", status: []int{404}, wantErr: true, attempts: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var attempts int
+			c := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				attempts++
+				if tt.post && r.FormValue("search") != "search error" {
+					t.Errorf("Expected the form to be sent again, got: %v", r.Form)
+				}
+				if attempts <= len(tt.status) {
+					w.Header().Set("Retry-After", "0")
+					w.WriteHeader(tt.status[attempts-1])
+					return
+				}
+				fmt.Fprint(w, `{"sid":"123","content":{"isDone":true}}`)
+			}))
+			c.Use((&RetryPolicy{MaxRetries: 2, Backoff: time.Millisecond, MaxBackoff: time.Second}).Middleware)
+
+			var err error
+			if tt.post {
+				_, err = c.RunSearch(context.Background(), "search error", SearchOptions{})
+			} else {
+				_, err = c.GetSearchStatus(context.Background(), "123")
+			}
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Expected error %t, got: %v", tt.wantErr, err)
+			}
+			if attempts != tt.attempts {
+				t.Errorf("Expected %d attempts, got %d", tt.attempts, attempts)
+			}
+		})
+	}
+}
+
+func TestRetryPolicyBackoff(t *testing.T) {
+	p := &RetryPolicy{Backoff: time.Second, MaxBackoff: 5 * time.Second}
+	for attempt, want := range []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 5 * time.Second} {
+		if got := p.backoff(attempt + 1); got != want {
+			t.Errorf("Expected a backoff of %s before retry %d, got %s", want, attempt+1, got)
+		}
+	}
+
+	p.Jitter = 0.5
+	for range 100 {
+		if got := p.backoff(2); got <= time.Second || got > 2*time.Second {
+			t.Fatalf("Expected a jittered backoff in (1s, 2s], got %s", got)
+		}
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	if d, ok := retryAfter("120"); !ok || d != 2*time.Minute {
+		t.Errorf("Expected 2m, got %s, %t", d, ok)
+	}
+	if d, ok := retryAfter(time.Now().Add(time.Hour).UTC().Format(http.TimeFormat)); !ok || d < 59*time.Minute {
+		t.Errorf("Expected about an hour, got %s, %t", d, ok)
+	}
+	if _, ok := retryAfter("soon"); ok {
+		t.Error("Expected an invalid Retry-After to be ignored")
+	}
+}
@@ -0,0 +1,115 @@
+package splunk
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+// SessionAuth authenticates with a username and password instead of a token. It
+// logs in for a session key, and logs in again whenever the session expires.
+type SessionAuth struct {
+	Username string
+	Password string
+	// SessionKey, if set, is a previously cached session key to start with. It's replaced by each new one.
+	SessionKey string
+	// OnLogin, if set, is called with each new session key, e.g. to cache it for the next run
+	OnLogin func(key string)
+
+	mu sync.Mutex
+}
+
+// Middleware authenticates every request with the session key. It must be the
+// outermost middleware, so others (such as the response cache) see the key.
+func (a *SessionAuth) Middleware(next RequestFunc) RequestFunc {
+	return func(req *http.Request) (*http.Response, error) {
+		key, err := a.sessionKey(req, next, "")
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Authorization", "Splunk "+key)
+		resp, err := next(req)
+		if err != nil || resp.StatusCode != http.StatusUnauthorized {
+			return resp, err
+		}
+
+		// The session expired, so log in again and retry, if the body can be sent again
+		if req.Body != nil && req.GetBody == nil {
+			return resp, nil
+		}
+		resp.Body.Close()
+		if key, err = a.sessionKey(req, next, key); err != nil {
+			return nil, err
+		}
+		retry := req.Clone(req.Context())
+		if req.GetBody != nil {
+			if retry.Body, err = req.GetBody(); err != nil {
+				return nil, err
+			}
+		}
+		retry.Header.Set("Authorization", "Splunk "+key)
+		return next(retry)
+	}
+}
+
+// sessionKey returns the current session key, logging in if there isn't one or it is the rejected key
+func (a *SessionAuth) sessionKey(req *http.Request, next RequestFunc, rejected string) (string, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	// Another request may already have logged in again
+	if a.SessionKey != "" && a.SessionKey != rejected {
+		return a.SessionKey, nil
+	}
+
+	key, err := a.login(req, next)
+	if err != nil {
+		return "", err
+	}
+	a.SessionKey = key
+	if a.OnLogin != nil {
+		a.OnLogin(key)
+	}
+	return key, nil
+}
+
+// login logs in to the server req is sent to and returns the new session key
+func (a *SessionAuth) login(req *http.Request, next RequestFunc) (string, error) {
+	data := url.Values{}
+	data.Set("username", a.Username)
+	data.Set("password", a.Password)
+	data.Set("output_mode", "json")
+
+	loginURL := url.URL{Scheme: req.URL.Scheme, Host: req.URL.Host, Path: "/services/auth/login"}
+	login, err := http.NewRequestWithContext(req.Context(), "POST", loginURL.String(), strings.NewReader(data.Encode()))
+	if err != nil {
+		return "", fmt.Errorf("failed to create login request: %w", err)
+	}
+	login.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := next(login)
+	if err != nil {
+		return "", fmt.Errorf("failed to log in: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return "", fmt.Errorf("failed to log in as %s: %w", a.Username, &APIError{StatusCode: resp.StatusCode, Body: string(body)})
+	}
+
+	var result struct {
+		SessionKey string `json:"sessionKey"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return "", fmt.Errorf("failed to decode login response: %w", err)
+	}
+	if result.SessionKey == "" {
+		return "", fmt.Errorf("failed to log in as %s: no session key returned", a.Username)
+	}
+	return result.SessionKey, nil
+}
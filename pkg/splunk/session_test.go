@@ -0,0 +1,44 @@
+package splunk
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+func TestSessionAuthRenewsExpiredKey(t *testing.T) {
+	var logins int
+	c := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/services/auth/login" {
+			logins++
+			if r.FormValue("username") != "admin" || r.FormValue("password") != "changeme" {
+				t.Errorf("Unexpected credentials: %v", r.Form)
+			}
+			fmt.Fprint(w, `{"sessionKey":"fresh"}`)
+			return
+		}
+		if r.Header.Get("Authorization") != "Splunk fresh" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if r.FormValue("search") != "search error" {
+			t.Errorf("Expected the form to be sent again, got: %v", r.Form)
+		}
+		fmt.Fprint(w, `{"sid":"123"}`)
+	}))
+
+	auth := &SessionAuth{Username: "admin", Password: "changeme", SessionKey: "expired"}
+	var saved string
+	auth.OnLogin = func(key string) { saved = key }
+	c.Token = ""
+	c.Middleware = append([]Middleware{auth.Middleware}, c.Middleware...)
+
+	sid, err := c.RunSearch(context.Background(), "search error", SearchOptions{})
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if sid != "123" || logins != 1 || saved != "fresh" {
+		t.Errorf("Expected one login and a retried search, got: sid %q, %d logins, saved %q", sid, logins, saved)
+	}
+}
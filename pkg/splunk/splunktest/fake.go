@@ -0,0 +1,210 @@
+// Package splunktest helps test code that uses the splunk package without a live Splunk: Fake is
+// an API whose methods are stubbed with funcs, and Server is a fixture REST API for a real Client.
+package splunktest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/kitproj/splunk-cli/pkg/splunk"
+)
+
+// ErrNotImplemented is returned by a Fake's methods that have no func set
+var ErrNotImplemented = errors.New("not implemented by the fake")
+
+// Fake is a splunk.API whose methods call the funcs of the same name, e.g. RunSearch calls
+// RunSearchFunc, or return ErrNotImplemented if it isn't set
+type Fake struct {
+	RunSearchFunc           func(ctx context.Context, searchQuery string, opts splunk.SearchOptions) (string, error)
+	OneshotSearchFunc       func(ctx context.Context, searchQuery string, opts splunk.SearchOptions, count int) (*splunk.SearchResult, error)
+	ExportFunc              func(ctx context.Context, searchQuery string, opts splunk.SearchOptions, fn func(map[string]interface{}) error) error
+	ParseSearchFunc         func(ctx context.Context, searchQuery string) (*splunk.ParsedSearch, error)
+	ResolveTimeFunc         func(ctx context.Context, modifier string) (time.Time, error)
+	GetSearchStatusFunc     func(ctx context.Context, sid string) (*splunk.Search, error)
+	GetResultsFunc          func(ctx context.Context, sid string, opts splunk.ResultsOptions) (*splunk.SearchResult, error)
+	StreamResultsFunc       func(ctx context.Context, sid string, opts splunk.ResultsOptions, fn func(map[string]interface{}) error) (splunk.Messages, error)
+	ListJobsFunc            func(ctx context.Context, count int) ([]splunk.Job, error)
+	InspectJobFunc          func(ctx context.Context, sid string) (map[string]interface{}, error)
+	CancelSearchFunc        func(ctx context.Context, sid string) error
+	FinalizeSearchFunc      func(ctx context.Context, sid string) error
+	ListSavedSearchesFunc   func(ctx context.Context) ([]splunk.SavedSearch, error)
+	GetSavedSearchFunc      func(ctx context.Context, name string) (*splunk.SavedSearch, error)
+	CreateSavedSearchFunc   func(ctx context.Context, search splunk.SavedSearch) error
+	UpdateSavedSearchFunc   func(ctx context.Context, search splunk.SavedSearch) error
+	DeleteSavedSearchFunc   func(ctx context.Context, name string) error
+	DispatchSavedSearchFunc func(ctx context.Context, name string, opts splunk.SearchOptions) (string, error)
+	ListAlertsFunc          func(ctx context.Context) ([]splunk.Alert, error)
+	ListFiredAlertsFunc     func(ctx context.Context, opts splunk.FiredAlertsOptions) ([]splunk.FiredAlert, error)
+	ListIndexesFunc         func(ctx context.Context) ([]splunk.Index, error)
+	GetServerInfoFunc       func(ctx context.Context) (map[string]interface{}, error)
+	GetServerHealthFunc     func(ctx context.Context) (*splunk.HealthFeature, error)
+}
+
+var _ splunk.API = (*Fake)(nil)
+
+func notImplemented(method string) error {
+	return fmt.Errorf("%s: %w", method, ErrNotImplemented)
+}
+
+func (f *Fake) RunSearch(ctx context.Context, searchQuery string, opts splunk.SearchOptions) (string, error) {
+	if f.RunSearchFunc == nil {
+		return "", notImplemented("RunSearch")
+	}
+	return f.RunSearchFunc(ctx, searchQuery, opts)
+}
+
+func (f *Fake) OneshotSearch(ctx context.Context, searchQuery string, opts splunk.SearchOptions, count int) (*splunk.SearchResult, error) {
+	if f.OneshotSearchFunc == nil {
+		return nil, notImplemented("OneshotSearch")
+	}
+	return f.OneshotSearchFunc(ctx, searchQuery, opts, count)
+}
+
+func (f *Fake) Export(ctx context.Context, searchQuery string, opts splunk.SearchOptions, fn func(map[string]interface{}) error) error {
+	if f.ExportFunc == nil {
+		return notImplemented("Export")
+	}
+	return f.ExportFunc(ctx, searchQuery, opts, fn)
+}
+
+func (f *Fake) ParseSearch(ctx context.Context, searchQuery string) (*splunk.ParsedSearch, error) {
+	if f.ParseSearchFunc == nil {
+		return nil, notImplemented("ParseSearch")
+	}
+	return f.ParseSearchFunc(ctx, searchQuery)
+}
+
+func (f *Fake) ResolveTime(ctx context.Context, modifier string) (time.Time, error) {
+	if f.ResolveTimeFunc == nil {
+		return time.Time{}, notImplemented("ResolveTime")
+	}
+	return f.ResolveTimeFunc(ctx, modifier)
+}
+
+func (f *Fake) GetSearchStatus(ctx context.Context, sid string) (*splunk.Search, error) {
+	if f.GetSearchStatusFunc == nil {
+		return nil, notImplemented("GetSearchStatus")
+	}
+	return f.GetSearchStatusFunc(ctx, sid)
+}
+
+func (f *Fake) GetResults(ctx context.Context, sid string, opts splunk.ResultsOptions) (*splunk.SearchResult, error) {
+	if f.GetResultsFunc == nil {
+		return nil, notImplemented("GetResults")
+	}
+	return f.GetResultsFunc(ctx, sid, opts)
+}
+
+func (f *Fake) StreamResults(ctx context.Context, sid string, opts splunk.ResultsOptions, fn func(map[string]interface{}) error) (splunk.Messages, error) {
+	if f.StreamResultsFunc == nil {
+		return nil, notImplemented("StreamResults")
+	}
+	return f.StreamResultsFunc(ctx, sid, opts, fn)
+}
+
+func (f *Fake) ListJobs(ctx context.Context, count int) ([]splunk.Job, error) {
+	if f.ListJobsFunc == nil {
+		return nil, notImplemented("ListJobs")
+	}
+	return f.ListJobsFunc(ctx, count)
+}
+
+func (f *Fake) InspectJob(ctx context.Context, sid string) (map[string]interface{}, error) {
+	if f.InspectJobFunc == nil {
+		return nil, notImplemented("InspectJob")
+	}
+	return f.InspectJobFunc(ctx, sid)
+}
+
+func (f *Fake) CancelSearch(ctx context.Context, sid string) error {
+	if f.CancelSearchFunc == nil {
+		return notImplemented("CancelSearch")
+	}
+	return f.CancelSearchFunc(ctx, sid)
+}
+
+func (f *Fake) FinalizeSearch(ctx context.Context, sid string) error {
+	if f.FinalizeSearchFunc == nil {
+		return notImplemented("FinalizeSearch")
+	}
+	return f.FinalizeSearchFunc(ctx, sid)
+}
+
+func (f *Fake) ListSavedSearches(ctx context.Context) ([]splunk.SavedSearch, error) {
+	if f.ListSavedSearchesFunc == nil {
+		return nil, notImplemented("ListSavedSearches")
+	}
+	return f.ListSavedSearchesFunc(ctx)
+}
+
+func (f *Fake) GetSavedSearch(ctx context.Context, name string) (*splunk.SavedSearch, error) {
+	if f.GetSavedSearchFunc == nil {
+		return nil, notImplemented("GetSavedSearch")
+	}
+	return f.GetSavedSearchFunc(ctx, name)
+}
+
+func (f *Fake) CreateSavedSearch(ctx context.Context, search splunk.SavedSearch) error {
+	if f.CreateSavedSearchFunc == nil {
+		return notImplemented("CreateSavedSearch")
+	}
+	return f.CreateSavedSearchFunc(ctx, search)
+}
+
+func (f *Fake) UpdateSavedSearch(ctx context.Context, search splunk.SavedSearch) error {
+	if f.UpdateSavedSearchFunc == nil {
+		return notImplemented("UpdateSavedSearch")
+	}
+	return f.UpdateSavedSearchFunc(ctx, search)
+}
+
+func (f *Fake) DeleteSavedSearch(ctx context.Context, name string) error {
+	if f.DeleteSavedSearchFunc == nil {
+		return notImplemented("DeleteSavedSearch")
+	}
+	return f.DeleteSavedSearchFunc(ctx, name)
+}
+
+func (f *Fake) DispatchSavedSearch(ctx context.Context, name string, opts splunk.SearchOptions) (string, error) {
+	if f.DispatchSavedSearchFunc == nil {
+		return "", notImplemented("DispatchSavedSearch")
+	}
+	return f.DispatchSavedSearchFunc(ctx, name, opts)
+}
+
+func (f *Fake) ListAlerts(ctx context.Context) ([]splunk.Alert, error) {
+	if f.ListAlertsFunc == nil {
+		return nil, notImplemented("ListAlerts")
+	}
+	return f.ListAlertsFunc(ctx)
+}
+
+func (f *Fake) ListFiredAlerts(ctx context.Context, opts splunk.FiredAlertsOptions) ([]splunk.FiredAlert, error) {
+	if f.ListFiredAlertsFunc == nil {
+		return nil, notImplemented("ListFiredAlerts")
+	}
+	return f.ListFiredAlertsFunc(ctx, opts)
+}
+
+func (f *Fake) ListIndexes(ctx context.Context) ([]splunk.Index, error) {
+	if f.ListIndexesFunc == nil {
+		return nil, notImplemented("ListIndexes")
+	}
+	return f.ListIndexesFunc(ctx)
+}
+
+func (f *Fake) GetServerInfo(ctx context.Context) (map[string]interface{}, error) {
+	if f.GetServerInfoFunc == nil {
+		return nil, notImplemented("GetServerInfo")
+	}
+	return f.GetServerInfoFunc(ctx)
+}
+
+func (f *Fake) GetServerHealth(ctx context.Context) (*splunk.HealthFeature, error) {
+	if f.GetServerHealthFunc == nil {
+		return nil, notImplemented("GetServerHealth")
+	}
+	return f.GetServerHealthFunc(ctx)
+}
@@ -0,0 +1,148 @@
+package splunktest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/kitproj/splunk-cli/pkg/splunk"
+)
+
+// Server is a fixture REST API, for testing code end to end through a real Client. Requests that
+// match no handler fail the test.
+type Server struct {
+	*httptest.Server
+	mux *http.ServeMux
+
+	mu   sync.Mutex
+	jobs []*job
+	next int
+}
+
+// job is a search job the server runs, dispatched in the order they were added
+type job struct {
+	sid       string
+	results   []map[string]interface{}
+	cancelled bool
+}
+
+// NewServer starts a Server, which is closed when the test ends
+func NewServer(t testing.TB) *Server {
+	t.Helper()
+	s := &Server{mux: http.NewServeMux()}
+	s.mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("Unexpected request: %s %s", r.Method, r.URL)
+		http.NotFound(w, r)
+	})
+	s.Server = httptest.NewServer(s.mux)
+	t.Cleanup(s.Close)
+	return s
+}
+
+// Client returns a Client of the server
+func (s *Server) Client() *splunk.Client {
+	c := splunk.NewClient("localhost", "test-token")
+	c.BaseURL = s.URL
+	return c
+}
+
+// Handle responds to requests matching pattern, such as "GET /services/data/indexes", with status and body
+func (s *Server) Handle(pattern string, status int, body string) {
+	s.mux.HandleFunc(pattern, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		fmt.Fprint(w, body)
+	})
+}
+
+// HandleFunc responds to requests matching pattern with handler
+func (s *Server) HandleFunc(pattern string, handler http.HandlerFunc) {
+	s.mux.HandleFunc(pattern, handler)
+}
+
+// AddJob adds a search job that is done as soon as it's dispatched, with results. Searches,
+// including oneshot ones, are given the jobs in the order they were added.
+func (s *Server) AddJob(sid string, results ...map[string]interface{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.jobs) == 0 {
+		s.handleJobs("POST", "", s.dispatch)
+	}
+	j := &job{sid: sid, results: results}
+	if j.results == nil {
+		j.results = []map[string]interface{}{}
+	}
+	s.jobs = append(s.jobs, j)
+
+	s.handleJobs("GET", "/"+sid, func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, map[string]interface{}{"sid": sid, "content": map[string]interface{}{
+			"sid": sid, "isDone": true, "dispatchState": "DONE", "doneProgress": 1, "resultCount": len(j.results),
+		}})
+	})
+	s.handleJobs("GET", "/"+sid+"/results", j.serveResults)
+	s.handleJobs("GET", "/"+sid+"/results_preview", j.serveResults)
+	s.handleJobs("POST", "/"+sid+"/control", func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		j.cancelled = j.cancelled || r.FormValue("action") == "cancel"
+		s.mu.Unlock()
+		writeJSON(w, map[string]interface{}{})
+	})
+}
+
+// handleJobs responds to requests for a search jobs endpoint, in the global namespace or any other
+func (s *Server) handleJobs(method, endpoint string, handler http.HandlerFunc) {
+	s.mux.HandleFunc(method+" /services/search/jobs"+endpoint, handler)
+	s.mux.HandleFunc(method+" /servicesNS/{owner}/{app}/search/jobs"+endpoint, handler)
+}
+
+// Cancelled reports whether the job with sid has been cancelled
+func (s *Server) Cancelled(sid string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, j := range s.jobs {
+		if j.sid == sid {
+			return j.cancelled
+		}
+	}
+	return false
+}
+
+// dispatch starts the next job, or returns its results straight away for a oneshot search
+func (s *Server) dispatch(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	if s.next >= len(s.jobs) {
+		s.mu.Unlock()
+		http.Error(w, `{"messages":[{"type":"FATAL","text":"no more fixture jobs"}]}`, http.StatusInternalServerError)
+		return
+	}
+	j := s.jobs[s.next]
+	s.next++
+	s.mu.Unlock()
+
+	if r.FormValue("exec_mode") == "oneshot" {
+		writeJSON(w, map[string]interface{}{"results": j.results})
+		return
+	}
+	writeJSON(w, map[string]interface{}{"sid": j.sid})
+}
+
+// serveResults serves the page of the job's results the offset and count parameters select
+func (j *job) serveResults(w http.ResponseWriter, r *http.Request) {
+	offset, _ := strconv.Atoi(r.FormValue("offset"))
+	count, _ := strconv.Atoi(r.FormValue("count"))
+	start := min(offset, len(j.results))
+	end := len(j.results)
+	if count > 0 {
+		end = min(start+count, end)
+	}
+	writeJSON(w, map[string]interface{}{"init_offset": start, "results": j.results[start:end]})
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(v)
+}
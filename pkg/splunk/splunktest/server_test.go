@@ -0,0 +1,48 @@
+package splunktest
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/kitproj/splunk-cli/pkg/splunk"
+)
+
+func TestServer(t *testing.T) {
+	s := NewServer(t)
+	var rows []map[string]interface{}
+	for i := 0; i < 25; i++ {
+		rows = append(rows, map[string]interface{}{"n": fmt.Sprint(i)})
+	}
+	s.AddJob("1.1", rows...)
+	s.AddJob("1.2", map[string]interface{}{"host": "web-1"})
+	c := s.Client()
+	ctx := context.Background()
+
+	sid, err := c.RunSearch(ctx, "search index=main", splunk.SearchOptions{Namespace: splunk.Namespace{App: "search"}})
+	if err != nil || sid != "1.1" {
+		t.Fatalf("Expected the first job, got %q and: %v", sid, err)
+	}
+	status, err := splunk.NewJobWaiter(c).Wait(ctx, sid)
+	if err != nil || status.Content.ResultCount != 25 {
+		t.Fatalf("Expected the job to be done with 25 results, got %+v and: %v", status, err)
+	}
+	results, err := c.GetResults(ctx, sid, splunk.ResultsOptions{Offset: 5, PageSize: 10})
+	if err != nil || len(results.Results) != 20 || results.Results[0]["n"] != "5" {
+		t.Errorf("Expected results 5 to 24, got %v and: %v", results, err)
+	}
+	if err := c.CancelSearch(ctx, sid); err != nil || !s.Cancelled(sid) {
+		t.Errorf("Expected the job to be cancelled, got: %v", err)
+	}
+
+	results, err = c.OneshotSearch(ctx, "search index=web", splunk.SearchOptions{}, 0)
+	if err != nil || len(results.Results) != 1 || results.Results[0]["host"] != "web-1" {
+		t.Errorf("Expected the second job's results, got %v and: %v", results, err)
+	}
+
+	s.Handle("GET /services/server/info", 200, `{"entry":[{"content":{"version":"9.2.0"}}]}`)
+	info, err := c.GetServerInfo(ctx)
+	if err != nil || info["version"] != "9.2.0" {
+		t.Errorf("Expected the server info, got %v and: %v", info, err)
+	}
+}
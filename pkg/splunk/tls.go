@@ -0,0 +1,100 @@
+package splunk
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// TLSOptions configures the TLS connection to the management API
+type TLSOptions struct {
+	// CertFile and KeyFile are a PEM client certificate and key, for servers that require mutual TLS.
+	// KeyFile may be empty if CertFile holds both. A CertFile ending in .p12 or .pfx is a
+	// PKCS#12 bundle of both, encrypted with Password.
+	CertFile string
+	KeyFile  string
+	Password string
+	// CAFile is a PEM bundle of CA certificates to trust as well as the system's, e.g. a corporate CA
+	CAFile string
+	// Insecure skips verifying the server's certificate, e.g. for lab instances with self-signed certificates
+	Insecure bool
+}
+
+// ConfigureTLS replaces the client's transport with one using opts
+func (c *Client) ConfigureTLS(opts TLSOptions) error {
+	transport, err := NewTLSTransport(opts)
+	if err != nil {
+		return err
+	}
+	c.HTTPClient.Transport = transport
+	return nil
+}
+
+// ConfigureTLS replaces the client's transport with one using opts
+func (c *HECClient) ConfigureTLS(opts TLSOptions) error {
+	transport, err := NewTLSTransport(opts)
+	if err != nil {
+		return err
+	}
+	c.HTTPClient.Transport = transport
+	return nil
+}
+
+// NewTLSTransport returns a copy of the default transport that uses opts
+func NewTLSTransport(opts TLSOptions) (*http.Transport, error) {
+	config := &tls.Config{InsecureSkipVerify: opts.Insecure}
+	if opts.CAFile != "" {
+		pem, err := os.ReadFile(opts.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA certificates: %w", err)
+		}
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("failed to load CA certificates: no PEM certificates in %s", opts.CAFile)
+		}
+		config.RootCAs = pool
+	}
+	if opts.CertFile != "" {
+		cert, err := LoadClientCertificate(opts.CertFile, opts.KeyFile, opts.Password)
+		if err != nil {
+			return nil, err
+		}
+		config.Certificates = []tls.Certificate{cert}
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = config
+	return transport, nil
+}
+
+// LoadClientCertificate loads a client certificate from PEM files or a PKCS#12 bundle
+func LoadClientCertificate(certFile, keyFile, password string) (tls.Certificate, error) {
+	switch strings.ToLower(filepath.Ext(certFile)) {
+	case ".p12", ".pfx":
+		data, err := os.ReadFile(certFile)
+		if err != nil {
+			return tls.Certificate{}, fmt.Errorf("failed to read client certificate: %w", err)
+		}
+		cert, err := decodePKCS12(data, password)
+		if err != nil {
+			return tls.Certificate{}, fmt.Errorf("failed to load client certificate %s: %w", certFile, err)
+		}
+		return cert, nil
+	}
+
+	if keyFile == "" {
+		keyFile = certFile
+	}
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return tls.Certificate{}, fmt.Errorf("failed to load client certificate %s: %w", certFile, err)
+	}
+	return cert, nil
+}
@@ -0,0 +1,149 @@
+package splunk
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"software.sslmate.com/src/go-pkcs12"
+)
+
+// writeClientCertificate writes a self-signed client certificate and its key as PEM files
+func writeClientCertificate(t *testing.T, dir string) (certFile, keyFile string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "splunk-cli"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	certFile = filepath.Join(dir, "client.crt")
+	keyFile = filepath.Join(dir, "client.key")
+	if err := os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDER}), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return certFile, keyFile
+}
+
+func TestLoadClientCertificatePEM(t *testing.T) {
+	certFile, keyFile := writeClientCertificate(t, t.TempDir())
+
+	cert, err := LoadClientCertificate(certFile, keyFile, "")
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if len(cert.Certificate) != 1 || cert.PrivateKey == nil {
+		t.Errorf("Expected a certificate and key, got: %+v", cert)
+	}
+
+	if _, err := LoadClientCertificate(certFile, "", ""); err == nil {
+		t.Error("Expected an error for a certificate without a key")
+	}
+}
+
+func TestLoadClientCertificatePKCS12(t *testing.T) {
+	openssl, err := exec.LookPath("openssl")
+	if err != nil {
+		t.Skip("openssl is required to create PKCS#12 bundles")
+	}
+	dir := t.TempDir()
+	certFile, keyFile := writeClientCertificate(t, dir)
+
+	for name, args := range map[string][]string{
+		"aes":  nil,
+		"3des": {"-keypbe", "PBE-SHA1-3DES", "-certpbe", "PBE-SHA1-3DES", "-macalg", "sha1"},
+		"rc2":  {"-legacy"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			bundle := filepath.Join(dir, name+".p12")
+			cmd := exec.Command(openssl, append([]string{"pkcs12", "-export", "-in", certFile, "-inkey", keyFile, "-out", bundle, "-passout", "pass:secret"}, args...)...)
+			if out, err := cmd.CombinedOutput(); err != nil {
+				t.Skipf("openssl can't create the bundle: %v: %s", err, out)
+			}
+
+			cert, err := LoadClientCertificate(bundle, "", "secret")
+			if err != nil {
+				t.Fatalf("Expected no error, got: %v", err)
+			}
+			if cert.Leaf == nil || cert.Leaf.Subject.CommonName != "splunk-cli" || cert.PrivateKey == nil {
+				t.Errorf("Expected the client certificate and key, got: %+v", cert)
+			}
+
+			if _, err := LoadClientCertificate(bundle, "", "wrong"); !errors.Is(err, pkcs12.ErrIncorrectPassword) {
+				t.Errorf("Expected an incorrect password error, got: %v", err)
+			}
+		})
+	}
+}
+
+func TestConfigureTLS(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile := writeClientCertificate(t, dir)
+
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"entry":[{"content":{"serverName":"lab"}}]}`))
+	}))
+	server.TLS = &tls.Config{ClientAuth: tls.RequireAnyClientCert}
+	server.StartTLS()
+	defer server.Close()
+
+	caFile := filepath.Join(dir, "ca.pem")
+	if err := os.WriteFile(caFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw}), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	for name, tc := range map[string]struct {
+		opts    TLSOptions
+		wantErr bool
+	}{
+		"untrusted":      {opts: TLSOptions{CertFile: certFile, KeyFile: keyFile}, wantErr: true},
+		"no client cert": {opts: TLSOptions{CAFile: caFile}, wantErr: true},
+		"ca":             {opts: TLSOptions{CertFile: certFile, KeyFile: keyFile, CAFile: caFile}},
+		"insecure":       {opts: TLSOptions{CertFile: certFile, KeyFile: keyFile, Insecure: true}},
+	} {
+		t.Run(name, func(t *testing.T) {
+			c := NewClient("localhost", "test-token")
+			c.BaseURL = server.URL
+			if err := c.ConfigureTLS(tc.opts); err != nil {
+				t.Fatalf("Expected no error, got: %v", err)
+			}
+			_, err := c.GetServerInfo(context.Background())
+			if tc.wantErr && err == nil {
+				t.Error("Expected the connection to fail")
+			} else if !tc.wantErr && err != nil {
+				t.Errorf("Expected no error, got: %v", err)
+			}
+		})
+	}
+}
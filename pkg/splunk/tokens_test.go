@@ -0,0 +1,61 @@
+package splunk
+
+import (
+	"context"
+	"encoding/base64"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestParseTokenClaims(t *testing.T) {
+	payload := base64.RawURLEncoding.EncodeToString([]byte(`{"iss":"admin from splunk","sub":"alice","aud":"splunk-cli","idp":"Splunk","jti":"abc123","iat":1700000000,"exp":1702592000,"nbr":1700000000}`))
+	claims, err := ParseTokenClaims("eyJraWQiOiJzcGx1bmsuc2VjcmV0IiwiYWxnIjoiSFM1MTIifQ." + payload + ".signature")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if claims.ID != "abc123" || claims.Subject != "alice" || claims.Audience != "splunk-cli" {
+		t.Errorf("Unexpected claims: %+v", claims)
+	}
+	if !claims.IssuedAt.Equal(time.Unix(1700000000, 0)) || !claims.ExpiresAt.Equal(time.Unix(1702592000, 0)) {
+		t.Errorf("Unexpected times: %v, %v", claims.IssuedAt, claims.ExpiresAt)
+	}
+
+	if _, err := ParseTokenClaims("not-a-jwt"); err == nil {
+		t.Error("Expected an error for a token that isn't a JWT")
+	}
+}
+
+func TestCreateAndDeleteToken(t *testing.T) {
+	var deleted string
+	c := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case "POST":
+			if err := r.ParseForm(); err != nil {
+				t.Fatal(err)
+			}
+			if r.URL.Path != "/services/authorization/tokens" || r.PostForm.Get("name") != "alice" || r.PostForm.Get("audience") != "splunk-cli" || r.PostForm.Get("expires_on") != "+30d" {
+				t.Errorf("Unexpected request: %s %v", r.URL.Path, r.PostForm)
+			}
+			w.Write([]byte(`{"entry":[{"name":"tokens","content":{"id":"new123","token":"eyJ.new.token"}}]}`))
+		case "DELETE":
+			deleted = r.URL.Path + "?id=" + r.URL.Query().Get("id")
+			w.Write([]byte(`{"entry":[]}`))
+		}
+	}))
+
+	token, err := c.CreateToken(context.Background(), TokenOptions{User: "alice", Audience: "splunk-cli", ExpiresOn: "+30d"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if token.ID != "new123" || token.Token != "eyJ.new.token" {
+		t.Errorf("Unexpected token: %+v", token)
+	}
+
+	if err := c.DeleteToken(context.Background(), "alice", "old456"); err != nil {
+		t.Fatal(err)
+	}
+	if deleted != "/services/authorization/tokens/alice?id=old456" {
+		t.Errorf("Unexpected delete: %s", deleted)
+	}
+}
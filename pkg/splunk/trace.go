@@ -0,0 +1,148 @@
+package splunk
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// maxTraceBody is how much of a request or response body a Tracer logs
+const maxTraceBody = 4 << 10
+
+// secretFields matches the JSON fields a Tracer redacts from bodies
+var secretFields = regexp.MustCompile(`(?i)("(?:password|sessionKey|token)"\s*:\s*)"(?:[^"\\]|\\.)*"`)
+
+// Tracer logs every request and its response, for troubleshooting API failures
+type Tracer struct {
+	// Logger, if set, logs each request at debug level, with any bodies as "body" attributes
+	Logger *slog.Logger
+	// Out is written the lines of each request if there's no Logger
+	Out io.Writer
+	// Bodies logs the first 4 KiB of request and response bodies as well, with passwords, tokens
+	// and session keys redacted
+	Bodies bool
+	// Redact, if set, is applied to everything logged, e.g. to hide secret values in queries
+	Redact func(string) string
+
+	mu sync.Mutex
+}
+
+// Middleware logs the method, URL, status and latency of each request. Used as the innermost
+// middleware, it logs every attempt of a retried request.
+func (t *Tracer) Middleware(next RequestFunc) RequestFunc {
+	return func(req *http.Request) (*http.Response, error) {
+		var lines []string
+		if t.Bodies && req.GetBody != nil {
+			if body, err := req.GetBody(); err == nil {
+				data, _ := io.ReadAll(io.LimitReader(body, maxTraceBody))
+				body.Close()
+				lines = append(lines, "> "+redactBody(data, req.Header.Get("Content-Type"), t.Redact))
+			}
+		}
+
+		start := time.Now()
+		resp, err := next(req)
+		latency := time.Since(start).Round(time.Millisecond)
+		if err != nil {
+			t.log(fmt.Sprintf("%s %s: %v (%s)", req.Method, redactURL(req.URL, t.Redact), err, latency), lines)
+			return resp, err
+		}
+
+		line := fmt.Sprintf("%s %s -> %s (%s)", req.Method, redactURL(req.URL, t.Redact), resp.Status, latency)
+		if id := req.Header.Get("X-Request-Id"); id != "" {
+			line += " request ID " + id
+		}
+		if t.Bodies {
+			// Only the start of the body is read, and put back, so streamed results still stream
+			data, readErr := io.ReadAll(io.LimitReader(resp.Body, maxTraceBody))
+			resp.Body = readCloser{io.MultiReader(bytes.NewReader(data), resp.Body), resp.Body}
+			if readErr == nil && len(data) > 0 {
+				lines = append(lines, "< "+redactBody(data, resp.Header.Get("Content-Type"), t.Redact))
+			}
+		}
+		t.log(line, lines)
+		return resp, nil
+	}
+}
+
+// log writes a request's lines together, as requests may be sent concurrently
+func (t *Tracer) log(line string, bodies []string) {
+	if t.Logger != nil {
+		var args []any
+		for _, body := range bodies {
+			args = append(args, "body", t.redact(strings.TrimSpace(body)))
+		}
+		t.Logger.Debug(t.redact(line), args...)
+		return
+	}
+	text := "DEBUG " + line + "\n"
+	for _, body := range bodies {
+		text += "DEBUG   " + strings.ReplaceAll(strings.TrimSpace(body), "\n", "\nDEBUG   ") + "\n"
+	}
+	text = t.redact(text)
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	fmt.Fprint(t.Out, text)
+}
+
+func (t *Tracer) redact(s string) string {
+	if t.Redact == nil {
+		return s
+	}
+	return t.Redact(s)
+}
+
+// redactBody redacts passwords, tokens and session keys from a form or JSON body. Form values are
+// redacted by redact as well before they're encoded again, as it wouldn't match their encoding.
+func redactBody(data []byte, contentType string, redact func(string) string) string {
+	if strings.HasPrefix(contentType, "application/x-www-form-urlencoded") {
+		if values, err := url.ParseQuery(string(data)); err == nil {
+			return redactValues(values, redact).Encode()
+		}
+	}
+	return secretFields.ReplaceAllString(string(data), `$1"REDACTED"`)
+}
+
+// redactURL returns the URL with its query values redacted like those of a form body
+func redactURL(u *url.URL, redact func(string) string) string {
+	if u.RawQuery == "" {
+		return u.String()
+	}
+	values, err := url.ParseQuery(u.RawQuery)
+	if err != nil {
+		return u.String()
+	}
+	redacted := *u
+	redacted.RawQuery = redactValues(values, redact).Encode()
+	return redacted.String()
+}
+
+// redactValues replaces passwords, tokens and session keys, and applies redact to the other values
+func redactValues(values url.Values, redact func(string) string) url.Values {
+	for key, vs := range values {
+		switch strings.ToLower(key) {
+		case "password", "token", "sessionkey":
+			values.Set(key, "REDACTED")
+			continue
+		}
+		if redact != nil {
+			for i, v := range vs {
+				vs[i] = redact(v)
+			}
+		}
+	}
+	return values
+}
+
+// readCloser reads from one reader and closes another, e.g. a body that has been partly read ahead
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
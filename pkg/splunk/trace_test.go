@@ -0,0 +1,83 @@
+package splunk
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestTracer(t *testing.T) {
+	c := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/services/auth/login":
+			fmt.Fprint(w, `{"sessionKey":"s3cret-key"}`)
+		default:
+			fmt.Fprint(w, `{"sid":"123"}`)
+		}
+	}))
+	var out strings.Builder
+	tracer := &Tracer{Out: &out, Bodies: true, Redact: strings.NewReplacer("acct-42", "$ACCOUNT$", "a+b/c=", "$API_KEY$").Replace}
+	auth := &SessionAuth{Username: "admin", Password: "changeme"}
+	c.Token = ""
+	c.Use(auth.Middleware, WithRequestID("req-1"), tracer.Middleware)
+
+	sid, err := c.RunSearch(context.Background(), "search account=acct-42 key=a+b/c=", SearchOptions{})
+	if err != nil || sid != "123" {
+		t.Fatalf("Expected the job to be created, got %q and: %v", sid, err)
+	}
+
+	log := out.String()
+	for _, want := range []string{
+		"DEBUG POST " + c.BaseURL + "/services/auth/login -> 200 OK (",
+		"DEBUG POST " + c.BaseURL + "/services/search/jobs -> 200 OK (",
+		"request ID req-1",
+		"password=REDACTED",
+		`{"sessionKey":"REDACTED"}`,
+		"search=search+account%3D%24ACCOUNT%24+key%3D%24API_KEY%24",
+		`{"sid":"123"}`,
+	} {
+		if !strings.Contains(log, want) {
+			t.Errorf("Expected the log to contain %q, got:\n%s", want, log)
+		}
+	}
+	// Secrets that need escaping mustn't appear encoded either
+	for _, secret := range []string{"changeme", "s3cret-key", "acct-42", "a+b/c=", url.QueryEscape("a+b/c=")} {
+		if strings.Contains(log, secret) {
+			t.Errorf("Expected %q to be redacted, got:\n%s", secret, log)
+		}
+	}
+}
+
+func TestTracerLogger(t *testing.T) {
+	c := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"sid":"123"}`)
+	}))
+	var out strings.Builder
+	logger := slog.New(slog.NewJSONHandler(&out, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	tracer := &Tracer{Logger: logger, Bodies: true, Redact: strings.NewReplacer("acct-42", "$ACCOUNT$").Replace}
+	c.Use(tracer.Middleware)
+
+	if _, err := c.RunSearch(context.Background(), "search account=acct-42", SearchOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	var record struct {
+		Level string `json:"level"`
+		Msg   string `json:"msg"`
+		Body  string `json:"body"`
+	}
+	if err := json.Unmarshal([]byte(out.String()), &record); err != nil {
+		t.Fatalf("Expected a JSON record, got: %s", out.String())
+	}
+	if record.Level != "DEBUG" || !strings.HasPrefix(record.Msg, "POST "+c.BaseURL+"/services/search/jobs -> 200 OK") {
+		t.Errorf("Expected the request at debug level, got: %+v", record)
+	}
+	// The last body attribute is the response's
+	if record.Body != `< {"sid":"123"}` || strings.Contains(out.String(), "acct-42") {
+		t.Errorf("Expected the redacted bodies, got: %s", out.String())
+	}
+}
@@ -0,0 +1,89 @@
+package splunk
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// JobWaiter waits for search jobs to complete, polling with an adaptive backoff
+type JobWaiter struct {
+	Client API
+	// MinInterval is the delay before the first status poll
+	MinInterval time.Duration
+	// MaxInterval caps the delay between status polls as it backs off
+	MaxInterval time.Duration
+	// OnProgress, if set, is called with the job status after every poll
+	OnProgress func(*Search)
+	// LeaveRunning leaves the job running if ctx is done first, for callers that don't own the job
+	LeaveRunning bool
+}
+
+// warner is implemented by the APIs that report warnings about searches, such as Client with its OnWarning
+type warner interface {
+	warn(sid string, messages Messages)
+}
+
+// NewJobWaiter creates a JobWaiter with default polling intervals
+func NewJobWaiter(client API) *JobWaiter {
+	return &JobWaiter{
+		Client:      client,
+		MinInterval: 250 * time.Millisecond,
+		MaxInterval: 5 * time.Second,
+	}
+}
+
+// Wait polls the job until it is done. If ctx is done first, the job is cancelled (unless
+// LeaveRunning is set) so it doesn't keep running on the search head, and ctx's error is returned.
+func (w *JobWaiter) Wait(ctx context.Context, sid string) (*Search, error) {
+	interval := w.MinInterval
+	timer := time.NewTimer(interval)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			w.cancel(ctx, sid)
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+
+		status, err := w.Client.GetSearchStatus(ctx, sid)
+		if err != nil {
+			if ctx.Err() != nil {
+				w.cancel(ctx, sid)
+				return nil, ctx.Err()
+			}
+			return nil, fmt.Errorf("failed to get search status: %w", err)
+		}
+
+		if w.OnProgress != nil {
+			w.OnProgress(status)
+		}
+
+		if status.Content.IsDone {
+			if c, ok := w.Client.(warner); ok {
+				c.warn(sid, status.Content.Messages)
+			}
+			return status, nil
+		}
+
+		// Back off by half again each poll, so short searches return quickly
+		// and long ones don't hammer the search head
+		interval += interval / 2
+		if interval > w.MaxInterval {
+			interval = w.MaxInterval
+		}
+		timer.Reset(interval)
+	}
+}
+
+// cancel cancels an abandoned job, using a fresh deadline since ctx is already done
+func (w *JobWaiter) cancel(ctx context.Context, sid string) {
+	if w.LeaveRunning {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 10*time.Second)
+	defer cancel()
+	_ = w.Client.CancelSearch(ctx, sid)
+}
@@ -0,0 +1,70 @@
+package splunk
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func newTestClient(t *testing.T, handler http.Handler) *Client {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	c := NewClient("localhost", "test-token")
+	c.BaseURL = server.URL
+	return c
+}
+
+func TestJobWaiterWaitsUntilDone(t *testing.T) {
+	var polls atomic.Int32
+	c := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		done := polls.Add(1) >= 3
+		fmt.Fprintf(w, `{"sid":"123","content":{"isDone":%t,"dispatchState":"RUNNING","resultCount":7}}`, done)
+	}))
+
+	waiter := NewJobWaiter(c)
+	waiter.MinInterval = time.Millisecond
+	var progress int
+	waiter.OnProgress = func(*Search) { progress++ }
+
+	status, err := waiter.Wait(context.Background(), "123")
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if status.Content.ResultCount != 7 {
+		t.Errorf("Expected 7 results, got: %d", status.Content.ResultCount)
+	}
+	if progress != 3 {
+		t.Errorf("Expected 3 progress callbacks, got: %d", progress)
+	}
+}
+
+func TestJobWaiterCancelsJobWhenContextDone(t *testing.T) {
+	var cancelled atomic.Bool
+	c := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "POST" && r.URL.Path == "/services/search/jobs/123/control" {
+			cancelled.Store(true)
+			return
+		}
+		fmt.Fprint(w, `{"sid":"123","content":{"isDone":false}}`)
+	}))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	waiter := NewJobWaiter(c)
+	waiter.MinInterval = time.Millisecond
+	waiter.MaxInterval = time.Millisecond
+
+	if _, err := waiter.Wait(ctx, "123"); err != context.DeadlineExceeded {
+		t.Fatalf("Expected deadline exceeded, got: %v", err)
+	}
+	if !cancelled.Load() {
+		t.Error("Expected job to be cancelled")
+	}
+}
@@ -0,0 +1,156 @@
+package main
+
+import (
+	"context"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/kitproj/splunk-cli/pkg/splunk"
+)
+
+// pluginPrefix is the prefix of plugin executables, e.g. splunk-triage is run by "splunk triage"
+const pluginPrefix = "splunk-"
+
+// pluginCommands returns a command for each plugin executable on PATH. A
+// plugin can't replace a built-in command, and the first plugin on PATH with
+// a name wins, as with any other executable.
+func pluginCommands(builtin *command) []*command {
+	seen := map[string]bool{}
+	var plugins []*command
+	for _, dir := range filepath.SplitList(os.Getenv("PATH")) {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, entry := range entries {
+			name, ok := pluginName(entry.Name())
+			if !ok || seen[name] || builtin.find(name) != nil {
+				continue
+			}
+			path := filepath.Join(dir, entry.Name())
+			if !isExecutable(path) {
+				continue
+			}
+			seen[name] = true
+			plugins = append(plugins, pluginCommand(name, path))
+		}
+	}
+	return plugins
+}
+
+// pluginName returns the command name of a plugin executable's file name
+func pluginName(file string) (string, bool) {
+	if runtime.GOOS == "windows" {
+		ext := strings.ToLower(filepath.Ext(file))
+		if ext != ".exe" && ext != ".bat" && ext != ".cmd" {
+			return "", false
+		}
+		file = strings.TrimSuffix(file, filepath.Ext(file))
+	}
+	name := strings.TrimPrefix(file, pluginPrefix)
+	if name == file || name == "" {
+		return "", false
+	}
+	return name, true
+}
+
+func isExecutable(path string) bool {
+	info, err := os.Stat(path)
+	if err != nil || info.IsDir() {
+		return false
+	}
+	return runtime.GOOS == "windows" || info.Mode()&0111 != 0
+}
+
+func pluginCommand(name, path string) *command {
+	return &command{
+		name:    name,
+		args:    "[args...]",
+		short:   "Plugin (" + path + ")",
+		rawArgs: true,
+		run: func(ctx context.Context, args []string) error {
+			return runPlugin(ctx, path, args)
+		},
+	}
+}
+
+// runPlugin runs a plugin with the CLI's connection settings in its environment
+func runPlugin(ctx context.Context, path string, args []string) error {
+	cmd := exec.CommandContext(ctx, path, args...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	// Let the plugin handle Ctrl-C itself, e.g. to cancel its search jobs
+	cmd.Cancel = func() error {
+		if runtime.GOOS == "windows" {
+			return cmd.Process.Kill()
+		}
+		return cmd.Process.Signal(os.Interrupt)
+	}
+	cmd.Env = append(os.Environ(), pluginEnv()...)
+	return cmd.Run()
+}
+
+// pluginEnv returns the environment variables describing the CLI's connection settings.
+// A plugin that doesn't talk to Splunk still runs if the connection isn't configured.
+func pluginEnv() []string {
+	source, err := newClientSource()
+	if err != nil {
+		return cliEnv(nil)
+	}
+	c, err := source.Get()
+	if err != nil {
+		return cliEnv(nil)
+	}
+	env := cliEnv(c)
+	if settings.Earliest != "" {
+		env = append(env, "SPLUNK_EARLIEST="+settings.Earliest)
+	}
+	if settings.Latest != "" {
+		env = append(env, "SPLUNK_LATEST="+settings.Latest)
+	}
+	return env
+}
+
+// cliEnv returns the environment variables describing the CLI and, if c isn't nil, the connection of c
+func cliEnv(c splunk.Connection) []string {
+	env := []string{"SPLUNK_CLI_VERSION=" + version}
+	if executable, err := os.Executable(); err == nil {
+		env = append(env, "SPLUNK_CLI="+executable)
+	}
+	if name := profileName(); name != "" {
+		env = append(env, "SPLUNK_PROFILE="+name)
+	}
+	if requestID != "" {
+		env = append(env, "SPLUNK_REQUEST_ID="+requestID)
+	}
+	if c == nil {
+		return env
+	}
+	if u, err := url.Parse(c.ServerURL()); err == nil {
+		env = append(env, "SPLUNK_HOST="+u.Hostname())
+	}
+	env = append(env, "SPLUNK_URL="+c.ServerURL())
+	// There's no token with basic auth
+	if token := c.AuthToken(); token != "" {
+		env = append(env, "SPLUNK_TOKEN="+token)
+	}
+	opts := tlsOptions()
+	if opts.CertFile != "" {
+		env = append(env, "SPLUNK_CLIENT_CERT="+opts.CertFile)
+		if opts.KeyFile != "" {
+			env = append(env, "SPLUNK_CLIENT_KEY="+opts.KeyFile)
+		}
+	}
+	if opts.CAFile != "" {
+		env = append(env, "SPLUNK_CA_CERT="+opts.CAFile)
+	}
+	if opts.Insecure {
+		env = append(env, "SPLUNK_INSECURE=true")
+	}
+	return env
+}
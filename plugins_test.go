@@ -0,0 +1,31 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func TestPluginCommands(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("plugins are found by file extension on Windows")
+	}
+	dir := t.TempDir()
+	for name, mode := range map[string]os.FileMode{
+		"splunk-triage": 0755,
+		"splunk-search": 0755, // can't replace a built-in command
+		"splunk-notes":  0644, // not executable
+		"splunk-":       0755,
+	} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("#!/bin/sh\n"), mode); err != nil {
+			t.Fatal(err)
+		}
+	}
+	t.Setenv("PATH", dir)
+
+	plugins := pluginCommands(&command{subcommands: []*command{{name: "search"}}})
+	if len(plugins) != 1 || plugins[0].name != "triage" {
+		t.Fatalf("Expected only the triage plugin, got: %v", plugins)
+	}
+}
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/kitproj/splunk-cli/pkg/splunk"
+)
+
+// progress reports the progress of a search, as text logged at info level, so -log-level and
+// -log-format apply to it, or, with --progress-json, as a JSON object per line on stderr, e.g.
+// {"event":"created","sid":"1700000000.1"}
+type progress struct {
+	log  *slog.Logger
+	w    io.Writer
+	json bool
+}
+
+func newProgress(asJSON bool) *progress {
+	return &progress{log: slog.Default(), w: os.Stderr, json: asJSON}
+}
+
+// report reports an event of a search, logged as text, or written as its fields if reporting JSON
+func (p *progress) report(event, text string, fields map[string]interface{}) {
+	if !p.json {
+		p.log.Info(strings.TrimSpace(redact(text)))
+		return
+	}
+	line := map[string]interface{}{"event": event}
+	for key, value := range fields {
+		if s, ok := value.(string); ok {
+			value = redact(s)
+		}
+		line[key] = value
+	}
+	data, err := json.Marshal(line)
+	if err != nil {
+		return
+	}
+	fmt.Fprintf(p.w, "%s\n", data)
+}
+
+// warning reports a warning Splunk reported about a search
+func (p *progress) warning(sid string, message splunk.Message) {
+	if !p.json {
+		p.log.Warn(redact(message.Text))
+		return
+	}
+	p.report("warning", fmt.Sprintf("Warning: %s\n", message.Text), map[string]interface{}{"sid": sid, "message": message.Text})
+}
+
+// summary reports the metrics of a finished search job, so performance regressions are
+// noticeable and can be quoted in issue reports
+func (p *progress) summary(sid string, status *splunk.Search) {
+	runtime := float64(status.Content.RunDuration)
+	scanned := int64(status.Content.ScanCount)
+	rate := 0.0
+	if runtime > 0 {
+		rate = float64(scanned) / runtime
+	}
+	p.report("summary", fmt.Sprintf("Search %s took %.2fs: scanned %d events (%.0f/s), %d results.\n",
+		sid, runtime, scanned, rate, status.Content.ResultCount), map[string]interface{}{
+		"sid":               sid,
+		"runtime_seconds":   runtime,
+		"scanned_events":    scanned,
+		"events_per_second": rate,
+		"results":           int64(status.Content.ResultCount),
+	})
+}
+
+// oneshotSummary reports the metrics of a oneshot search, which has no job to report its own
+func (p *progress) oneshotSummary(elapsed time.Duration, results int) {
+	p.report("summary", fmt.Sprintf("Oneshot search took %.2fs: %d results.\n", elapsed.Seconds(), results), map[string]interface{}{
+		"runtime_seconds": elapsed.Seconds(),
+		"results":         results,
+	})
+}
+
+// maxTimelineRows is the most rows a timeline is printed in, adjacent buckets being added together
+// if the job has more
+const maxTimelineRows = 30
+
+// timeline reports the number of events over time, as a bar per span of time
+func (p *progress) timeline(buckets []splunk.TimelineBucket) {
+	buckets = mergeBuckets(buckets, maxTimelineRows)
+	if len(buckets) == 0 {
+		p.report("timeline", "The search has no timeline.\n", map[string]interface{}{"buckets": []interface{}{}})
+		return
+	}
+
+	var most int64
+	for _, b := range buckets {
+		most = max(most, b.Count)
+	}
+	layout := "2006-01-02 15:04"
+	switch span := buckets[0].Duration; {
+	case span >= 24*time.Hour:
+		layout = "2006-01-02"
+	case span < time.Minute:
+		layout = "2006-01-02 15:04:05"
+	}
+
+	var text strings.Builder
+	rows := make([]interface{}, len(buckets))
+	fmt.Fprintf(&text, "Events per %s:\n", buckets[0].Duration)
+	for i, b := range buckets {
+		width := 0
+		if most > 0 {
+			width = int((b.Count*40 + most - 1) / most)
+		}
+		fmt.Fprintf(&text, "%s %-40s %d\n", b.Earliest.Format(layout), strings.Repeat("#", width), b.Count)
+		rows[i] = map[string]interface{}{"earliest": b.Earliest.Format(time.RFC3339), "duration_seconds": b.Duration.Seconds(), "count": b.Count}
+	}
+	p.report("timeline", text.String(), map[string]interface{}{"buckets": rows})
+}
+
+// mergeBuckets adds adjacent buckets together so there are at most rows of them
+func mergeBuckets(buckets []splunk.TimelineBucket, rows int) []splunk.TimelineBucket {
+	n := (len(buckets) + rows - 1) / rows
+	if n <= 1 {
+		return buckets
+	}
+	var merged []splunk.TimelineBucket
+	for i := 0; i < len(buckets); i += n {
+		b := splunk.TimelineBucket{Earliest: buckets[i].Earliest, Duration: buckets[i].Duration * time.Duration(n)}
+		for _, next := range buckets[i:min(i+n, len(buckets))] {
+			b.Count += next.Count
+		}
+		merged = append(merged, b)
+	}
+	return merged
+}
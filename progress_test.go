@@ -0,0 +1,74 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/kitproj/splunk-cli/pkg/splunk"
+)
+
+// textLogger logs info messages and above to w as text
+func textLogger(w io.Writer) *slog.Logger {
+	return slog.New(&textHandler{w: w, level: slog.LevelInfo, mu: &sync.Mutex{}})
+}
+
+func TestProgressSummary(t *testing.T) {
+	status := &splunk.Search{}
+	status.Content.RunDuration = 2
+	status.Content.ScanCount = 1000
+	status.Content.ResultCount = 7
+
+	var text bytes.Buffer
+	(&progress{log: textLogger(&text)}).summary("123", status)
+	if text.String() != "Search 123 took 2.00s: scanned 1000 events (500/s), 7 results.\n" {
+		t.Errorf("Unexpected summary: %q", text.String())
+	}
+
+	var lines bytes.Buffer
+	(&progress{w: &lines, json: true}).summary("123", status)
+	var summary map[string]interface{}
+	if err := json.Unmarshal(lines.Bytes(), &summary); err != nil {
+		t.Fatalf("Expected a JSON line, got: %q", lines.String())
+	}
+	if summary["event"] != "summary" || summary["sid"] != "123" || summary["events_per_second"] != 500.0 {
+		t.Errorf("Unexpected summary: %v", summary)
+	}
+}
+
+func TestProgressTimeline(t *testing.T) {
+	start := time.Date(2024, 1, 1, 10, 0, 0, 0, time.Local)
+	var buckets []splunk.TimelineBucket
+	for i := range 60 {
+		buckets = append(buckets, splunk.TimelineBucket{Earliest: start.Add(time.Duration(i) * time.Minute), Duration: time.Minute, Count: int64(i % 2)})
+	}
+	buckets[59].Count = 9
+
+	var text bytes.Buffer
+	(&progress{log: textLogger(&text)}).timeline(buckets)
+	lines := strings.Split(strings.TrimSpace(text.String()), "\n")
+	if len(lines) != 31 || lines[0] != "Events per 2m0s:" {
+		t.Fatalf("Expected the buckets merged into 30 rows of 2m, got: %s", text.String())
+	}
+	if want := "2024-01-01 10:00 ##### 1"; strings.Join(strings.Fields(lines[1]), " ") != want {
+		t.Errorf("Expected %q, got %q", want, lines[1])
+	}
+	if want := "2024-01-01 10:58 " + strings.Repeat("#", 40) + " 9"; lines[30] != want {
+		t.Errorf("Expected %q, got %q", want, lines[30])
+	}
+}
+
+func TestProgressLogLevel(t *testing.T) {
+	var text bytes.Buffer
+	p := &progress{log: slog.New(&textHandler{w: &text, level: slog.LevelWarn, mu: &sync.Mutex{}})}
+	p.report("created", "Search job created: 123\n", map[string]interface{}{"sid": "123"})
+	p.warning("123", splunk.Message{Type: "WARN", Text: "Peer idx2 was unavailable"})
+	if text.String() != "Warning: Peer idx2 was unavailable\n" {
+		t.Errorf("Expected only the warning at warn level, got: %q", text.String())
+	}
+}
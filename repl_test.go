@@ -0,0 +1,50 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestReplSession(t *testing.T) {
+	var got []string
+	var out bytes.Buffer
+	session := &replSession{
+		earliest: "-1h",
+		format:   "text",
+		out:      &out,
+		search: func(ctx context.Context, args searchArgs) error {
+			got = append(got, fmt.Sprintf("%s [%s,%s] %s", args.query, args.earliestTime, args.latestTime, args.format))
+			return nil
+		},
+	}
+
+	input := strings.Join([]string{
+		"index=main error",
+		".earliest -4h",
+		".output bogus",
+		".output json",
+		"index=main |",
+		"stats count \\",
+		"by host",
+		".quit",
+		"never run",
+	}, "\n")
+	if err := session.run(context.Background(), &scannerReader{bufio.NewScanner(strings.NewReader(input))}); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	want := []string{
+		"index=main error [-1h,] text",
+		"index=main |\nstats count \nby host [-4h,] json",
+	}
+	if strings.Join(got, ";") != strings.Join(want, ";") {
+		t.Errorf("Expected %q, got: %q", want, got)
+	}
+	if !strings.Contains(out.String(), `unknown output format "bogus"`) {
+		t.Errorf("Expected the bad format to be reported, got: %s", out.String())
+	}
+}
@@ -0,0 +1,149 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/kitproj/splunk-cli/pkg/splunk"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// mcpResource is a read-only resource whose content is the structured content of a tool's call,
+// for clients that prefer reading resources to calling tools
+type mcpResource struct {
+	resource mcp.Resource
+	// tool is the tool to call, without which the resource isn't registered
+	tool    string
+	args    map[string]interface{}
+	handler func(ctx context.Context, client splunk.API, request mcp.CallToolRequest) (*mcp.CallToolResult, error)
+}
+
+// mcpResources returns the resources of the server
+func mcpResources() []mcpResource {
+	return []mcpResource{
+		{
+			resource: mcp.NewResource("splunk://saved-searches", "Saved searches",
+				mcp.WithResourceDescription("The saved searches (reports and alerts) with their SPL, descriptions and schedules"),
+				mcp.WithMIMEType("application/json"),
+			),
+			tool:    "list_saved_searches",
+			handler: listSavedSearchesHandler,
+		},
+		{
+			resource: mcp.NewResource("splunk://indexes", "Indexes",
+				mcp.WithResourceDescription("The enabled indexes, including internal ones, with their event counts, sizes and retention"),
+				mcp.WithMIMEType("application/json"),
+			),
+			tool:    "list_indexes",
+			args:    map[string]interface{}{"include_internal": true},
+			handler: listIndexesHandler,
+		},
+		{
+			resource: mcp.NewResource("splunk://server-info", "Server info",
+				mcp.WithResourceDescription("The Splunk server's version, license state and splunkd health"),
+				mcp.WithMIMEType("application/json"),
+			),
+			tool:    "server_info",
+			handler: serverInfoHandler,
+		},
+	}
+}
+
+// savedSearchTemplate is a saved search by name, whose name clients can complete
+var savedSearchTemplate = mcp.NewResourceTemplate("splunk://saved-searches/{saved_search}", "Saved search",
+	mcp.WithTemplateDescription("A saved search (report or alert) with its SPL, description and schedule"),
+	mcp.WithTemplateMIMEType("application/json"),
+)
+
+// addResources registers the resources whose tools are registered, so --read-only, --tools and
+// --exclude-tools restrict them too
+func addResources(s *server.MCPServer, tools []server.ServerTool, clients *clientSource) {
+	registered := map[string]bool{}
+	for _, tool := range tools {
+		registered[tool.Tool.Name] = true
+	}
+	for _, r := range mcpResources() {
+		if registered[r.tool] {
+			s.AddResource(r.resource, resourceHandler(r, clients))
+		}
+	}
+	if registered["list_saved_searches"] {
+		s.AddResourceTemplate(savedSearchTemplate, func(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+			api, err := clients.Get()
+			if err != nil {
+				return nil, fmt.Errorf("failed to create Splunk client: %w", err)
+			}
+			contents, err := readSavedSearch(ctx, api, request)
+			if err != nil {
+				return nil, err
+			}
+			return []mcp.ResourceContents{contents}, nil
+		})
+	}
+}
+
+// readSavedSearch returns the saved search a request for the saved search template names, as JSON
+func readSavedSearch(ctx context.Context, client splunk.API, request mcp.ReadResourceRequest) (mcp.TextResourceContents, error) {
+	// The server sets the template's variables as lists of their decoded values
+	var name string
+	switch value := request.Params.Arguments["saved_search"].(type) {
+	case string:
+		name = value
+	case []string:
+		name = strings.Join(value, ",")
+	}
+	if name == "" {
+		return mcp.TextResourceContents{}, fmt.Errorf("missing saved search name in %s", request.Params.URI)
+	}
+	search, err := client.GetSavedSearch(ctx, name)
+	if err != nil {
+		return mcp.TextResourceContents{}, fmt.Errorf("failed to get saved search %s: %w", name, err)
+	}
+	data, err := json.MarshalIndent(search, "", "  ")
+	if err != nil {
+		return mcp.TextResourceContents{}, fmt.Errorf("failed to encode %s: %w", request.Params.URI, err)
+	}
+	return mcp.TextResourceContents{URI: request.Params.URI, MIMEType: "application/json", Text: string(data)}, nil
+}
+
+// resourceHandler reads a resource by calling its tool, returning the structured content as JSON
+func resourceHandler(r mcpResource, clients *clientSource) server.ResourceHandlerFunc {
+	return func(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+		api, err := clients.Get()
+		if err != nil {
+			return nil, fmt.Errorf("failed to create Splunk client: %w", err)
+		}
+		contents, err := readResource(ctx, api, r)
+		if err != nil {
+			return nil, err
+		}
+		return []mcp.ResourceContents{contents}, nil
+	}
+}
+
+// readResource calls a resource's tool and returns its structured content as JSON
+func readResource(ctx context.Context, client *splunk.Client, r mcpResource) (mcp.TextResourceContents, error) {
+	result, err := r.handler(ctx, client, mcp.CallToolRequest{
+		Params: mcp.CallToolParams{Name: r.tool, Arguments: r.args},
+	})
+	if err != nil {
+		return mcp.TextResourceContents{}, err
+	}
+	if result.IsError {
+		var texts []string
+		for _, content := range result.Content {
+			if text, ok := content.(mcp.TextContent); ok {
+				texts = append(texts, text.Text)
+			}
+		}
+		return mcp.TextResourceContents{}, fmt.Errorf("failed to read %s: %s", r.resource.URI, strings.Join(texts, "; "))
+	}
+	data, err := json.MarshalIndent(result.StructuredContent, "", "  ")
+	if err != nil {
+		return mcp.TextResourceContents{}, fmt.Errorf("failed to encode %s: %w", r.resource.URI, err)
+	}
+	return mcp.TextResourceContents{URI: r.resource.URI, MIMEType: "application/json", Text: string(data)}, nil
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/kitproj/splunk-cli/pkg/splunk"
+	"github.com/kitproj/splunk-cli/pkg/splunk/splunktest"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestReadResource(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"entry":[
+			{"name":"_internal","content":{"totalEventCount":99}},
+			{"name":"main","content":{"totalEventCount":1234}},
+			{"name":"old","content":{"disabled":true}}
+		]}`))
+	}))
+	defer server.Close()
+	client := splunk.NewClient("localhost", "test-token")
+	client.BaseURL = server.URL
+
+	var indexes mcpResource
+	for _, r := range mcpResources() {
+		if r.resource.URI == "splunk://indexes" {
+			indexes = r
+		}
+	}
+	contents, err := readResource(context.Background(), client, indexes)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if contents.URI != "splunk://indexes" || contents.MIMEType != "application/json" {
+		t.Errorf("Expected a JSON splunk://indexes resource, got: %s %s", contents.URI, contents.MIMEType)
+	}
+
+	var content struct {
+		Indexes []splunk.Index `json:"indexes"`
+	}
+	if err := json.Unmarshal([]byte(contents.Text), &content); err != nil {
+		t.Fatalf("Expected JSON, got: %s", contents.Text)
+	}
+	if len(content.Indexes) != 2 || content.Indexes[0].Name != "_internal" || content.Indexes[1].Name != "main" {
+		t.Errorf("Expected the enabled indexes, including internal ones, got: %+v", content.Indexes)
+	}
+}
+
+func TestReadSavedSearch(t *testing.T) {
+	uri := "splunk://saved-searches/Errors%20by%20host"
+	var request mcp.ReadResourceRequest
+	request.Params.URI = uri
+	request.Params.Arguments = map[string]any{}
+	for name, value := range savedSearchTemplate.URITemplate.Match(uri) {
+		request.Params.Arguments[name] = value.V
+	}
+
+	fake := &splunktest.Fake{
+		GetSavedSearchFunc: func(ctx context.Context, name string) (*splunk.SavedSearch, error) {
+			return &splunk.SavedSearch{Name: name, Search: "error | stats count by host"}, nil
+		},
+	}
+	contents, err := readSavedSearch(context.Background(), fake, request)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	var search splunk.SavedSearch
+	if err := json.Unmarshal([]byte(contents.Text), &search); err != nil {
+		t.Fatalf("Expected JSON, got: %s", contents.Text)
+	}
+	if search.Name != "Errors by host" || contents.URI != uri {
+		t.Errorf("Expected the decoded name's saved search at %s, got: %s %+v", uri, contents.URI, search)
+	}
+}
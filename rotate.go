@@ -0,0 +1,221 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log/slog"
+	"math"
+	"os"
+	"time"
+
+	"github.com/kitproj/splunk-cli/internal/config"
+	"github.com/kitproj/splunk-cli/pkg/splunk"
+)
+
+// tokenExpiryWarning is how long before the saved token expires that commands start warning about it
+const tokenExpiryWarning = 7 * 24 * time.Hour
+
+func credentialsCommand() *command {
+	var expires, audience *string
+	return &command{
+		name:  "credentials",
+		short: "Check and rotate the saved API token",
+		long: "Check when the API token saved by 'splunk configure' expires, and rotate it before it does.\n" +
+			"Commands warn on stderr when the saved token expires within 7 days.",
+		subcommands: []*command{
+			{
+				name:    "status",
+				args:    "[profile]",
+				short:   "Show who the saved token is for and when it expires",
+				maxArgs: 1,
+				run: func(ctx context.Context, args []string) error {
+					if len(args) > 0 {
+						profile = args[0]
+					}
+					host, _, err := savedToken()
+					if err != nil {
+						return err
+					}
+					info, ok := config.LoadTokenInfo(profileName(), host)
+					if !ok {
+						return fmt.Errorf("nothing is known about the token for %s%s (rotate it, or configure it again, to track it)", host, profileSuffix())
+					}
+					fmt.Printf("Host:    %s\n", host)
+					fmt.Printf("User:    %s\n", defaultString(info.User, "unknown"))
+					fmt.Printf("ID:      %s\n", defaultString(info.ID, "unknown"))
+					if !info.Created.IsZero() {
+						fmt.Printf("Created: %s\n", info.Created.Local().Format(time.RFC3339))
+					}
+					if info.Expires.IsZero() {
+						fmt.Printf("Expires: never\n")
+					} else {
+						fmt.Printf("Expires: %s (%s)\n", info.Expires.Local().Format(time.RFC3339), expiresIn(info.Expires, time.Now()))
+					}
+					return nil
+				},
+			},
+			{
+				name:  "rotate",
+				args:  "[profile]",
+				short: "Replace the saved token with a new one, and revoke the old one",
+				long: "Create a new token for the saved token's user, check that it works, save it to the keyring in place of the old one,\n" +
+					"and then revoke the old one, so the token can be rotated in one step.\n" +
+					"Creating tokens requires the edit_tokens_own capability. Only token auth, with the token in the keyring, can be rotated.",
+				maxArgs: 1,
+				flags: func(flags *flag.FlagSet) {
+					expires = flags.String("expires", "+30d", "when the new token expires, as a relative time, e.g. +90d")
+					audience = flags.String("audience", "", "audience of the new token (default: the old token's, or splunk-cli)")
+				},
+				run: func(ctx context.Context, args []string) error {
+					if len(args) > 0 {
+						profile = args[0]
+					}
+					return rotateToken(ctx, *expires, *audience)
+				},
+			},
+		},
+	}
+}
+
+// savedToken returns the host and token of the selected profile that 'splunk configure' saved to the keyring
+func savedToken() (string, string, error) {
+	if err := loadSettings(); err != nil {
+		return "", "", err
+	}
+	if settings.Auth == "basic" {
+		return "", "", fmt.Errorf("the profile uses basic auth, which has a password rather than a token")
+	}
+	host := defaultString(settings.Host, os.Getenv("SPLUNK_HOST"))
+	if host == "" {
+		return "", "", fmt.Errorf("host is required")
+	}
+	token, err := config.LoadToken(profileName(), host)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to load token for %s%s (use 'splunk configure %s'): %w", host, profileSuffix(), host, err)
+	}
+	return host, token, nil
+}
+
+// rotateToken replaces the saved token with a new token for the same user, revoking the old one once the new one is saved
+func rotateToken(ctx context.Context, expires, audience string) error {
+	host, oldToken, err := savedToken()
+	if err != nil {
+		return err
+	}
+	old, err := newClient(host, oldToken)
+	if err != nil {
+		return err
+	}
+	user, _, err := old.CurrentUser(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to check the saved token: %w", err)
+	}
+
+	// The old token's ID is in its claims, or else was saved when it was configured
+	oldID := ""
+	if claims, err := splunk.ParseTokenClaims(oldToken); err == nil {
+		oldID = claims.ID
+		audience = defaultString(audience, claims.Audience)
+	} else if info, ok := config.LoadTokenInfo(profileName(), host); ok {
+		oldID = info.ID
+	}
+
+	created, err := old.CreateToken(ctx, splunk.TokenOptions{User: user, Audience: defaultString(audience, "splunk-cli"), ExpiresOn: expires})
+	if err != nil {
+		return fmt.Errorf("failed to create token: %w", err)
+	}
+	c, err := newClient(host, created.Token)
+	if err != nil {
+		return err
+	}
+	if newUser, _, err := c.CurrentUser(ctx); err != nil {
+		return fmt.Errorf("failed to check the new token, so the old one was kept: %w", err)
+	} else if newUser != user {
+		return fmt.Errorf("the new token is for %s rather than %s, so the old one was kept", newUser, user)
+	}
+
+	if err := config.SaveToken(profileName(), host, created.Token); err != nil {
+		return fmt.Errorf("failed to save the new token, so the old one was kept: %w", err)
+	}
+	info := newTokenInfo(created.Token, time.Now())
+	info.ID = defaultString(info.ID, created.ID)
+	info.User = defaultString(info.User, user)
+	if err := config.SaveTokenInfo(profileName(), host, info); err != nil {
+		slog.Warn("Failed to save the new token's expiry", "err", err)
+	}
+	slog.Info("Saved a new token", "user", user, "host", host, profileAttr())
+
+	if oldID == "" {
+		slog.Warn("The old token's ID is unknown, so revoke it in Splunk under Settings > Tokens")
+		return nil
+	}
+	if err := c.DeleteToken(ctx, user, oldID); err != nil {
+		return fmt.Errorf("the new token was saved, but failed to revoke the old token %s (revoke it in Splunk under Settings > Tokens): %w", oldID, err)
+	}
+	slog.Info("Revoked the old token", "id", oldID)
+	return nil
+}
+
+// newTokenInfo returns what can be told about a token from its claims, if it's a JWT
+func newTokenInfo(token string, now time.Time) config.TokenInfo {
+	claims, err := splunk.ParseTokenClaims(token)
+	if err != nil {
+		return config.TokenInfo{Created: now}
+	}
+	return config.TokenInfo{
+		ID:      claims.ID,
+		User:    claims.Subject,
+		Created: defaultTime(claims.IssuedAt, now),
+		Expires: claims.ExpiresAt,
+	}
+}
+
+// warnTokenExpiry warns if the saved token of a profile's host has expired, or expires soon
+func warnTokenExpiry(profile, host string) {
+	if settings.Auth == "basic" {
+		return
+	}
+	info, ok := config.LoadTokenInfo(profile, host)
+	if !ok {
+		return
+	}
+	if msg := tokenExpiryMessage(info, time.Now()); msg != "" {
+		slog.Warn(msg)
+	}
+}
+
+// tokenExpiryMessage returns a reminder to rotate a token that has expired or expires soon, or "" if it doesn't
+func tokenExpiryMessage(info config.TokenInfo, now time.Time) string {
+	if info.Expires.IsZero() || info.Expires.Sub(now) > tokenExpiryWarning {
+		return ""
+	}
+	if !info.Expires.After(now) {
+		return fmt.Sprintf("the saved token expired on %s (use 'splunk configure' to save a new one)", info.Expires.Local().Format(time.DateOnly))
+	}
+	rotate := "splunk credentials rotate"
+	if name := profileName(); name != "" {
+		rotate += " " + name
+	}
+	return fmt.Sprintf("the saved token expires %s, on %s (use '%s' to replace it)", expiresIn(info.Expires, now), info.Expires.Local().Format(time.DateOnly), rotate)
+}
+
+// expiresIn describes how long until a time, in days, or hours on the last day
+func expiresIn(t, now time.Time) string {
+	left := t.Sub(now)
+	switch {
+	case left <= 0:
+		return "expired"
+	case left < 24*time.Hour:
+		return fmt.Sprintf("in %d hours", int(math.Ceil(left.Hours())))
+	default:
+		return fmt.Sprintf("in %d days", int(left.Hours()/24))
+	}
+}
+
+func defaultTime(value, def time.Time) time.Time {
+	if value.IsZero() {
+		return def
+	}
+	return value
+}
@@ -0,0 +1,32 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kitproj/splunk-cli/internal/config"
+)
+
+func TestTokenExpiryMessage(t *testing.T) {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name    string
+		expires time.Time
+		want    string
+	}{
+		{"never expires", time.Time{}, ""},
+		{"expires later", now.Add(30 * 24 * time.Hour), ""},
+		{"expires soon", now.Add(3*24*time.Hour + time.Hour), "expires in 3 days"},
+		{"expires today", now.Add(90 * time.Minute), "expires in 2 hours"},
+		{"expired", now.Add(-time.Hour), "expired on"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tokenExpiryMessage(config.TokenInfo{Expires: tt.expires}, now)
+			if tt.want == "" && got != "" || !strings.Contains(got, tt.want) {
+				t.Errorf("Expected %q in %q", tt.want, got)
+			}
+		})
+	}
+}
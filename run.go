@@ -0,0 +1,161 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/kitproj/splunk-cli/pkg/splunk"
+	"gopkg.in/yaml.v3"
+)
+
+// jobSpec is a search for 'splunk run' to run, so other tools can generate searches as JSON or YAML
+type jobSpec struct {
+	Query    string `json:"query" yaml:"query"`
+	Earliest string `json:"earliest,omitempty" yaml:"earliest,omitempty"`
+	Latest   string `json:"latest,omitempty" yaml:"latest,omitempty"`
+	// Output is the format of the results, ndjson if unset
+	Output string `json:"output,omitempty" yaml:"output,omitempty"`
+	// Destination is the file the results are written to, stdout if unset
+	Destination string `json:"destination,omitempty" yaml:"destination,omitempty"`
+	// Params are the values of the query's $name$ placeholders, keyed by name or name:type
+	Params map[string]string `json:"params,omitempty" yaml:"params,omitempty"`
+}
+
+func runCommand() *command {
+	return &command{
+		name:  "run",
+		args:  "<file|->",
+		short: "Run searches described as JSON or YAML, e.g. by another tool",
+		long: "Run the searches described in a file, or stdin with -, in turn, streaming each one's results like 'splunk export'.\n" +
+			"Each search is a JSON object, and there may be several, one after another or in a list:\n" +
+			`  {"query": "index=main error | stats count by host", "earliest": "-24h", "latest": "now",` + "\n" +
+			`   "output": "csv", "destination": "errors.csv", "params": {"threshold:int": "500"}}` + "\n" +
+			"or the same in YAML, with several searches in a list or in documents separated by ---:\n" +
+			"  query: index=main error | stats count by host\n" +
+			"  earliest: -24h\n" +
+			"  params:\n" +
+			"    threshold:int: 500\n" +
+			"Only query is required. Results go to stdout as ndjson unless output or destination say otherwise.\n" +
+			"Every search is checked before the first is run.",
+		minArgs: 1,
+		maxArgs: 1,
+		run: func(ctx context.Context, args []string) error {
+			r := io.Reader(os.Stdin)
+			if args[0] != "-" {
+				f, err := os.Open(args[0])
+				if err != nil {
+					return fmt.Errorf("failed to open job spec: %w", err)
+				}
+				defer f.Close()
+				r = f
+			}
+			specs, err := parseJobSpecs(r)
+			if err != nil {
+				return err
+			}
+			queries := make([]string, len(specs))
+			for i, spec := range specs {
+				if queries[i], err = spec.query(); err != nil {
+					return fmt.Errorf("search %d: %w", i+1, err)
+				}
+			}
+			return executeCommand(ctx, func(ctx context.Context) error {
+				for i, spec := range specs {
+					if len(specs) > 1 {
+						slog.Info("Running search", "search", i+1, "searches", len(specs))
+					}
+					opts := splunk.SearchOptions{EarliestTime: spec.Earliest, LatestTime: spec.Latest}
+					if err := runExport(ctx, queries[i], opts, defaultString(spec.Output, "ndjson"), spec.Destination, 0, 0, false); err != nil {
+						return fmt.Errorf("search %d: %w", i+1, err)
+					}
+				}
+				return nil
+			})
+		},
+	}
+}
+
+// parseJobSpecs reads job specs, which may be a list or a sequence of JSON objects or YAML documents,
+// checking each is valid. Input that starts as JSON is decoded as JSON, as JSON objects one after
+// another aren't a YAML document, and anything else as YAML.
+func parseJobSpecs(r io.Reader) ([]jobSpec, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read job spec: %w", err)
+	}
+	var decoder interface{ Decode(v interface{}) error }
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && (trimmed[0] == '{' || trimmed[0] == '[') {
+		d := json.NewDecoder(bytes.NewReader(data))
+		d.DisallowUnknownFields()
+		decoder = d
+	} else {
+		d := yaml.NewDecoder(bytes.NewReader(data))
+		d.KnownFields(true)
+		decoder = d
+	}
+
+	var specs []jobSpec
+	if isSpecList(data) {
+		if err := decoder.Decode(&specs); err != nil && err != io.EOF {
+			return nil, fmt.Errorf("failed to parse job spec: %w", err)
+		}
+	} else {
+		for {
+			var spec jobSpec
+			if err := decoder.Decode(&spec); err == io.EOF {
+				break
+			} else if err != nil {
+				return nil, fmt.Errorf("failed to parse search %d: %w", len(specs)+1, err)
+			}
+			specs = append(specs, spec)
+		}
+	}
+	if len(specs) == 0 {
+		return nil, fmt.Errorf("no searches to run")
+	}
+
+	for i, spec := range specs {
+		if strings.TrimSpace(spec.Query) == "" {
+			return nil, fmt.Errorf("search %d has no query", i+1)
+		}
+		if err := checkOutput(spec.Output); err != nil {
+			return nil, fmt.Errorf("search %d: %w", i+1, err)
+		}
+	}
+	return specs, nil
+}
+
+// isSpecList returns whether job specs are a JSON or YAML list, rather than one spec after another
+func isSpecList(data []byte) bool {
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || line == "---" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		return line[0] == '[' || line == "-" || strings.HasPrefix(line, "- ")
+	}
+	return false
+}
+
+// query returns the spec's query with its parameters substituted
+func (s jobSpec) query() (string, error) {
+	var params searchParamsFlag
+	names := make([]string, 0, len(s.Params))
+	for name := range s.Params {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if err := params.Set(name + "=" + s.Params[name]); err != nil {
+			return "", err
+		}
+	}
+	return params.substitute(s.Query)
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseJobSpecs(t *testing.T) {
+	specs, err := parseJobSpecs(strings.NewReader(`{"query": "index=main | where count > $threshold$", "params": {"threshold:int": "500"}}
+{"query": "index=web", "earliest": "-1h", "output": "csv", "destination": "web.csv"}`))
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if len(specs) != 2 || specs[1].Earliest != "-1h" || specs[1].Destination != "web.csv" {
+		t.Fatalf("Unexpected specs: %+v", specs)
+	}
+	if query, err := specs[0].query(); err != nil || query != "index=main | where count > 500" {
+		t.Errorf("Expected the parameter to be substituted, got %q, %v", query, err)
+	}
+
+	list, err := parseJobSpecs(strings.NewReader(`[{"query": "index=a"}, {"query": "index=b"}]`))
+	if err != nil || len(list) != 2 || list[1].Query != "index=b" {
+		t.Errorf("Expected a list of specs, got %+v, %v", list, err)
+	}
+
+	yamlSpecs, err := parseJobSpecs(strings.NewReader(`# errors over the last day
+query: index=main | where count > $threshold$
+earliest: -24h
+params:
+  threshold:int: 500
+---
+query: index=web
+output: csv
+`))
+	if err != nil || len(yamlSpecs) != 2 || yamlSpecs[0].Earliest != "-24h" || yamlSpecs[1].Output != "csv" {
+		t.Fatalf("Expected YAML documents of specs, got %+v, %v", yamlSpecs, err)
+	}
+	if query, err := yamlSpecs[0].query(); err != nil || query != "index=main | where count > 500" {
+		t.Errorf("Expected the YAML parameter to be substituted, got %q, %v", query, err)
+	}
+
+	yamlList, err := parseJobSpecs(strings.NewReader("- query: index=a\n- query: index=b\n  latest: -1h\n"))
+	if err != nil || len(yamlList) != 2 || yamlList[1].Latest != "-1h" {
+		t.Errorf("Expected a YAML list of specs, got %+v, %v", yamlList, err)
+	}
+
+	for input, want := range map[string]string{
+		``: "no searches to run",
+		`{"query": "index=a", "earliset": "-1h"}`: `unknown field "earliset"`,
+		`{"earliest": "-1h"}`:                     "search 1 has no query",
+		`{"query": "index=a", "output": "xml"}`:   "search 1:",
+		"query: index=a\nearliset: -1h":           "field earliset not found",
+	} {
+		if _, err := parseJobSpecs(strings.NewReader(input)); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("Expected an error containing %q for %s, got: %v", want, input, err)
+		}
+	}
+}
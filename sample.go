@@ -0,0 +1,103 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/kitproj/splunk-cli/internal/output"
+	"github.com/kitproj/splunk-cli/pkg/splunk"
+)
+
+func sampleCommand() *command {
+	var index, sourcetype, format *string
+	var count *int
+	var last *time.Duration
+	var raw *bool
+	return &command{
+		name:  "sample",
+		short: "Print a few recent events of an index or sourcetype",
+		long:  "Print a few recent events of an index or sourcetype, e.g. to write field extractions.\nThe search runs in fast mode and stops after --count events, so it returns quickly even on busy indexes.",
+		flags: func(flags *flag.FlagSet) {
+			index = flags.String("index", "", "index to sample (default: the user's default indexes)")
+			sourcetype = flags.String("sourcetype", "", "sourcetype to sample")
+			count = flags.Int("count", 10, "number of events to print")
+			last = flags.Duration("last", 24*time.Hour, "sample events from this long ago until now")
+			raw = flags.Bool("raw", false, "print only each event's raw text, one per line")
+			format = outputFlag(flags)
+		},
+		run: func(ctx context.Context, args []string) error {
+			return executeCommand(ctx, func(ctx context.Context) error {
+				return runSample(ctx, *index, *sourcetype, *count, *last, *raw, *format)
+			})
+		},
+	}
+}
+
+// runSample prints the most recent events of the index and sourcetype
+func runSample(ctx context.Context, index, sourcetype string, count int, last time.Duration, raw bool, format string) error {
+	writer, err := newWriter(os.Stdout, format)
+	if err != nil {
+		return err
+	}
+	if count <= 0 {
+		return fmt.Errorf("--count must be positive")
+	}
+
+	query := sampleQuery(index, sourcetype, count)
+	// Fast mode skips field discovery, which sampling doesn't need
+	opts := splunk.SearchOptions{
+		EarliestTime:     fmt.Sprintf("-%ds", int(last.Seconds())),
+		LatestTime:       "now",
+		AdhocSearchLevel: "fast",
+	}
+	hook := searchHook{client: client, query: query, earliest: opts.EarliestTime, latest: opts.LatestTime}
+	if err := hook.before(ctx); err != nil {
+		return err
+	}
+	recorded, err := hook.record()
+	if err != nil {
+		return err
+	}
+	defer recorded.remove()
+	if err := waitForJobSlot(ctx, client); err != nil {
+		return err
+	}
+
+	err = client.Export(ctx, query, opts, func(result map[string]interface{}) error {
+		if err := recorded.Write(result); err != nil {
+			return err
+		}
+		if raw {
+			_, err := fmt.Fprintln(os.Stdout, output.FormatValue(result["_raw"], "\n"))
+			return err
+		}
+		return writer.Write(result)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to sample events: %w", err)
+	}
+	if !raw {
+		if err := writer.Close(); err != nil {
+			return err
+		}
+	}
+	return hook.afterRecorded(ctx, "", recorded)
+}
+
+// sampleQuery returns the search for the first count events of the index and sourcetype
+func sampleQuery(index, sourcetype string, count int) string {
+	query := "search"
+	if index != "" {
+		query += " index=" + quoteSPL(index)
+	}
+	if sourcetype != "" {
+		query += " sourcetype=" + quoteSPL(sourcetype)
+	}
+	if index == "" && sourcetype == "" {
+		query += " *"
+	}
+	return query + fmt.Sprintf(" | head %d", count)
+}
@@ -0,0 +1,18 @@
+package main
+
+import "testing"
+
+func TestSampleQuery(t *testing.T) {
+	for _, tc := range []struct {
+		index, sourcetype string
+		want              string
+	}{
+		{"app", "nginx", `search index="app" sourcetype="nginx" | head 20`},
+		{"", "nginx", `search sourcetype="nginx" | head 20`},
+		{"", "", `search * | head 20`},
+	} {
+		if got := sampleQuery(tc.index, tc.sourcetype, 20); got != tc.want {
+			t.Errorf("Expected %s, got: %s", tc.want, got)
+		}
+	}
+}
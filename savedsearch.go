@@ -0,0 +1,244 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+
+	"github.com/kitproj/splunk-cli/pkg/splunk"
+)
+
+func savedSearchCommand() *command {
+	var listFormat, showFormat, runFormat *string
+	var runStrict, runProgressJSON *bool
+	var create, update splunk.SavedSearch
+	var updateFlags *flag.FlagSet
+	savedSearchFlags := func(flags *flag.FlagSet, search *splunk.SavedSearch) {
+		flags.StringVar(&search.Description, "description", "", "description of the saved search")
+		flags.StringVar(&search.CronSchedule, "cron", "", "cron schedule to run the saved search on, e.g. '0 * * * *'")
+		flags.StringVar(&search.EarliestTime, "earliest", "", "default earliest time of the saved search, e.g. -24h")
+		flags.StringVar(&search.LatestTime, "latest", "", "default latest time of the saved search, e.g. now")
+	}
+
+	return &command{
+		name:    "saved-search",
+		aliases: []string{"saved-searches"},
+		short:   "Manage and run saved searches",
+		subcommands: []*command{
+			{
+				name:    "list",
+				aliases: []string{"ls"},
+				short:   "List saved searches",
+				flags: func(flags *flag.FlagSet) {
+					listFormat = outputFlag(flags)
+				},
+				run: func(ctx context.Context, args []string) error {
+					return executeCommand(ctx, func(ctx context.Context) error {
+						return runSavedSearchList(ctx, *listFormat)
+					})
+				},
+			},
+			{
+				name:    "show",
+				args:    "<name>",
+				short:   "Print a saved search",
+				minArgs: 1,
+				maxArgs: 1,
+				flags: func(flags *flag.FlagSet) {
+					showFormat = outputFlag(flags)
+				},
+				run: func(ctx context.Context, args []string) error {
+					return executeCommand(ctx, func(ctx context.Context) error {
+						search, err := client.GetSavedSearch(ctx, args[0])
+						if err != nil {
+							return fmt.Errorf("failed to get saved search: %w", err)
+						}
+						return writeAll(os.Stdout, *showFormat, []map[string]interface{}{savedSearchRow(*search)})
+					})
+				},
+			},
+			{
+				name:    "create",
+				args:    "<name> <search>",
+				short:   "Create a saved search",
+				minArgs: 2,
+				maxArgs: 2,
+				flags: func(flags *flag.FlagSet) {
+					savedSearchFlags(flags, &create)
+				},
+				run: func(ctx context.Context, args []string) error {
+					create.Name = args[0]
+					create.Search = args[1]
+					return executeCommand(ctx, func(ctx context.Context) error {
+						if err := client.CreateSavedSearch(ctx, create); err != nil {
+							return fmt.Errorf("failed to create saved search: %w", err)
+						}
+						slog.Info("Created saved search", "name", create.Name)
+						return nil
+					})
+				},
+			},
+			{
+				name:    "update",
+				args:    "<name>",
+				short:   "Update a saved search's query, description, schedule or time range",
+				long:    "Update a saved search. Only the given flags are changed, and a flag given an empty value, e.g.\n--cron '', clears the field. Clearing the schedule unschedules the search.",
+				minArgs: 1,
+				maxArgs: 1,
+				flags: func(flags *flag.FlagSet) {
+					flags.StringVar(&update.Search, "search", "", "new query of the saved search")
+					savedSearchFlags(flags, &update)
+					updateFlags = flags
+				},
+				run: func(ctx context.Context, args []string) error {
+					update.Name = args[0]
+					var err error
+					update.Clear, err = clearedFields(updateFlags)
+					if err != nil {
+						return err
+					}
+					return executeCommand(ctx, func(ctx context.Context) error {
+						if err := client.UpdateSavedSearch(ctx, update); err != nil {
+							return fmt.Errorf("failed to update saved search: %w", err)
+						}
+						slog.Info("Updated saved search", "name", update.Name)
+						return nil
+					})
+				},
+			},
+			{
+				name:    "delete",
+				aliases: []string{"rm"},
+				args:    "<name>",
+				short:   "Delete a saved search",
+				minArgs: 1,
+				maxArgs: 1,
+				run: func(ctx context.Context, args []string) error {
+					return executeCommand(ctx, func(ctx context.Context) error {
+						if err := client.DeleteSavedSearch(ctx, args[0]); err != nil {
+							return fmt.Errorf("failed to delete saved search: %w", err)
+						}
+						slog.Info("Deleted saved search", "name", args[0])
+						return nil
+					})
+				},
+			},
+			{
+				name:    "run",
+				args:    "<name> [earliest-time] [latest-time]",
+				short:   "Dispatch a saved search and print its results",
+				long:    "Dispatch a saved search, wait for the job to complete and print its first 100 results.\nThe time range defaults to the saved search's own.",
+				minArgs: 1,
+				maxArgs: 3,
+				flags: func(flags *flag.FlagSet) {
+					runFormat = outputFlag(flags)
+					runStrict = flags.Bool("strict", false, "exit non-zero if the results are partial, e.g. because a peer was unavailable or only the first 100 were printed")
+					runProgressJSON = flags.Bool("progress-json", false, "report progress and the search's metrics on stderr as a JSON object per line")
+				},
+				run: func(ctx context.Context, args []string) error {
+					opts := splunk.SearchOptions{}
+					if len(args) >= 2 {
+						opts.EarliestTime = args[1]
+					}
+					if len(args) >= 3 {
+						opts.LatestTime = args[2]
+					}
+					return executeCommand(ctx, func(ctx context.Context) error {
+						return runSavedSearch(ctx, args[0], opts, *runFormat, *runStrict, newProgress(*runProgressJSON))
+					})
+				},
+			},
+		},
+	}
+}
+
+// runSavedSearchList prints every saved search
+func runSavedSearchList(ctx context.Context, format string) error {
+	writer, err := newWriter(os.Stdout, format)
+	if err != nil {
+		return err
+	}
+
+	searches, err := client.ListSavedSearches(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list saved searches: %w", err)
+	}
+
+	for _, search := range searches {
+		if err := writer.Write(savedSearchRow(search)); err != nil {
+			return err
+		}
+	}
+	return writer.Close()
+}
+
+// runSavedSearch dispatches a saved search, waits for it and prints its results
+func runSavedSearch(ctx context.Context, name string, opts splunk.SearchOptions, format string, strict bool, p *progress) error {
+	writer, err := newWriter(os.Stdout, format)
+	if err != nil {
+		return err
+	}
+
+	client.OnWarning = p.warning
+	p.report("running", fmt.Sprintf("Running saved search: %s\n", name), map[string]interface{}{"saved_search": name})
+
+	job := searchJob{
+		client:      client,
+		query:       "| savedsearch " + quoteSPL(name),
+		opts:        opts,
+		savedSearch: name,
+		progress:    p,
+		results:     splunk.ResultsOptions{Count: 100},
+	}
+	finished, err := job.run(ctx)
+	if err != nil {
+		return err
+	}
+	if err := writeResults(writer, finished.results); err != nil {
+		return err
+	}
+	p.summary(finished.sid, finished.status)
+	return checkComplete(finished.status, finished.results, finished.returned, 0, strict, p)
+}
+
+// savedSearchFields are the saved search fields, by their JSON names, of the flags that set them
+var savedSearchFields = map[string]string{
+	"description": "description",
+	"cron":        "cron_schedule",
+	"earliest":    "dispatch.earliest_time",
+	"latest":      "dispatch.latest_time",
+}
+
+// clearedFields returns the saved search fields that flags given an empty value clear. Flags that
+// weren't given leave their fields unchanged.
+func clearedFields(flags *flag.FlagSet) ([]string, error) {
+	var cleared []string
+	var err error
+	flags.Visit(func(f *flag.Flag) {
+		if f.Value.String() != "" {
+			return
+		}
+		if field, ok := savedSearchFields[f.Name]; ok {
+			cleared = append(cleared, field)
+		} else if f.Name == "search" {
+			err = withExitCode(exitUsage, fmt.Errorf("--search can't be empty"))
+		}
+	})
+	return cleared, err
+}
+
+// savedSearchRow returns a saved search as an output row
+func savedSearchRow(search splunk.SavedSearch) map[string]interface{} {
+	return map[string]interface{}{
+		"name":        search.Name,
+		"search":      search.Search,
+		"description": search.Description,
+		"cron":        search.CronSchedule,
+		"scheduled":   search.IsScheduled,
+		"disabled":    search.Disabled,
+		"earliest":    search.EarliestTime,
+		"latest":      search.LatestTime,
+	}
+}
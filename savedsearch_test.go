@@ -0,0 +1,29 @@
+package main
+
+import (
+	"flag"
+	"reflect"
+	"testing"
+)
+
+func TestClearedFields(t *testing.T) {
+	flags := flag.NewFlagSet("update", flag.ContinueOnError)
+	for _, name := range []string{"search", "description", "cron", "earliest", "latest"} {
+		flags.String(name, "", "")
+	}
+	if err := flags.Parse([]string{"--cron", "", "--description", "", "--earliest", "-1h"}); err != nil {
+		t.Fatal(err)
+	}
+	// Only the flags given an empty value clear their fields
+	cleared, err := clearedFields(flags)
+	if err != nil || !reflect.DeepEqual(cleared, []string{"cron_schedule", "description"}) {
+		t.Errorf("Expected the schedule and description to be cleared, got: %v, %v", cleared, err)
+	}
+
+	if err := flags.Parse([]string{"--search", ""}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := clearedFields(flags); err == nil {
+		t.Error("Expected an empty query to be refused")
+	}
+}
@@ -0,0 +1,666 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/kitproj/splunk-cli/internal/output"
+	"github.com/kitproj/splunk-cli/pkg/splunk"
+)
+
+func searchCommand() *command {
+	var opts searchArgs
+	var params searchParamsFlag
+	var format *string
+	return &command{
+		name:  "search",
+		args:  "<query> [earliest-time] [latest-time]",
+		short: "Run a Splunk search query",
+		long: "Run a Splunk search query, wait for the job to complete and print its first 100 results, or --count or --all of them.\n" +
+			"The time range defaults to the earliest and latest settings in the config file.\n" +
+			"The query may instead be the URL of a search in Splunk Web, which runs in its app and over its time range.\n" +
+			paramsHelp,
+		minArgs: 1,
+		maxArgs: 3,
+		flags: func(flags *flag.FlagSet) {
+			flags.StringVar(&opts.collect, "collect", "", "write results to a summary index, e.g. 'index=summary marker=\"report=daily\"'")
+			flags.BoolVar(&opts.oneshot, "oneshot", false, "return the results in the same request instead of polling a job, for small fast searches")
+			flags.StringVar(&opts.level, "level", "smart", searchLevelUsage)
+			flags.IntVar(&opts.count, "count", 100, "maximum number of results to print")
+			flags.IntVar(&opts.offset, "offset", 0, "number of results to skip before printing")
+			flags.BoolVar(&opts.all, "all", false, "print every result, fetching them in pages")
+			flags.Var(&opts.fields, "fields", fieldsUsage)
+			flags.StringVar(&opts.out, "out", "", "file to write results to (default: stdout)")
+			flags.BoolVar(&opts.yes, "yes", false, "print the results even if there are more than terminal_rows for a terminal, without asking")
+			flags.BoolVar(&opts.strict, "strict", false, "exit non-zero if the results are partial, e.g. because a peer was unavailable or not every result was printed")
+			flags.BoolVar(&opts.progressJSON, "progress-json", false, "report progress and the search's metrics on stderr as a JSON object per line")
+			flags.BoolVar(&opts.timeline, "timeline", false, "also report the number of events over time on stderr, to see when a spike happened")
+			flags.Var(&params, "param", paramFlagUsage)
+			flags.Var(&secretParamFlag{&params, "env"}, "param-env", paramEnvUsage)
+			flags.Var(&secretParamFlag{&params, "keyring"}, "param-keyring", paramKeyringUsage)
+			format = outputFlag(flags)
+		},
+		run: func(ctx context.Context, args []string) error {
+			query, err := params.substitute(args[0])
+			if err != nil {
+				return err
+			}
+			opts.query = query
+			if len(args) >= 2 {
+				opts.earliestTime = args[1]
+			}
+			if len(args) >= 3 {
+				opts.latestTime = args[2]
+			}
+			opts.format = *format
+			return executeCommand(ctx, func(ctx context.Context) error {
+				return runSearch(ctx, opts)
+			})
+		},
+	}
+}
+
+func exportCommand() *command {
+	var format, out *string
+	var partition *time.Duration
+	var concurrency *int
+	var resume *bool
+	var level *string
+	var params searchParamsFlag
+	return &command{
+		name:  "export",
+		args:  "<query> [earliest-time] [latest-time]",
+		short: "Stream all results of a search as they are produced",
+		long: "Stream all results of a search as they are produced, without creating a search job.\nUse this for large result sets; output defaults to ndjson.\n" +
+			"With --partition, the time range is split into slices exported as parallel jobs, which is much faster for long ranges.\n" +
+			"Results are still written in order, and a failed slice is retried without failing the others.\n" +
+			"Finished slices are kept until every slice succeeds, so --resume only re-exports the rest.\n" +
+			"The query may instead be the URL of a search in Splunk Web, which runs in its app and over its time range.\n" +
+			paramsHelp,
+		minArgs: 1,
+		maxArgs: 3,
+		flags: func(flags *flag.FlagSet) {
+			format = flags.String("output", "ndjson", "output format: "+strings.Join(output.Formats, ", "))
+			flags.StringVar(format, "o", "ndjson", "shorthand for --output")
+			out = flags.String("out", "", "file to write results to (default: stdout)")
+			partition = flags.Duration("partition", 0, "split the time range into slices of this length, e.g. 1h, exported in parallel")
+			concurrency = flags.Int("concurrency", 4, "number of slices to export at once with --partition")
+			resume = flags.Bool("resume", false, "with --partition, re-export only the slices that failed or never ran in the last run of the same export")
+			level = flags.String("level", "smart", searchLevelUsage)
+			flags.Var(&params, "param", paramFlagUsage)
+			flags.Var(&secretParamFlag{&params, "env"}, "param-env", paramEnvUsage)
+			flags.Var(&secretParamFlag{&params, "keyring"}, "param-keyring", paramKeyringUsage)
+		},
+		run: func(ctx context.Context, args []string) error {
+			query, err := params.substitute(args[0])
+			if err != nil {
+				return err
+			}
+			if err := checkSearchLevel(*level); err != nil {
+				return err
+			}
+			opts := splunk.SearchOptions{AdhocSearchLevel: *level}
+			if len(args) >= 2 {
+				opts.EarliestTime = args[1]
+			}
+			if len(args) >= 3 {
+				opts.LatestTime = args[2]
+			}
+			return executeCommand(ctx, func(ctx context.Context) error {
+				return runExport(ctx, query, opts, *format, *out, *partition, *concurrency, *resume)
+			})
+		},
+	}
+}
+
+func resultsCommand() *command {
+	var postProcess, format, out *string
+	var count *int
+	var yes *bool
+	var fields fieldsFlag
+	return &command{
+		name:    "results",
+		args:    "<sid>",
+		short:   "Print an existing job's results, optionally post-processed",
+		minArgs: 1,
+		maxArgs: 1,
+		flags: func(flags *flag.FlagSet) {
+			postProcess = flags.String("post", "", "post-process search to run over the job's results, e.g. '| stats count by status'")
+			count = flags.Int("count", 100, "maximum number of results to return (0 for all)")
+			out = flags.String("out", "", "file to write results to (default: stdout)")
+			yes = flags.Bool("yes", false, "print the results even if there are more than terminal_rows for a terminal, without asking")
+			flags.Var(&fields, "fields", fieldsUsage)
+			format = outputFlag(flags)
+		},
+		run: func(ctx context.Context, args []string) error {
+			return executeCommand(ctx, func(ctx context.Context) error {
+				return runResults(ctx, args[0], *postProcess, *count, *format, *out, *yes, fields)
+			})
+		},
+	}
+}
+
+func followCommand() *command {
+	var format *string
+	return &command{
+		name:    "follow",
+		args:    "<sid>",
+		short:   "Print a job's results as they arrive, until it finalizes",
+		long:    "Print a job's preview results as they arrive, until it finalizes.\nThe job is left running if follow is interrupted.",
+		minArgs: 1,
+		maxArgs: 1,
+		flags: func(flags *flag.FlagSet) {
+			format = outputFlag(flags)
+		},
+		run: func(ctx context.Context, args []string) error {
+			return executeCommand(ctx, func(ctx context.Context) error {
+				return runFollow(ctx, os.Stdout, args[0], *format)
+			})
+		},
+	}
+}
+
+// searchLevels are Splunk's search modes, which trade discovering fields for speed
+var searchLevels = []string{"fast", "smart", "verbose"}
+
+const searchLevelUsage = "search mode: fast extracts only the fields the search uses, smart also discovers fields for searches that return events, and verbose discovers every field"
+
+// checkSearchLevel checks a --level is one of Splunk's search modes, if it's set
+func checkSearchLevel(level string) error {
+	if level != "" && !slices.Contains(searchLevels, level) {
+		return withExitCode(exitUsage, fmt.Errorf("unknown search level %q (must be one of %s)", level, strings.Join(searchLevels, ", ")))
+	}
+	return nil
+}
+
+// searchArgs are the command-line arguments of the search command
+type searchArgs struct {
+	query        string
+	earliestTime string
+	latestTime   string
+	format       string
+	// out is the file to write results to, stdout if unset
+	out string
+	// yes prints any number of results to a terminal without asking
+	yes bool
+	// app runs the search in this app instead of the profile's, if set
+	app string
+	// collect holds the arguments of a "| collect" command to append, if any
+	collect string
+	// oneshot runs the search with exec_mode=oneshot, which has no job to poll
+	oneshot bool
+	// level is the search mode: fast, smart or verbose
+	level string
+	// count and offset select the results to print, or all of them if all is set
+	count  int
+	offset int
+	all    bool
+	// fields are the only fields of the results to get, or all of them if unset
+	fields fieldsFlag
+	// strict fails the search if its results are partial
+	strict bool
+	// progressJSON reports progress as JSON lines instead of text
+	progressJSON bool
+	// timeline reports the number of events over time once the job is done
+	timeline bool
+}
+
+func runSearch(ctx context.Context, args searchArgs) error {
+	namespace := searchNamespace()
+	if args.app != "" {
+		namespace.App = args.app
+	}
+	// A search pasted from Splunk Web runs in its app, over its time range unless one is given
+	if link, ok := parseSearchLink(args.query); ok {
+		args.query = link.query
+		args.earliestTime = defaultString(args.earliestTime, link.earliest)
+		args.latestTime = defaultString(args.latestTime, link.latest)
+		namespace.App = link.app
+	}
+	if args.count < 0 || args.offset < 0 {
+		return withExitCode(exitUsage, fmt.Errorf("--count and --offset must not be negative"))
+	}
+	if args.oneshot && (args.all || args.count == 0 || args.offset > 0) {
+		return withExitCode(exitUsage, fmt.Errorf("--oneshot returns at most --count results, so can't be used with --all or --offset"))
+	}
+	if args.oneshot && args.timeline {
+		return withExitCode(exitUsage, fmt.Errorf("--timeline needs a search job, so can't be used with --oneshot"))
+	}
+	if args.oneshot && len(args.fields) > 0 {
+		return withExitCode(exitUsage, fmt.Errorf("--fields selects the fields of a job's results, so can't be used with --oneshot (use '| fields' in the query instead)"))
+	}
+	if err := checkSearchLevel(args.level); err != nil {
+		return err
+	}
+	query := ensureSearchCommand(args.query)
+	earliestTime := defaultString(args.earliestTime, settings.Earliest)
+	latestTime := defaultString(args.latestTime, settings.Latest)
+
+	// Validate the format and collect arguments before dispatching the search
+	if err := checkOutput(args.format); err != nil {
+		return err
+	}
+	if args.collect != "" {
+		collect, err := collectCommand(args.collect)
+		if err != nil {
+			return err
+		}
+		query += " " + collect
+	}
+
+	// Progress goes to stderr, so stdout only contains results
+	p := newProgress(args.progressJSON)
+	client.OnWarning = p.warning
+	p.report("running", fmt.Sprintf("Running search: %s\n", query), map[string]interface{}{"query": query})
+
+	opts := splunk.SearchOptions{
+		EarliestTime:     earliestTime,
+		LatestTime:       latestTime,
+		AdhocSearchLevel: args.level,
+		Namespace:        namespace,
+	}
+	if args.timeline {
+		opts.StatusBuckets = timelineBuckets
+	}
+	if args.oneshot {
+		return runOneshot(ctx, args, query, opts, p)
+	}
+
+	job := searchJob{client: client, query: query, opts: opts, progress: p, results: args.results()}
+	if args.all {
+		// Results are written as they're downloaded, so jobs with a great many don't need the memory to hold them
+		var w io.WriteCloser
+		defer func() {
+			if w != nil {
+				w.Close()
+			}
+		}()
+		job.stream = func(status *splunk.Search) (output.Writer, error) {
+			if args.out == "" {
+				if err := confirmOutput(max(int(status.Content.ResultCount)-args.offset, 0), args.yes); err != nil {
+					return nil, err
+				}
+			}
+			var err error
+			if w, err = openOutput(args.out); err != nil {
+				return nil, err
+			}
+			return newWriter(w, args.format)
+		}
+	}
+	finished, err := job.run(ctx)
+	if err != nil {
+		return err
+	}
+	if !args.all {
+		if err := printResults(args, finished.results); err != nil {
+			return err
+		}
+	}
+	if args.timeline {
+		// The results are already printed, so a timeline that can't be fetched isn't worth failing for
+		if buckets, err := client.GetTimeline(ctx, finished.sid); err != nil {
+			slog.Warn("Failed to get the timeline", "sid", finished.sid, "err", err)
+		} else {
+			p.timeline(buckets)
+		}
+	}
+	p.summary(finished.sid, finished.status)
+	return checkComplete(finished.status, finished.results, finished.returned, args.offset, args.strict, p)
+}
+
+// runOneshot runs a search that returns its results in a single request, without a job, and prints them
+func runOneshot(ctx context.Context, args searchArgs, query string, opts splunk.SearchOptions, p *progress) error {
+	hook := searchHook{client: client, query: query, earliest: opts.EarliestTime, latest: opts.LatestTime}
+	if err := hook.before(ctx); err != nil {
+		return err
+	}
+	if err := waitForJobSlot(ctx, client); err != nil {
+		return err
+	}
+
+	start := time.Now()
+	results, err := client.OneshotSearch(ctx, query, opts, args.count)
+	if err != nil {
+		return searchFailed(fmt.Errorf("failed to run search: %w", err))
+	}
+	elapsed := time.Since(start)
+	p.report("completed", fmt.Sprintf("Search completed. Found %d results.\n\n", len(results.Results)), map[string]interface{}{"results": len(results.Results)})
+	if err := hook.after(ctx, "", results); err != nil {
+		return err
+	}
+	if err := printResults(args, results); err != nil {
+		return err
+	}
+	p.oneshotSummary(elapsed, len(results.Results))
+	return checkComplete(nil, results, len(results.Results), 0, args.strict, p)
+}
+
+// timelineBuckets is the most buckets Splunk splits a job's timeline into with --timeline, as
+// Splunk Web does
+const timelineBuckets = 300
+
+// resultsPageSize is how many results are fetched per request with --all, well under Splunk's default
+// maxresultrows of 50,000
+const resultsPageSize = 10000
+
+// results returns the options that select the results to print
+func (a searchArgs) results() splunk.ResultsOptions {
+	if a.all || a.count == 0 {
+		return splunk.ResultsOptions{Offset: a.offset, Fields: a.fields, PageSize: resultsPageSize}
+	}
+	return splunk.ResultsOptions{Offset: a.offset, Count: a.count, Fields: a.fields, PageSize: resultsPageSize}
+}
+
+// jobFailedError describes why a search job failed, from its error messages
+func jobFailedError(status *splunk.Search) error {
+	var reasons []string
+	for _, message := range status.Content.Messages {
+		if t := strings.ToUpper(message.Type); t == "ERROR" || t == "FATAL" {
+			reasons = append(reasons, message.Text)
+		}
+	}
+	if len(reasons) == 0 {
+		return fmt.Errorf("search %s failed", status.SID)
+	}
+	return fmt.Errorf("search %s failed: %s", status.SID, strings.Join(reasons, "; "))
+}
+
+// checkComplete reports why results are partial, if they are, failing if strict.
+// Results are partial if Splunk warned that results are missing, e.g. that a peer was unavailable,
+// or reported an error, the job was finalized before it finished, or not every result was returned.
+func checkComplete(status *splunk.Search, results *splunk.SearchResult, returned, offset int, strict bool, p *progress) error {
+	reasons := partialReasons(status, results, returned, offset)
+	if len(reasons) == 0 {
+		return nil
+	}
+	p.report("partial", fmt.Sprintf("Partial results: %s.\n", strings.Join(reasons, "; ")), map[string]interface{}{"reasons": reasons})
+	if strict {
+		return withExitCode(exitPartial, fmt.Errorf("results are partial"))
+	}
+	return nil
+}
+
+// partialReasons returns why results are partial, if they are, given how many were returned from the offset
+func partialReasons(status *splunk.Search, results *splunk.SearchResult, returned, offset int) []string {
+	var reasons []string
+	if missing := uniqueTexts(searchMessages(status, results).Incomplete()); len(missing) > 0 {
+		reasons = append(reasons, fmt.Sprintf("Splunk reported %d warning(s) of missing results", len(missing)))
+	}
+	if status == nil {
+		return reasons
+	}
+	if status.Content.IsFinalized {
+		reasons = append(reasons, "the job was finalized before it finished")
+	}
+	if int(status.Content.ResultCount) > offset+returned {
+		if offset == 0 {
+			reasons = append(reasons, fmt.Sprintf("only the first %d of %d results were returned", returned, status.Content.ResultCount))
+		} else {
+			reasons = append(reasons, fmt.Sprintf("only results %d to %d of %d were returned", offset+1, offset+returned, status.Content.ResultCount))
+		}
+	}
+	return reasons
+}
+
+// searchWarnings returns the texts of the warnings about a search job and its results, without duplicates
+func searchWarnings(status *splunk.Search, results *splunk.SearchResult) []string {
+	return uniqueTexts(searchMessages(status, results).Warnings())
+}
+
+// searchMessages returns the messages about a search job, then those about its results
+func searchMessages(status *splunk.Search, results *splunk.SearchResult) splunk.Messages {
+	// Oneshot searches have no job status
+	if status == nil {
+		return results.Messages
+	}
+	return append(append(splunk.Messages{}, status.Content.Messages...), results.Messages...)
+}
+
+// uniqueTexts returns the texts of messages, without duplicates
+func uniqueTexts(messages splunk.Messages) []string {
+	var texts []string
+	seen := map[string]bool{}
+	for _, message := range messages {
+		if !seen[message.Text] {
+			seen[message.Text] = true
+			texts = append(texts, message.Text)
+		}
+	}
+	return texts
+}
+
+// runExport streams the results of a search to stdout or a file as they are produced
+func runExport(ctx context.Context, query string, opts splunk.SearchOptions, format, out string, partition time.Duration, concurrency int, resume bool) error {
+	opts.Namespace = searchNamespace()
+	if link, ok := parseSearchLink(query); ok {
+		query = link.query
+		opts.EarliestTime = defaultString(opts.EarliestTime, link.earliest)
+		opts.LatestTime = defaultString(opts.LatestTime, link.latest)
+		opts.Namespace.App = link.app
+	}
+	opts.EarliestTime = defaultString(opts.EarliestTime, settings.Earliest)
+	opts.LatestTime = defaultString(opts.LatestTime, settings.Latest)
+
+	w, err := openOutput(out)
+	if err != nil {
+		return err
+	}
+	defer w.Close()
+
+	writer, err := newWriter(w, format)
+	if err != nil {
+		return err
+	}
+
+	// An export has no job, so the post_search hook gets no SID
+	hook := searchHook{client: client, query: query, earliest: opts.EarliestTime, latest: opts.LatestTime}
+	if err := hook.before(ctx); err != nil {
+		return err
+	}
+	recorded, err := hook.record()
+	if err != nil {
+		return err
+	}
+	defer recorded.remove()
+	writer = hookWriter{Writer: writer, recorded: recorded}
+
+	if partition > 0 {
+		count, err := exportPartitions(ctx, query, opts, partition, concurrency, resume, writer)
+		if closeErr := writer.Close(); err == nil {
+			err = closeErr
+		}
+		slog.Info("Exported results", "count", count)
+		if err != nil {
+			return err
+		}
+		return hook.afterRecorded(ctx, "", recorded)
+	}
+
+	if err := waitForJobSlot(ctx, client); err != nil {
+		return err
+	}
+
+	count := 0
+	err = client.Export(ctx, ensureSearchCommand(query), opts, func(result map[string]interface{}) error {
+		count++
+		return writer.Write(result)
+	})
+	if err != nil {
+		return searchFailed(fmt.Errorf("failed to export search: %w", err))
+	}
+	if err := writer.Close(); err != nil {
+		return err
+	}
+
+	slog.Info("Exported results", "count", count)
+	return hook.afterRecorded(ctx, "", recorded)
+}
+
+// runResults prints the results of an existing search job, optionally post-processed server-side
+func runResults(ctx context.Context, sid, postProcess string, count int, format, out string, yes bool, fields []string) error {
+	if err := checkOutput(format); err != nil {
+		return err
+	}
+	// A post-process search changes how many results there are, usually to fewer, so only the job's own are checked
+	if out == "" && postProcess == "" {
+		status, err := client.GetSearchStatus(ctx, sid)
+		if err != nil {
+			return fmt.Errorf("failed to get search status: %w", err)
+		}
+		rows := int(status.Content.ResultCount)
+		if count > 0 {
+			rows = min(rows, count)
+		}
+		if err := confirmOutput(rows, yes); err != nil {
+			return err
+		}
+	}
+
+	w, err := openOutput(out)
+	if err != nil {
+		return err
+	}
+	defer w.Close()
+	writer, err := newWriter(w, format)
+	if err != nil {
+		return err
+	}
+
+	// Results are written as they're decoded, so a job with a great many doesn't need the memory to hold them
+	opts := splunk.ResultsOptions{Count: count, PostProcess: postProcess, Fields: fields, PageSize: resultsPageSize}
+	if _, err := client.StreamResults(ctx, sid, opts, writer.Write); err != nil {
+		return fmt.Errorf("failed to get search results: %w", err)
+	}
+	return writer.Close()
+}
+
+// runFollow writes a job's preview results to w as they grow, until the job finalizes, or only its
+// final results if it transforms its events
+func runFollow(ctx context.Context, w io.Writer, sid, format string) error {
+	writer, err := newWriter(w, format)
+	if err != nil {
+		return err
+	}
+	hook, err := jobHook(ctx, sid)
+	if err != nil {
+		return err
+	}
+	if err := hook.before(ctx); err != nil {
+		return err
+	}
+	recorded, err := hook.record()
+	if err != nil {
+		return err
+	}
+	defer recorded.remove()
+	writer = hookWriter{Writer: writer, recorded: recorded}
+
+	printed := 0
+	printNew := func() error {
+		// A count of 0 returns everything from the offset onwards
+		opts := splunk.ResultsOptions{Offset: printed, Preview: true}
+		_, err := client.StreamResults(ctx, sid, opts, func(result map[string]interface{}) error {
+			printed++
+			return writer.Write(result)
+		})
+		if err != nil {
+			return fmt.Errorf("failed to get preview results: %w", err)
+		}
+		return nil
+	}
+
+	// Stop waiting as soon as printing fails. Following doesn't own the job, so leave it running
+	// if we stop or are interrupted.
+	waitCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	var previewErr error
+	transforming := false
+	waiter := splunk.NewJobWaiter(client)
+	waiter.LeaveRunning = true
+	waiter.OnProgress = func(status *splunk.Search) {
+		if previewErr != nil || status.Content.IsDone {
+			return
+		}
+		// The preview of a transforming search, e.g. a stats table, is replaced rather than added
+		// to, so only its final results are printed
+		if status.Content.ReportSearch != "" {
+			if !transforming {
+				transforming = true
+				slog.Info("The search transforms its events, so its results are printed when it finalizes", "sid", sid)
+			}
+			return
+		}
+		if previewErr = printNew(); previewErr != nil {
+			cancel()
+		}
+	}
+
+	_, err = waiter.Wait(waitCtx, sid)
+	if previewErr != nil {
+		return previewErr
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := printNew(); err != nil {
+		return err
+	}
+	if err := writer.Close(); err != nil {
+		return err
+	}
+	slog.Info("Search finalized", "count", printed)
+	return hook.afterRecorded(ctx, sid, recorded)
+}
+
+// waitForJobSlot waits until the user is under the profile's share of their search job quota, if it
+// has one and the client can count the user's jobs
+func waitForJobSlot(ctx context.Context, client splunk.API) error {
+	quota, ok := client.(splunk.JobQuota)
+	if settings.JobQuotaShare <= 0 || !ok {
+		return nil
+	}
+	throttle := splunk.NewJobThrottle(quota, settings.JobQuotaShare)
+	throttle.OnWait = func(running, limit int) {
+		slog.Info("Waiting for a search job slot", "running", running, "limit", limit)
+	}
+	return throttle.Wait(ctx)
+}
+
+// printResults writes a search's results to its --out file or stdout, asking first if there are
+// a great many for a terminal
+func printResults(args searchArgs, results *splunk.SearchResult) error {
+	if args.out == "" {
+		if err := confirmOutput(len(results.Results), args.yes); err != nil {
+			return err
+		}
+	}
+	w, err := openOutput(args.out)
+	if err != nil {
+		return err
+	}
+	defer w.Close()
+	writer, err := newWriter(w, args.format)
+	if err != nil {
+		return err
+	}
+	return writeResults(writer, results)
+}
+
+// writeResults writes every result and closes the writer
+func writeResults(writer output.Writer, results *splunk.SearchResult) error {
+	for _, result := range results.Results {
+		if err := writer.Write(result); err != nil {
+			return err
+		}
+	}
+	return writer.Close()
+}
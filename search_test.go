@@ -0,0 +1,156 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/kitproj/splunk-cli/internal/config"
+	"github.com/kitproj/splunk-cli/pkg/splunk"
+	"github.com/kitproj/splunk-cli/pkg/splunk/splunktest"
+)
+
+func TestSearchWarnings(t *testing.T) {
+	status := &splunk.Search{}
+	status.Content.Messages = splunk.Messages{{Type: "WARN", Text: "Peer idx2 was unavailable"}, {Type: "INFO", Text: "Search took 1s"}}
+	results := &splunk.SearchResult{Messages: splunk.Messages{{Type: "WARN", Text: "Peer idx2 was unavailable"}}}
+
+	warnings := searchWarnings(status, results)
+	if len(warnings) != 1 || warnings[0] != "Peer idx2 was unavailable" {
+		t.Errorf("Expected the peer warning once, got: %q", warnings)
+	}
+}
+
+func TestPartialReasons(t *testing.T) {
+	status := &splunk.Search{}
+	status.Content.ResultCount = 250
+	results := &splunk.SearchResult{Results: make([]map[string]interface{}, 100)}
+	if reasons := partialReasons(status, results, len(results.Results), 0); len(reasons) != 1 || reasons[0] != "only the first 100 of 250 results were returned" {
+		t.Errorf("Expected the results to be truncated, got: %q", reasons)
+	}
+
+	if reasons := partialReasons(status, results, len(results.Results), 100); len(reasons) != 1 || reasons[0] != "only results 101 to 200 of 250 were returned" {
+		t.Errorf("Expected the results to be a page, got: %q", reasons)
+	}
+	if reasons := partialReasons(status, results, len(results.Results), 150); len(reasons) != 0 {
+		t.Errorf("Expected the last page to be complete, got: %q", reasons)
+	}
+
+	status.Content.ResultCount = 100
+	if reasons := partialReasons(status, results, len(results.Results), 0); len(reasons) != 0 {
+		t.Errorf("Expected complete results, got: %q", reasons)
+	}
+	if err := checkComplete(status, results, len(results.Results), 0, true, newProgress(false)); err != nil {
+		t.Errorf("Expected no error, got: %v", err)
+	}
+
+	// A warning that doesn't mean results are missing doesn't make them partial
+	results.Messages = splunk.Messages{{Type: "WARN", Text: "The 'search' command's 'foo' argument is deprecated"}}
+	if reasons := partialReasons(status, results, len(results.Results), 0); len(reasons) != 0 {
+		t.Errorf("Expected complete results, got: %q", reasons)
+	}
+
+	status.Content.IsFinalized = true
+	results.Messages = splunk.Messages{{Type: "WARN", Text: "Peer idx2 was unavailable"}}
+	if reasons := partialReasons(status, results, len(results.Results), 0); len(reasons) != 2 {
+		t.Errorf("Expected a warning and finalization, got: %q", reasons)
+	}
+	if err := checkComplete(status, results, len(results.Results), 0, true, newProgress(false)); err == nil {
+		t.Error("Expected partial results to fail in strict mode")
+	}
+}
+
+func TestSearchAll(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the hooks use sh")
+	}
+	rows := make([]map[string]interface{}, 25)
+	for i := range rows {
+		rows[i] = map[string]interface{}{"n": i}
+	}
+	s := splunktest.NewServer(t)
+	s.AddJob("1", rows...)
+	savedSettings, savedClient := settings, client
+	defer func() { settings, client = savedSettings, savedClient }()
+	settings = &config.Profile{PostSearch: `test "$SPLUNK_RESULT_COUNT" = 23`}
+	client = s.Client()
+
+	out := filepath.Join(t.TempDir(), "results.ndjson")
+	args := searchArgs{query: "index=main", format: "ndjson", out: out, all: true, offset: 2}
+	if err := runSearch(context.Background(), args); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	data, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != 23 || lines[0] != `{"n":2}` || lines[22] != `{"n":24}` {
+		t.Errorf("Expected every result from the offset on, got: %s", data)
+	}
+}
+
+func TestFollow(t *testing.T) {
+	// The job is done on the given poll, or never if it's 0
+	newServer := func(done int, reportSearch string, preview http.HandlerFunc) *splunktest.Server {
+		s := splunktest.NewServer(t)
+		polls := 0
+		s.HandleFunc("GET /services/search/jobs/1", func(w http.ResponseWriter, r *http.Request) {
+			polls++
+			fmt.Fprintf(w, `{"content":{"isDone":%t,"reportSearch":%q}}`, polls == done, reportSearch)
+		})
+		s.HandleFunc("GET /services/search/jobs/1/results_preview", preview)
+		return s
+	}
+	rows := func(n int) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			var results []string
+			offset, _ := strconv.Atoi(r.FormValue("offset"))
+			for i := offset; i < n; i++ {
+				results = append(results, fmt.Sprintf(`{"n":"%d"}`, i))
+			}
+			fmt.Fprintf(w, `{"results":[%s]}`, strings.Join(results, ","))
+		}
+	}
+	saved := client
+	defer func() { client = saved }()
+
+	// Event previews are added to, so each new result is printed once
+	var out strings.Builder
+	client = newServer(3, "", rows(3)).Client()
+	if err := runFollow(context.Background(), &out, "1", "ndjson"); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if want := "{\"n\":\"0\"}\n{\"n\":\"1\"}\n{\"n\":\"2\"}\n"; out.String() != want {
+		t.Errorf("Expected each result once, got: %s", out.String())
+	}
+
+	// A transforming search's previews are replaced, so only its final results are printed
+	out.Reset()
+	previews := 0
+	client = newServer(3, "stats count", func(w http.ResponseWriter, r *http.Request) {
+		previews++
+		fmt.Fprintf(w, `{"results":[{"count":"%d"}]}`, previews)
+	}).Client()
+	if err := runFollow(context.Background(), &out, "1", "ndjson"); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if out.String() != "{\"count\":\"1\"}\n" {
+		t.Errorf("Expected only the final results, got: %s", out.String())
+	}
+
+	// Following stops as soon as a preview can't be got, without waiting for the job
+	client = newServer(0, "", func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"messages":[{"type":"FATAL","text":"boom"}]}`, http.StatusInternalServerError)
+	}).Client()
+	if err := runFollow(context.Background(), io.Discard, "1", "ndjson"); err == nil || !strings.Contains(err.Error(), "preview") {
+		t.Errorf("Expected the preview error, got: %v", err)
+	}
+}
@@ -0,0 +1,200 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/kitproj/splunk-cli/internal/output"
+	"github.com/kitproj/splunk-cli/pkg/splunk"
+)
+
+// searchJob runs a search as a job and gets its results, with the profile's hooks around it: the
+// pre_search hook, waiting for a job slot, dispatching the job, waiting for it, getting its results
+// and the post_search hook, in that order. The commands and MCP tools that wait for a search's
+// results all run it with a searchJob, so none of them skips a step.
+type searchJob struct {
+	client splunk.API
+	// query is run over the time range of opts
+	query string
+	opts  splunk.SearchOptions
+	// savedSearch, if set, is dispatched instead, with query describing it to the hooks
+	savedSearch string
+	// progress, if set, reports the job being created, its progress and its completion
+	progress *progress
+	// waiter waits for the job, or a default one if nil. The job is cancelled by run, rather than
+	// the waiter, if waiting fails.
+	waiter *splunk.JobWaiter
+	// timeout, if set, limits how long the job is waited for. With partial, the preview results of
+	// a job that times out are got instead of failing.
+	timeout time.Duration
+	partial bool
+	// results selects the results to get
+	results splunk.ResultsOptions
+	// stream, if set, is called once the job is done for a writer to write its results to as
+	// they're downloaded, rather than holding them in memory. run closes the writer.
+	stream func(status *splunk.Search) (output.Writer, error)
+}
+
+// finishedJob is a job a searchJob ran and its results
+type finishedJob struct {
+	sid     string
+	status  *splunk.Search
+	results *splunk.SearchResult
+	// returned is how many results were got, which are only counted, not kept, when streamed
+	returned int
+	// timedOut is whether the job timed out, so the results are its preview
+	timedOut bool
+}
+
+// hook returns the hook that describes the search
+func (j searchJob) hook() searchHook {
+	return searchHook{client: j.client, query: j.query, earliest: j.opts.EarliestTime, latest: j.opts.LatestTime}
+}
+
+// run runs the search job to completion, returning it with its results
+func (j searchJob) run(ctx context.Context) (*finishedJob, error) {
+	hook := j.hook()
+	if err := hook.before(ctx); err != nil {
+		return nil, err
+	}
+	if err := waitForJobSlot(ctx, j.client); err != nil {
+		return nil, err
+	}
+
+	job := &finishedJob{}
+	var err error
+	if j.savedSearch != "" {
+		if job.sid, err = j.client.DispatchSavedSearch(ctx, j.savedSearch, j.opts); err != nil {
+			return nil, searchFailed(fmt.Errorf("failed to dispatch saved search: %w", err))
+		}
+	} else if job.sid, err = j.client.RunSearch(ctx, j.query, j.opts); err != nil {
+		return nil, searchFailed(fmt.Errorf("failed to run search: %w", err))
+	}
+
+	if job.status, err = j.wait(ctx, job.sid); err != nil {
+		if !j.partial || ctx.Err() != nil || !errors.Is(err, context.DeadlineExceeded) {
+			cancelSearch(ctx, j.client, job.sid)
+			return nil, err
+		}
+		// The job timed out, so get the results it has so far, then cancel it
+		job.timedOut = true
+		job.status, job.results, err = previewResults(ctx, j.client, job.sid, j.results)
+		cancelSearch(ctx, j.client, job.sid)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get the results so far: %w", err)
+		}
+		job.returned = len(job.results.Results)
+		return job, hook.after(ctx, job.sid, job.results)
+	}
+
+	if j.stream != nil {
+		if err := j.streamResults(ctx, hook, job); err != nil {
+			return nil, err
+		}
+		return job, nil
+	}
+	if job.results, err = j.client.GetResults(ctx, job.sid, j.results); err != nil {
+		return nil, fmt.Errorf("failed to get search results: %w", err)
+	}
+	job.returned = len(job.results.Results)
+	return job, hook.after(ctx, job.sid, job.results)
+}
+
+// wait waits for the job to complete, reporting its progress, failing if the job did
+func (j searchJob) wait(ctx context.Context, sid string) (*splunk.Search, error) {
+	p := j.progress
+	if p != nil {
+		p.report("created", fmt.Sprintf("Search job created: %s\n", sid), map[string]interface{}{"sid": sid})
+	}
+
+	waiter := splunk.NewJobWaiter(j.client)
+	if j.waiter != nil {
+		w := *j.waiter
+		waiter = &w
+	}
+	waiter.LeaveRunning = true
+	if p != nil {
+		// Report each change of dispatch state
+		var lastState string
+		onProgress := waiter.OnProgress
+		waiter.OnProgress = func(status *splunk.Search) {
+			if onProgress != nil {
+				onProgress(status)
+			}
+			if !status.Content.IsDone && status.Content.DispatchState != lastState {
+				p.report("progress", fmt.Sprintf("Search in progress (%s)...\n", status.Content.DispatchState),
+					map[string]interface{}{"sid": sid, "state": status.Content.DispatchState})
+				lastState = status.Content.DispatchState
+			}
+		}
+	}
+	if j.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, j.timeout)
+		defer cancel()
+	}
+
+	status, err := waiter.Wait(ctx, sid)
+	if err != nil {
+		return nil, err
+	}
+	if status.Content.DispatchState == "FAILED" {
+		return nil, withExitCode(exitSearchFailed, jobFailedError(status))
+	}
+	if p != nil {
+		p.report("completed", fmt.Sprintf("Search completed. Found %d results.\n\n", status.Content.ResultCount),
+			map[string]interface{}{"sid": sid, "results": int64(status.Content.ResultCount)})
+	}
+	return status, nil
+}
+
+// streamResults writes the results of the done job to the stream's writer as they're downloaded,
+// recording them for the post_search hook, which is run once they're written
+func (j searchJob) streamResults(ctx context.Context, hook searchHook, job *finishedJob) error {
+	writer, err := j.stream(job.status)
+	if err != nil {
+		return err
+	}
+	recorded, err := hook.record()
+	if err != nil {
+		return err
+	}
+	defer recorded.remove()
+	writer = hookWriter{Writer: writer, recorded: recorded}
+
+	messages, err := j.client.StreamResults(ctx, job.sid, j.results, func(row map[string]interface{}) error {
+		job.returned++
+		return writer.Write(row)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to get search results: %w", err)
+	}
+	if err := writer.Close(); err != nil {
+		return err
+	}
+	job.results = &splunk.SearchResult{Messages: messages}
+	return hook.afterRecorded(ctx, job.sid, recorded)
+}
+
+// previewResults gets the status and preview results of an unfinished job
+func previewResults(ctx context.Context, client splunk.API, sid string, opts splunk.ResultsOptions) (*splunk.Search, *splunk.SearchResult, error) {
+	status, err := client.GetSearchStatus(ctx, sid)
+	if err != nil {
+		return nil, nil, err
+	}
+	opts.Preview = true
+	results, err := client.GetResults(ctx, sid, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+	return status, results, nil
+}
+
+// cancelSearch cancels a job that won't be waited for, with a fresh deadline as ctx may be done
+func cancelSearch(ctx context.Context, client splunk.API, sid string) {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 10*time.Second)
+	defer cancel()
+	_ = client.CancelSearch(ctx, sid)
+}
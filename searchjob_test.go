@@ -0,0 +1,78 @@
+package main
+
+import (
+	"context"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/kitproj/splunk-cli/internal/config"
+	"github.com/kitproj/splunk-cli/pkg/splunk"
+	"github.com/kitproj/splunk-cli/pkg/splunk/splunktest"
+)
+
+func TestSearchJob(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the hooks use sh")
+	}
+	saved := settings
+	defer func() { settings = saved }()
+	settings = &config.Profile{
+		PreSearch:  `test "$SPLUNK_QUERY" = "| savedsearch \"errors\"" && test "$SPLUNK_EARLIEST" = -1h`,
+		PostSearch: `test "$SPLUNK_SID" = 1 && test "$SPLUNK_RESULT_COUNT" = 2`,
+	}
+
+	var calls []string
+	cancelled := ""
+	fake := &splunktest.Fake{
+		DispatchSavedSearchFunc: func(ctx context.Context, name string, opts splunk.SearchOptions) (string, error) {
+			calls = append(calls, "dispatch "+name)
+			return "1", nil
+		},
+		GetSearchStatusFunc: func(ctx context.Context, sid string) (*splunk.Search, error) {
+			calls = append(calls, "status")
+			status := &splunk.Search{}
+			status.Content.IsDone = true
+			if sid == "2" {
+				status.Content.DispatchState = "FAILED"
+			}
+			return status, nil
+		},
+		GetResultsFunc: func(ctx context.Context, sid string, opts splunk.ResultsOptions) (*splunk.SearchResult, error) {
+			calls = append(calls, "results")
+			return &splunk.SearchResult{Results: []map[string]interface{}{{"n": "1"}, {"n": "2"}}}, nil
+		},
+		CancelSearchFunc: func(ctx context.Context, sid string) error {
+			cancelled = sid
+			return nil
+		},
+	}
+
+	job := searchJob{
+		client:      fake,
+		query:       `| savedsearch "errors"`,
+		opts:        splunk.SearchOptions{EarliestTime: "-1h"},
+		savedSearch: "errors",
+	}
+	finished, err := job.run(context.Background())
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if finished.sid != "1" || finished.returned != 2 {
+		t.Errorf("Expected the 2 results of job 1, got: %+v", finished)
+	}
+	if got := strings.Join(calls, ","); got != "dispatch errors,status,results" {
+		t.Errorf("Expected the job to be dispatched, waited for, then its results got, got: %s", got)
+	}
+
+	// A failed job is cancelled, and its results aren't got
+	fake.DispatchSavedSearchFunc = func(ctx context.Context, name string, opts splunk.SearchOptions) (string, error) {
+		return "2", nil
+	}
+	if _, err := job.run(context.Background()); err == nil {
+		t.Error("Expected the failed job to fail")
+	}
+	if cancelled != "2" {
+		t.Errorf("Expected the failed job to be cancelled, got: %q", cancelled)
+	}
+}
@@ -0,0 +1,194 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/kitproj/splunk-cli/pkg/splunk"
+)
+
+// sendArgs are the options of the send command
+type sendArgs struct {
+	index, sourcetype, source, host string
+	// format is how lines are read: json, raw or auto, which sends JSON objects as objects and other lines as text
+	format        string
+	batchSize     int
+	flushInterval time.Duration
+	ack           bool
+}
+
+func sendCommand() *command {
+	var args sendArgs
+	return &command{
+		name:  "send",
+		short: "Send events from stdin to the HTTP Event Collector",
+		long: "Send each line of stdin to the HTTP Event Collector as an event, in batches.\n" +
+			"Lines that are JSON objects are sent as structured events, and other lines as text.\n" +
+			"The HEC token is read from SPLUNK_HEC_TOKEN, or the keyring ('splunk configure --hec <host>').",
+		flags: func(flags *flag.FlagSet) {
+			flags.StringVar(&args.index, "index", "", "index to send events to (default: the token's default index)")
+			flags.StringVar(&args.sourcetype, "sourcetype", "", "sourcetype of the events (default: the token's default sourcetype)")
+			flags.StringVar(&args.source, "source", "", "source of the events (default: the token's default source)")
+			flags.StringVar(&args.host, "event-host", "", "host field of the events (default: the collector's host)")
+			flags.StringVar(&args.format, "format", "auto", "how to read lines: auto, json (every line must be a JSON object) or raw (every line is text)")
+			flags.IntVar(&args.batchSize, "batch-size", 100, "maximum number of events to send in each request")
+			flags.DurationVar(&args.flushInterval, "flush-interval", time.Second, "send a partial batch after this long, e.g. when following a log")
+			flags.BoolVar(&args.ack, "ack", false, "wait until Splunk has indexed the events (the HEC token must have indexer acknowledgement enabled)")
+		},
+		flagValues: map[string]func() []string{"format": staticValues("auto", "json", "raw")},
+		run: func(ctx context.Context, _ []string) error {
+			switch args.format {
+			case "auto", "json", "raw":
+			default:
+				return fmt.Errorf("unknown format %q (must be auto, json or raw)", args.format)
+			}
+			c, err := newHECClient()
+			if err != nil {
+				return err
+			}
+			return withTimeout(ctx, func(ctx context.Context) error {
+				count, err := sendEvents(ctx, c, os.Stdin, args)
+				slog.Info("Sent events", "count", count)
+				return err
+			})
+		},
+	}
+}
+
+// sendEvents sends each line of in as an event, returning how many were sent
+func sendEvents(ctx context.Context, c splunk.EventCollector, in io.Reader, args sendArgs) (int, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// Lines are read in the background, so a partial batch can be sent while waiting for more
+	lines := make(chan string)
+	readErr := make(chan error, 1)
+	go func() {
+		defer recoverCrash()
+		defer close(lines)
+		r := bufio.NewReader(in)
+		for {
+			line, err := r.ReadString('\n')
+			if line != "" {
+				select {
+				case lines <- line:
+				case <-ctx.Done():
+					return
+				}
+			}
+			if err != nil {
+				if !errors.Is(err, io.EOF) {
+					readErr <- fmt.Errorf("failed to read events: %w", err)
+				}
+				return
+			}
+		}
+	}()
+
+	var batch []splunk.HECEvent
+	var ackIDs []int64
+	sent, lineNo := 0, 0
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		resp, err := c.Send(ctx, batch...)
+		if err != nil {
+			return fmt.Errorf("failed to send events: %w", err)
+		}
+		if args.ack {
+			if resp.AckID == nil {
+				return fmt.Errorf("no acknowledgement ID was returned (enable indexer acknowledgement on the HEC token, or don't use --ack)")
+			}
+			ackIDs = append(ackIDs, *resp.AckID)
+		}
+		sent += len(batch)
+		batch = batch[:0]
+		return nil
+	}
+
+	ticker := time.NewTicker(max(args.flushInterval, time.Millisecond))
+	defer ticker.Stop()
+	for done := false; !done; {
+		select {
+		case line, ok := <-lines:
+			if !ok {
+				done = true
+				break
+			}
+			lineNo++
+			event, err := parseEventLine(line, args.format)
+			if err != nil {
+				return sent, fmt.Errorf("line %d: %w", lineNo, err)
+			}
+			if event == nil {
+				continue
+			}
+			batch = append(batch, splunk.HECEvent{
+				Index:      args.index,
+				Sourcetype: args.sourcetype,
+				Source:     args.source,
+				Host:       args.host,
+				Event:      event,
+			})
+			if len(batch) >= max(args.batchSize, 1) {
+				if err := flush(); err != nil {
+					return sent, err
+				}
+			}
+		case <-ticker.C:
+			if err := flush(); err != nil {
+				return sent, err
+			}
+		case <-ctx.Done():
+			return sent, ctx.Err()
+		}
+	}
+	if err := flush(); err != nil {
+		return sent, err
+	}
+	select {
+	case err := <-readErr:
+		return sent, err
+	default:
+	}
+
+	if len(ackIDs) > 0 {
+		slog.Info("Waiting for Splunk to index batches", "batches", len(ackIDs))
+		if err := c.WaitForAcks(ctx, time.Second, ackIDs...); err != nil {
+			return sent, fmt.Errorf("failed to confirm the events were indexed: %w", err)
+		}
+	}
+	return sent, nil
+}
+
+// parseEventLine returns the event of a line, or nil for a blank line
+func parseEventLine(line, format string) (interface{}, error) {
+	line = strings.TrimRight(line, "\r\n")
+	if strings.TrimSpace(line) == "" {
+		return nil, nil
+	}
+	if format == "raw" {
+		return line, nil
+	}
+
+	var event map[string]interface{}
+	err := json.Unmarshal([]byte(line), &event)
+	switch {
+	case err == nil:
+		return event, nil
+	case format == "json":
+		return nil, fmt.Errorf("not a JSON object: %w", err)
+	default:
+		return line, nil
+	}
+}
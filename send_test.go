@@ -0,0 +1,56 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kitproj/splunk-cli/pkg/splunk"
+)
+
+func TestSendEvents(t *testing.T) {
+	var batches [][]map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var batch []map[string]interface{}
+		dec := json.NewDecoder(r.Body)
+		for dec.More() {
+			var event map[string]interface{}
+			if err := dec.Decode(&event); err != nil {
+				t.Error(err)
+				return
+			}
+			batch = append(batch, event)
+		}
+		batches = append(batches, batch)
+		fmt.Fprint(w, `{"text":"Success","code":0}`)
+	}))
+	defer server.Close()
+
+	c := splunk.NewHECClient("localhost", "hec-token")
+	c.BaseURL = server.URL
+
+	in := strings.NewReader("{\"status\":500}\nplain text\r\n\n{\"status\":200}")
+	count, err := sendEvents(context.Background(), c, in, sendArgs{index: "main", format: "auto", batchSize: 2, flushInterval: time.Hour})
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if count != 3 || len(batches) != 2 || len(batches[0]) != 2 || len(batches[1]) != 1 {
+		t.Fatalf("Expected 3 events in batches of 2, got: %v", batches)
+	}
+	if batches[0][0]["index"] != "main" || batches[0][0]["event"].(map[string]interface{})["status"] != float64(500) {
+		t.Errorf("Expected a JSON event for the main index, got: %v", batches[0][0])
+	}
+	if batches[0][1]["event"] != "plain text" {
+		t.Errorf("Expected a text event, got: %v", batches[0][1])
+	}
+
+	_, err = sendEvents(context.Background(), c, strings.NewReader("not json\n"), sendArgs{format: "json", batchSize: 1, flushInterval: time.Hour})
+	if err == nil || !strings.Contains(err.Error(), "line 1") {
+		t.Errorf("Expected an error for the line that isn't JSON, got: %v", err)
+	}
+}
